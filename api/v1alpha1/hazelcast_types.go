@@ -119,14 +119,132 @@ type AgentConfiguration struct {
 	// +optional
 	Repository string `json:"repository,omitempty"`
 
-	// Version of Hazelcast Platform Operator Agent.
+	// Version of Hazelcast Platform Operator Agent. The options of the HotBackups and the restore added after 0.1.5,
+	// e.g. updateLatest of the HotBackups, require at least 0.2.0, the HotBackups and the restores using them
+	// are rejected with older agents.
 	// +kubebuilder:default:="0.1.5"
 	// +optional
 	Version string `json:"version,omitempty"`
 }
 
 // RestoreConfiguration contains the configuration for Restore operation
-type RestoreConfiguration BucketConfiguration
+type RestoreConfiguration struct {
+	// Name of the secret with credentials for cloud providers.
+	// +kubebuilder:validation:MinLength:=1
+	// +optional
+	Secret string `json:"secret,omitempty"`
+
+	// Full path to blob storage bucket.
+	// +kubebuilder:validation:MinLength:=6
+	// +optional
+	BucketURI string `json:"bucketURI,omitempty"`
+
+	// HotBackupResourceName is the name of the HotBackup resource to restore from.
+	// The bucket and the secret of the HotBackup are used, so bucketURI and secret must not be set.
+	// The cluster is not created until the HotBackup finishes successfully.
+	// +optional
+	HotBackupResourceName string `json:"hotBackupResourceName,omitempty"`
+
+	// Latest restores the most recent successful backup of the cluster. If bucketURI is set, the restore agent
+	// follows the latest pointer of the cluster in the bucket, see updateLatest of the HotBackup.
+	// Otherwise the HotBackup of this Hazelcast resource which succeeded last is restored.
+	// +optional
+	Latest bool `json:"latest,omitempty"`
+
+	// AllowVersionMismatch allows restoring a backup taken from a Hazelcast cluster whose major or minor
+	// version differs from the version of this cluster. Such restores are blocked by default. The version is only
+	// checked by the agents 0.2.0 or later, the older agents restore the backups of any version.
+	// +kubebuilder:default:=false
+	// +optional
+	AllowVersionMismatch bool `json:"allowVersionMismatch,omitempty"`
+
+	// VerifyDigest makes the restore agent recompute the digest of the backup set and compare it
+	// with the one stored in the manifest before restoring. The restore fails if they differ.
+	// +optional
+	VerifyDigest bool `json:"verifyDigest,omitempty"`
+
+	// Compression of the backup archives. If it is not set, the restore agent detects it from the manifest
+	// of the backup or, for backups without a manifest, from the leading bytes of the archives.
+	// The restore fails if the archives are compressed with an algorithm the agent does not support.
+	// +optional
+	Compression CompressionAlgorithm `json:"compression,omitempty"`
+
+	// StartupTimeout is the time the members wait for the restored data to load during the hot restart
+	// before the restore fails. It overrides the dataRecoveryTimeout of the data load step, e.g. for large datasets.
+	// +optional
+	StartupTimeout *metav1.Duration `json:"startupTimeout,omitempty"`
+
+	// MaxConcurrentDownloads is the maximum number of members downloading the backup at the same time.
+	// The members of a new cluster start at once and all of them download in parallel if it is 0.
+	// The downloads of the other members are canceled once the download of a member fails.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxConcurrentDownloads int32 `json:"maxConcurrentDownloads,omitempty"`
+
+	// IncludeConfig reapplies the dynamic configuration stored in the manifest of the backup, see includeConfig
+	// of the HotBackup. The restore agent writes it next to the restored data and the members import it at startup,
+	// an empty configuration is written if the backup has none. It is ignored by the agents older than 0.2.0.
+	// +optional
+	IncludeConfig bool `json:"includeConfig,omitempty"`
+
+	// ZoneAffinity restores the backup of each member on a member in the same zone, using the zones of the members
+	// stored in the manifest of the backup, so that the partition ownership of the zones is reconstructed.
+	// The members are spread evenly over the zones of the cluster unless the scheduling already spreads them over the zones.
+	// If the backup is restored from a HotBackup, zoneAffinityWarning of the restore status reports the zones
+	// of the backup the nodes of the cluster cannot satisfy. The restore agents wait until the operator sets the zones
+	// of their nodes on the member pods.
+	// +optional
+	ZoneAffinity bool `json:"zoneAffinity,omitempty"`
+
+	// Hooks run in the given order after the backup is restored and before the Hazelcast member starts.
+	// A failing hook prevents the member from starting.
+	// +optional
+	Hooks []RestoreHook `json:"hooks,omitempty"`
+
+	// KeyEncoding is the keyEncoding of the HotBackup which uploaded the backups in bucketURI. The restore agent uses it
+	// to find the backups of the source cluster, the other keys are read from the manifest of the backup.
+	// It is not needed to restore the backup of a HotBackup, its backup folder is known.
+	// +optional
+	KeyEncoding ObjectKeyEncoding `json:"keyEncoding,omitempty"`
+
+	// SourceClusterName is the name of the Hazelcast resource the backup was taken from if it differs from this one,
+	// e.g. to restore a backup of prod into prod-dr. latest looks up the backups of the source cluster. The restore agent
+	// rewrites the cluster identity stored in the restored persistence data to the names of this cluster,
+	// so that the members accept the data during the hot restart.
+	// +optional
+	SourceClusterName string `json:"sourceClusterName,omitempty"`
+
+	// ExcludeStructures are the names of the maps which are not loaded from the backup during the hot restart,
+	// e.g. large maps which can be reconstructed, to shorten the recovery. The restore agent removes the data of
+	// these maps from the restored backup, so they start empty. Their persistence stays enabled, the data written
+	// after the restore is persisted and backed up as usual.
+	// Names which are not maps with persistence enabled in the cluster are reported in excludeStructuresWarning
+	// of the restore status.
+	// +optional
+	ExcludeStructures []string `json:"excludeStructures,omitempty"`
+}
+
+// RestoreHook is a container run against the restored persistence data.
+// It can be used to migrate the data or to check whether it is compatible with the Hazelcast version.
+// The persistence directory and the Hazelcast version are passed to the hook in the
+// PERSISTENCE_DIR and HAZELCAST_VERSION environment variables.
+type RestoreHook struct {
+	// Name of the hook.
+	// +kubebuilder:validation:MinLength:=1
+	Name string `json:"name"`
+
+	// Container image of the hook.
+	// +kubebuilder:validation:MinLength:=1
+	Image string `json:"image"`
+
+	// Entrypoint of the hook container. The image's ENTRYPOINT is used if not provided.
+	// +optional
+	Command []string `json:"command,omitempty"`
+
+	// Arguments to the entrypoint.
+	// +optional
+	Args []string `json:"args,omitempty"`
+}
 
 // BackupType represents the storage options for the HotBackup
 // +kubebuilder:validation:Enum=External;Local
@@ -176,6 +294,18 @@ type HazelcastPersistenceConfiguration struct {
 
 	// +kubebuilder:default:="Local"
 	BackupType BackupType `json:"backupType,omitempty"`
+
+	// JetLosslessRestart persists the Jet job metadata and snapshots together with the data,
+	// so they are included in the hot backups and the streaming jobs can be resumed after a restore.
+	// +kubebuilder:default:=false
+	// +optional
+	JetLosslessRestart bool `json:"jetLosslessRestart,omitempty"`
+
+	// MaxConcurrentUploads is the maximum number of members uploading their backups at the same time.
+	// It protects small clusters from the load of the uploads. All the members upload at once if it is 0.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxConcurrentUploads int32 `json:"maxConcurrentUploads,omitempty"`
 }
 
 type PersistencePvcConfiguration struct {
@@ -366,9 +496,14 @@ func (p *HazelcastPersistenceConfiguration) IsExternal() bool {
 	return p != nil && (p.BackupType == External)
 }
 
+// IsJetLosslessRestartEnabled returns true if the Jet job snapshots are persisted
+func (p *HazelcastPersistenceConfiguration) IsJetLosslessRestartEnabled() bool {
+	return p != nil && p.JetLosslessRestart
+}
+
 // IsRestoreEnabled returns true if Restore Agent configuration is specified
 func (p *HazelcastPersistenceConfiguration) IsRestoreEnabled() bool {
-	return p != nil && p.Restore != nil && !(*p.Restore == (RestoreConfiguration{}))
+	return p != nil && p.Restore != nil && !(p.Restore.Secret == "" && p.Restore.BucketURI == "" && p.Restore.HotBackupResourceName == "" && !p.Restore.Latest)
 }
 
 // HazelcastStatus defines the observed state of Hazelcast
@@ -397,6 +532,27 @@ type HazelcastStatus struct {
 	// +optional
 	// +kubebuilder:default:={}
 	Restore *RestoreStatus `json:"restore,omitempty"`
+
+	// Backup is the summary of the last HotBackup of the cluster. It is written by the HotBackup controller.
+	// +optional
+	Backup *HazelcastBackupStatus `json:"backup,omitempty"`
+}
+
+// HazelcastBackupStatus is the summary of the last HotBackup of the Hazelcast cluster
+type HazelcastBackupStatus struct {
+	// HotBackup is the name of the HotBackup which ran last.
+	HotBackup string `json:"hotBackup"`
+
+	// State of the last HotBackup.
+	State HotBackupState `json:"state"`
+
+	// Message of the last HotBackup.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// LastSuccessTime is the time the last successful backup of the cluster finished.
+	// +optional
+	LastSuccessTime *metav1.Time `json:"lastSuccessTime,omitempty"`
 }
 
 type RestoreState string
@@ -417,6 +573,66 @@ type RestoreStatus struct {
 
 	// RemainingDataLoadTime show the time in seconds remained for the restore data load step.
 	RemainingDataLoadTime int64 `json:"remainingDataLoadTime"`
+
+	// LoadedMembers is the number of members which finished loading their data.
+	// +optional
+	LoadedMembers int32 `json:"loadedMembers,omitempty"`
+
+	// TotalMembers is the number of members taking part in the hot restart.
+	// +optional
+	TotalMembers int32 `json:"totalMembers,omitempty"`
+
+	// LoadedPartitions is the number of partitions owned by the members which finished loading their data.
+	// +optional
+	LoadedPartitions int32 `json:"loadedPartitions,omitempty"`
+
+	// TotalPartitions is the number of partitions owned by the members taking part in the hot restart.
+	// The restore is complete once all of them are loaded.
+	// +optional
+	TotalPartitions int32 `json:"totalPartitions,omitempty"`
+
+	// Members is the state of the download of the backup of each member.
+	// +optional
+	Members []RestoreMemberStatus `json:"members,omitempty"`
+
+	// ZoneAffinityWarning shows the zones of the backup which have fewer restored members than the backup had,
+	// i.e. the zone topology of the cluster cannot satisfy the zone affinity of the restore.
+	// +optional
+	ZoneAffinityWarning string `json:"zoneAffinityWarning,omitempty"`
+
+	// ExcludeStructuresWarning shows the names in excludeStructures of the restore which are not maps with persistence
+	// enabled in the cluster, so nothing is excluded for them.
+	// +optional
+	ExcludeStructuresWarning string `json:"excludeStructuresWarning,omitempty"`
+}
+
+// RestoreDownloadState is the state of the download of the backup of a member
+type RestoreDownloadState string
+
+const (
+	// RestoreDownloadWaiting means the member waits to start the download, e.g. for the concurrency limit.
+	RestoreDownloadWaiting RestoreDownloadState = "Waiting"
+	// RestoreDownloadInProgress means the restore agent of the member is downloading the backup.
+	RestoreDownloadInProgress RestoreDownloadState = "Downloading"
+	// RestoreDownloadSucceeded means the backup of the member is downloaded.
+	RestoreDownloadSucceeded RestoreDownloadState = "Downloaded"
+	// RestoreDownloadFailed means the download of the member failed after the restarts of its restore agent or it was canceled.
+	RestoreDownloadFailed RestoreDownloadState = "Failed"
+	// RestoreDownloadRetrying means the download of the member failed and its restore agent is restarted to retry it.
+	RestoreDownloadRetrying RestoreDownloadState = "Retrying"
+)
+
+// RestoreMemberStatus defines the state of the download of the backup of a member
+type RestoreMemberStatus struct {
+	// PodName is the name of the Hazelcast member pod.
+	PodName string `json:"podName"`
+
+	// State of the download.
+	State RestoreDownloadState `json:"state"`
+
+	// Message of the failed download.
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 // HazelcastMemberStatus defines the observed state of the individual Hazelcast member.
@@ -485,6 +701,8 @@ type HazelcastClusterStatus struct {
 // +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.phase",description="Current state of the Hazelcast deployment"
 // +kubebuilder:printcolumn:name="Members",type="string",JSONPath=".status.hazelcastClusterStatus.readyMembers",description="Current numbers of ready Hazelcast members"
 // +kubebuilder:printcolumn:name="External-Addresses",type="string",JSONPath=".status.externalAddresses",description="External addresses of the Hazelcast cluster"
+// +kubebuilder:printcolumn:name="Backup",type="string",JSONPath=".status.backup.state",priority=1,description="State of the last HotBackup of the cluster"
+// +kubebuilder:printcolumn:name="Last Backup",type="date",JSONPath=".status.backup.lastSuccessTime",priority=1,description="Time of the last successful backup of the cluster"
 type Hazelcast struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -1,6 +1,9 @@
 package v1alpha1
 
-import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
 
 type HotBackupState string
 
@@ -11,24 +14,351 @@ const (
 	HotBackupInProgress HotBackupState = "InProgress"
 	HotBackupFailure    HotBackupState = "Failure"
 	HotBackupSuccess    HotBackupState = "Success"
+	// HotBackupSkipped means the backups of the Hazelcast cluster are disabled by a label
+	// or the scheduled run was skipped in the maintenance mode of the operator
+	HotBackupSkipped HotBackupState = "Skipped"
+	// HotBackupUploading means the local backups of all the members finished and the members upload them to the bucket.
+	// Each member starts its upload right after its own local backup finished, so the first uploads may already be
+	// running in the InProgress state, see the phases of the members.
+	HotBackupUploading HotBackupState = "Uploading"
+	// HotBackupCanceled means the run was canceled by the cancel field or the hazelcast.com/cancel-current annotation
+	HotBackupCanceled HotBackupState = "Canceled"
+	// HotBackupPartialSuccess means the backup finished without the members whose upload was abandoned
+	// or uploaded to the failover bucket. The incomplete backup is not restored and is not a successful backup
+	// of the freshness SLA and the dependencies of the other HotBackups.
+	HotBackupPartialSuccess HotBackupState = "PartialSuccess"
 )
 
 func (s HotBackupState) IsFinished() bool {
-	return s == HotBackupFailure || s == HotBackupSuccess
+	return s == HotBackupFailure || s == HotBackupSuccess || s == HotBackupCanceled || s == HotBackupPartialSuccess
 }
 
 // IsRunning returns true if the HotBackup is scheduled to run or is running but not yet finished.
 // Returns false if the HotBackup is not yet scheduled to run or finished it execution including the failure state.
 func (s HotBackupState) IsRunning() bool {
-	return s == HotBackupInProgress || s == HotBackupPending
+	return s == HotBackupInProgress || s == HotBackupUploading || s == HotBackupPending
+}
+
+// HotBackupTriggerCause is why a run of the backup was started
+type HotBackupTriggerCause string
+
+const (
+	// HotBackupTriggerManual means the run was started by applying the HotBackup
+	HotBackupTriggerManual HotBackupTriggerCause = "Manual"
+	// HotBackupTriggerScheduled means the run was started by the schedule of the HotBackup
+	HotBackupTriggerScheduled HotBackupTriggerCause = "Scheduled"
+	// HotBackupTriggerTrigger means the run was started by a HotBackupTrigger, e.g. from an external system through the API
+	HotBackupTriggerTrigger HotBackupTriggerCause = "Trigger"
+)
+
+// BucketFailover is the replica of the backup bucket in the failover region
+type BucketFailover struct {
+	// BucketURI of the replica of the bucket.
+	// +kubebuilder:validation:MinLength:=6
+	BucketURI string `json:"bucketURI"`
+
+	// Secret with the credentials of the replica, e.g. with the region of the replica.
+	// The secret of the HotBackup is used if it is not set.
+	// +optional
+	Secret string `json:"secret,omitempty"`
+}
+
+// HotBackupNotification configures the webhooks notified about the finished backups.
+// The webhooks are called with a POST request with the JSON record of the backup after it finished,
+// a failed delivery neither blocks nor fails the backup.
+type HotBackupNotification struct {
+	// SuccessURL is notified when the backup succeeds.
+	// +optional
+	SuccessURL string `json:"successURL,omitempty"`
+
+	// FailureURL is notified when the backup fails, is canceled or is incomplete.
+	// +optional
+	FailureURL string `json:"failureURL,omitempty"`
+
+	// SigningSecret is the name of the secret whose signing-key signs the requests with HMAC-SHA256.
+	// The signature of the body is sent in the X-Hazelcast-Signature header as sha256=<hex digest>.
+	// +optional
+	SigningSecret string `json:"signingSecret,omitempty"`
+
+	// Retries is the number of times a failed delivery is retried.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Retries int32 `json:"retries,omitempty"`
+
+	// RetryInterval is the time between the delivery attempts, 10s if it is not set.
+	// +optional
+	RetryInterval *metav1.Duration `json:"retryInterval,omitempty"`
+}
+
+// UploadMode is the order the members upload their backups in
+// +kubebuilder:validation:Enum=Parallel;Sequential
+type UploadMode string
+
+const (
+	// UploadParallel uploads the backups of all members at the same time, limited by the maxConcurrentUploads of the cluster.
+	UploadParallel UploadMode = "Parallel"
+
+	// UploadSequential uploads the backups of the members one after the other and deletes the local backup of each
+	// member once it is uploaded, for the members with little free disk space. The members still take the backup
+	// at the same time as the backup of the cluster is consistent across the members.
+	UploadSequential UploadMode = "Sequential"
+)
+
+// HotBackupFailureReason classifies the bucket errors, the unsupported features and the cluster degradations failing
+// the backup, so automation can react to them, e.g. create the bucket, fix the permissions or retry later.
+type HotBackupFailureReason string
+
+const (
+	// HotBackupReasonBucketNotFound means the bucket or the container does not exist
+	HotBackupReasonBucketNotFound HotBackupFailureReason = "BucketNotFound"
+	// HotBackupReasonAccessDenied means the credentials of the bucket are invalid or lack permissions
+	HotBackupReasonAccessDenied HotBackupFailureReason = "AccessDenied"
+	// HotBackupReasonNetwork means the storage provider could not be reached
+	HotBackupReasonNetwork HotBackupFailureReason = "Network"
+	// HotBackupReasonThrottled means the storage provider rejected the requests because of their rate
+	HotBackupReasonThrottled HotBackupFailureReason = "Throttled"
+	// HotBackupReasonDeltaUnsupported means the Hazelcast members do not expose the changelog required by delta backups
+	HotBackupReasonDeltaUnsupported HotBackupFailureReason = "DeltaUnsupported"
+	// HotBackupReasonClusterDegraded means a member left the cluster while the members flushed the local backup
+	HotBackupReasonClusterDegraded HotBackupFailureReason = "ClusterDegraded"
+	// HotBackupReasonAgentUnreachable means the backup agents of the members could not be reached from the operator
+	HotBackupReasonAgentUnreachable HotBackupFailureReason = "AgentUnreachable"
+	// HotBackupReasonCanceled means the run was canceled by the cancel field or the hazelcast.com/cancel-current annotation
+	HotBackupReasonCanceled HotBackupFailureReason = "Canceled"
+)
+
+// ObjectKeyEncoding is the normalization of the segments of the object keys of the backups, e.g. the names of the cluster
+// and of the structures, for the object stores which are case-insensitive or restrict the characters of the keys.
+// Lowercase lowercases the segments, Percent percent-encodes every character except lowercase letters, digits, '-', '.'
+// and '_' with lowercase hex digits, Hash replaces the segments longer than 64 characters with their first 31 characters
+// and a hash. Percent is reversible from the keys, the original segments of the other encodings are recorded in the manifest.
+// +kubebuilder:validation:Enum=Lowercase;Percent;Hash
+type ObjectKeyEncoding string
+
+const (
+	KeyEncodingLowercase ObjectKeyEncoding = "Lowercase"
+	KeyEncodingPercent   ObjectKeyEncoding = "Percent"
+	KeyEncodingHash      ObjectKeyEncoding = "Hash"
+)
+
+// CompressionAlgorithm is the compression algorithm of the uploaded backup archives
+// +kubebuilder:validation:Enum=gzip;zstd
+type CompressionAlgorithm string
+
+const (
+	CompressionGzip CompressionAlgorithm = "gzip"
+	CompressionZstd CompressionAlgorithm = "zstd"
+)
+
+// LevelRange returns the minimum and maximum compression level accepted by the algorithm.
+func (c CompressionAlgorithm) LevelRange() (int32, int32) {
+	if c == CompressionZstd {
+		return 1, 22
+	}
+	return 1, 9
 }
 
 // HotBackupStatus defines the observed state of HotBackup
 type HotBackupStatus struct {
 	State   HotBackupState `json:"state"`
 	Message string         `json:"message,omitempty"`
+
+	// Reason classifies the bucket error, the unsupported feature or the cluster degradation of the failed backup.
+	// It is empty for the other failures and states.
+	// +optional
+	Reason HotBackupFailureReason `json:"reason,omitempty"`
+
+	// CompressionLevel is the compression level used by the agents for the last successful backup.
+	// +optional
+	CompressionLevel int32 `json:"compressionLevel,omitempty"`
+
+	// CompressionRatio is the ratio of the original to the compressed size of the last successful backup.
+	// +optional
+	CompressionRatio string `json:"compressionRatio,omitempty"`
+
+	// BackupFolder is the folder in the bucket containing the member backups of the last successful backup.
+	// +optional
+	BackupFolder string `json:"backupFolder,omitempty"`
+
+	// Digest is the Merkle root over the checksums of all member backups of the last successful backup.
+	// It is also stored in the manifest of the backup folder.
+	// +optional
+	Digest string `json:"digest,omitempty"`
+
+	// ScheduleRegistered shows whether the schedule of the HotBackup is registered in the operator.
+	// +optional
+	ScheduleRegistered bool `json:"scheduleRegistered,omitempty"`
+
+	// NextScheduledRun is the time of the next scheduled run.
+	// +optional
+	NextScheduledRun *metav1.Time `json:"nextScheduledRun,omitempty"`
+
+	// LastScheduledRun is the time the last scheduled run started.
+	// +optional
+	LastScheduledRun *metav1.Time `json:"lastScheduledRun,omitempty"`
+
+	// PendingSince is the time the HotBackup entered the Pending state.
+	// +optional
+	PendingSince *metav1.Time `json:"pendingSince,omitempty"`
+
+	// EstimatedSize is the size of the next backup before compression, the sum of the on-disk persistence data
+	// of the members without their local backups. It is refreshed periodically for the clusters with external backups.
+	// +optional
+	EstimatedSize *resource.Quantity `json:"estimatedSize,omitempty"`
+
+	// EstimatedSizeTime is the time the EstimatedSize last changed.
+	// +optional
+	EstimatedSizeTime *metav1.Time `json:"estimatedSizeTime,omitempty"`
+
+	// LastSuccessTime is the time the last successful backup finished.
+	// +optional
+	LastSuccessTime *metav1.Time `json:"lastSuccessTime,omitempty"`
+
+	// RecentDurations are the durations of the recent successful backups, the most recent last.
+	// +optional
+	RecentDurations []metav1.Duration `json:"recentDurations,omitempty"`
+
+	// ScheduleWarning is set if the scheduled runs are more frequent than the recent backups take.
+	// +optional
+	ScheduleWarning string `json:"scheduleWarning,omitempty"`
+
+	// JetSnapshotsIncluded shows whether the Jet job snapshots are included in the last successful backup.
+	// +optional
+	JetSnapshotsIncluded bool `json:"jetSnapshotsIncluded,omitempty"`
+
+	// DeltaBase is the backup folder the last successful backup is a delta of, it is empty for full backups.
+	// +optional
+	DeltaBase string `json:"deltaBase,omitempty"`
+
+	// CompactionProgress is the percentage of the backups of the chain merged by the current or last compaction.
+	// +optional
+	CompactionProgress int32 `json:"compactionProgress,omitempty"`
+
+	// LocalOnly shows that the last successful backup was kept on the members only
+	// because the external backups are disabled in the operator.
+	// +optional
+	LocalOnly bool `json:"localOnly,omitempty"`
+
+	// RunID is the ID of the current or last run of the backup. It is set in the log lines, the events and the audit record
+	// of the run and in the manifest of the uploaded backup. It is generated for each run, one-off backups take it from
+	// the hazelcast.com/run-id annotation if it is set before the backup starts.
+	// +optional
+	RunID string `json:"runID,omitempty"`
+
+	// TriggerCause is why the current or last run of the backup was started.
+	// It is also stored in the manifest of the uploaded backup.
+	// +optional
+	TriggerCause HotBackupTriggerCause `json:"triggerCause,omitempty"`
+
+	// TriggerSource is the schedule of the scheduled runs or the name of the HotBackupTrigger of the triggered runs.
+	// +optional
+	TriggerSource string `json:"triggerSource,omitempty"`
+
+	// EffectiveConfig is the configuration of the current or last run after the defaults of the operator
+	// and of the Hazelcast cluster were applied.
+	// +optional
+	EffectiveConfig *HotBackupEffectiveConfig `json:"effectiveConfig,omitempty"`
+
+	// Verification is the result of the current or last verification of the backup referenced by verify.
+	// +optional
+	Verification *HotBackupVerificationStatus `json:"verification,omitempty"`
+
+	// SourceMemberCount is the number of members of the Hazelcast cluster when the last backup started.
+	// +optional
+	SourceMemberCount int32 `json:"sourceMemberCount,omitempty"`
+
+	// Members is the status of the member backups of the last run.
+	// +optional
+	Members []HotBackupMemberStatus `json:"members,omitempty"`
+
+	// SkippedMembers are the members left out of the last successful or partially successful backup,
+	// e.g. whose failed upload was abandoned within maxFailedUploads. The backup does not cover the data of these members.
+	// +optional
+	SkippedMembers []HotBackupSkippedMember `json:"skippedMembers,omitempty"`
+
+	// Conditions of the HotBackup. The BackupFresh condition is maintained if freshnessSLA is set.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// HotBackupSkippedMember defines a member left out of the backup
+type HotBackupSkippedMember struct {
+	// Address of the member.
+	Address string `json:"address"`
+
+	// Reason the member was left out.
+	Reason string `json:"reason"`
+}
+
+// HotBackupMemberStatus defines the observed state of the backup of a single member
+type HotBackupMemberStatus struct {
+	// Address of the member.
+	Address string `json:"address"`
+
+	// UUID of the member.
+	UUID string `json:"uuid,omitempty"`
+
+	// Phase of the backup of the member, it is updated while the backup runs.
+	// +optional
+	Phase HotBackupMemberPhase `json:"phase,omitempty"`
+
+	// Zone of the node the member ran on. It is stored in the manifest of the backup
+	// for the zone affinity of the restore, see zoneAffinity of the restore configuration.
+	// +optional
+	Zone string `json:"zone,omitempty"`
+
+	// RetryCount is the number of times the upload had to be retried.
+	// +optional
+	RetryCount int32 `json:"retryCount,omitempty"`
+
+	// LastRetryError is the error causing the last retry of the upload.
+	// +optional
+	LastRetryError string `json:"lastRetryError,omitempty"`
+
+	// UploadedBytes is the number of bytes sent by the upload of the member, it is updated while the upload runs.
+	// +optional
+	UploadedBytes int64 `json:"uploadedBytes,omitempty"`
+
+	// AgentVersion is the version of the backup agent of the member which uploaded the backup.
+	// +optional
+	AgentVersion string `json:"agentVersion,omitempty"`
+
+	// BucketURI is the bucket the backup of the member was uploaded to.
+	// +optional
+	BucketURI string `json:"bucketURI,omitempty"`
+
+	// FailedOver shows that the backup of the member was uploaded to the failover bucket
+	// as the upload to the primary bucket failed.
+	// +optional
+	FailedOver bool `json:"failedOver,omitempty"`
+
+	// Abandoned shows that the failed upload of the member was given up without failing the backup.
+	// +optional
+	Abandoned bool `json:"abandoned,omitempty"`
+
+	// Error is the error of the abandoned upload.
+	// +optional
+	Error string `json:"error,omitempty"`
 }
 
+// HotBackupMemberPhase is the phase of the backup of a member
+type HotBackupMemberPhase string
+
+const (
+	// HotBackupMemberInProgress means the member takes its local backup.
+	HotBackupMemberInProgress HotBackupMemberPhase = "InProgress"
+	// HotBackupMemberUploading means the member uploads its local backup to the bucket.
+	HotBackupMemberUploading HotBackupMemberPhase = "Uploading"
+	// HotBackupMemberUploaded means the member uploaded its backup to the bucket.
+	HotBackupMemberUploaded HotBackupMemberPhase = "Uploaded"
+	// HotBackupMemberBackedUp means the member finished the local backup which is not uploaded.
+	HotBackupMemberBackedUp HotBackupMemberPhase = "BackedUp"
+	// HotBackupMemberFailed means the backup or the upload of the member failed or was abandoned.
+	HotBackupMemberFailed HotBackupMemberPhase = "Failed"
+)
+
 // HotBackupSpec defines the Spec of HotBackup
 type HotBackupSpec struct {
 	// HazelcastResourceName defines the name of the Hazelcast resource
@@ -36,6 +366,8 @@ type HotBackupSpec struct {
 
 	// Schedule contains a crontab-like expression that defines the schedule in which HotBackup will be started.
 	// If the Schedule is empty the HotBackup will start only once when applied.
+	// Intervals can be given as "@every <duration>", e.g. "@every 6h".
+	// If the operator is started with --backup-schedule-seconds, the expression may begin with an optional seconds field.
 	// ---
 	// Several pre-defined schedules in place of a cron expression can be used.
 	//	Entry                  | Description                                | Equivalent To
@@ -48,13 +380,409 @@ type HotBackupSpec struct {
 	// +optional
 	Schedule string `json:"schedule"`
 
+	// Cancel cancels the run in progress, the local backups and the uploads of the members are canceled and
+	// the HotBackup ends in the Canceled state. A HotBackup not started yet is canceled before it starts.
+	// The schedule is suspended while it is set, a one-off HotBackup is not started again once it is unset.
+	// +optional
+	Cancel bool `json:"cancel,omitempty"`
+
 	// URL of the bucket to download HotBackup folders.
+	// It can also be an http:// or https:// endpoint the backup objects are uploaded to with HTTP PUT requests.
 	// +optional
 	BucketURI string `json:"bucketURI"`
 
 	// Name of the secret with credentials for cloud providers.
+	// For HTTP PUT endpoints every key of the secret is sent as a request header, e.g. Authorization.
 	// +optional
 	Secret string `json:"secret"`
+
+	// ChunkedTransfer streams the backup objects to an HTTP PUT endpoint with chunked transfer encoding,
+	// without knowing their size in advance. It can only be set for http:// and https:// bucketURIs.
+	// +optional
+	ChunkedTransfer bool `json:"chunkedTransfer,omitempty"`
+
+	// Compression algorithm of the backup archives uploaded to the bucket. gzip is used if not set.
+	// +optional
+	Compression CompressionAlgorithm `json:"compression,omitempty"`
+
+	// CompressionLevel of the selected algorithm, from 1 to 9 for gzip and from 1 to 22 for zstd.
+	// The default level of the algorithm is used if not set.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	CompressionLevel int32 `json:"compressionLevel,omitempty"`
+
+	// VerifyArchive makes the agents check that the compressed backup archive can be read back
+	// before the upload of a member is reported as successful.
+	// +optional
+	VerifyArchive bool `json:"verifyArchive,omitempty"`
+
+	// KeyEncoding normalizes the segments of the keys of the uploaded objects, see ObjectKeyEncoding.
+	// The keys are built from the names as they are if it is not set.
+	// +optional
+	KeyEncoding ObjectKeyEncoding `json:"keyEncoding,omitempty"`
+
+	// BackupPathOverride is the directory on the members the agents upload the backup from instead of the baseDir of the
+	// persistence of the Hazelcast resource, for clusters whose backups are mounted at another path.
+	// The backup fails before it starts if the directory cannot be read on a member.
+	// +optional
+	BackupPathOverride string `json:"backupPathOverride,omitempty"`
+
+	// Failover is the replica of the bucket in another region. The backup of a member is uploaded to it
+	// if the upload to the bucket fails after the retries of the agent. Only one of the buckets is written.
+	// +optional
+	Failover *BucketFailover `json:"failover,omitempty"`
+
+	// Notification configures the webhooks notified when the backup succeeds or fails.
+	// +optional
+	Notification *HotBackupNotification `json:"notification,omitempty"`
+
+	// CompletionMap is the map of the backed up cluster the record of the finished backup is put into, under the name
+	// of the HotBackup as key and the JSON of the record as value, so that the applications can react to the backups.
+	// A failure to write the record does not fail the backup.
+	// +optional
+	CompletionMap string `json:"completionMap,omitempty"`
+
+	// CompletionTopic is the topic of the backed up cluster the JSON of the record of the finished backup is published to.
+	// A failure to publish the record does not fail the backup.
+	// +optional
+	CompletionTopic string `json:"completionTopic,omitempty"`
+
+	// MaxFailedUploads is the number of members whose failed upload is abandoned without failing the backup
+	// or canceling the uploads of the other members. The backup is incomplete without the abandoned members,
+	// it ends in the PartialSuccess state. Any failed upload fails the backup if it is 0.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxFailedUploads int32 `json:"maxFailedUploads,omitempty"`
+
+	// UploadMode controls how the members upload their backups once the backup of the cluster finished.
+	// The members upload in parallel if it is not set.
+	// +optional
+	UploadMode UploadMode `json:"uploadMode,omitempty"`
+
+	// Metadata is written into the manifest of the uploaded backup to identify it later,
+	// e.g. the environment, the application version or a ticket id.
+	// +optional
+	Metadata map[string]string `json:"metadata,omitempty"`
+
+	// Delta uploads only the changelog segments the members wrote since the previous successful backup of this
+	// HotBackup to the same bucket, the first backup is a full one. The base of the delta is recorded in the manifest,
+	// the restore agent replays the chain of the deltas up to the restored backup.
+	// It is experimental and depends on the members exposing the changelog of the persistence,
+	// the backup fails with the DeltaUnsupported reason otherwise.
+	// +optional
+	Delta bool `json:"delta,omitempty"`
+
+	// IncludeConfig writes the dynamic configuration of the cluster, e.g. the configs of the maps created with
+	// Map resources, into the manifest of the uploaded backup. It can be reapplied by the restore of the backup.
+	// +optional
+	IncludeConfig bool `json:"includeConfig,omitempty"`
+
+	// FlushHealthCheckInterval is the interval the members of the cluster are checked at while they flush the local backup.
+	// The backup is interrupted and fails with the ClusterDegraded reason once a member leaves the cluster,
+	// instead of noticing it only after the uploads. It is 5s if not set, 0 disables the checks.
+	// +optional
+	FlushHealthCheckInterval *metav1.Duration `json:"flushHealthCheckInterval,omitempty"`
+
+	// CollectDiagnosticsOnFailure uploads a diagnostic bundle to the bucket of the backup once it fails.
+	// The bundle holds the recent operator logs of the HotBackup, its events, its status and the status of
+	// the Hazelcast cluster. The backup agent of each member adds its own recent logs.
+	// The bundle is uploaded under the diagnostics/<HotBackup name>/<time> prefix of the bucket.
+	// +optional
+	CollectDiagnosticsOnFailure bool `json:"collectDiagnosticsOnFailure,omitempty"`
+
+	// RequestHeaders are added to the requests uploading the backup objects, e.g. for a proxy in front of the bucket.
+	// Headers set by the storage client itself, like Authorization or Content-Length, cannot be overridden.
+	// +optional
+	RequestHeaders map[string]string `json:"requestHeaders,omitempty"`
+
+	// PartSize is the size of the parts of the multipart upload of the backup archives.
+	// Defaults to 16Mi for S3 and GCP and to 8Mi for Azure buckets.
+	// +optional
+	PartSize *resource.Quantity `json:"partSize,omitempty"`
+
+	// MaxObjectSize splits the backup archives larger than it into multiple objects.
+	// The archives are uploaded as single objects up to the limit of the bucket if it is not set.
+	// +optional
+	MaxObjectSize *resource.Quantity `json:"maxObjectSize,omitempty"`
+
+	// MaxBackupSize is the size budget of the backup. The backup fails and its partial uploads are deleted
+	// if the backup of a member or of the whole cluster uploads more than this. Unlimited if it is not set.
+	// +optional
+	MaxBackupSize *resource.Quantity `json:"maxBackupSize,omitempty"`
+
+	// DependsOn are the names of the HotBackups in the same namespace which must have succeeded before this
+	// HotBackup starts, e.g. the backup of a source cluster before the backup of its derived cluster.
+	// +optional
+	DependsOn []string `json:"dependsOn,omitempty"`
+
+	// DependencyFreshness is the maximum age of the last successful backup of the dependencies.
+	// Any successful backup of the dependencies is accepted if it is not set.
+	// +optional
+	DependencyFreshness *metav1.Duration `json:"dependencyFreshness,omitempty"`
+
+	// CredentialsRefreshInterval makes the backup agent read the Secret again, or assume the role of the
+	// role-arn key of the Secret again for S3, at the given interval during the upload. It is meant for
+	// short-lived credentials expiring before long uploads finish. The credentials are read once if it is not set.
+	// +optional
+	CredentialsRefreshInterval *metav1.Duration `json:"credentialsRefreshInterval,omitempty"`
+
+	// AgentConnectTimeout is the timeout of a single attempt to start the upload on the backup agent of a member.
+	// It is 10s if it is not set.
+	// +optional
+	AgentConnectTimeout *metav1.Duration `json:"agentConnectTimeout,omitempty"`
+
+	// AgentConnectRetries is the number of times starting the upload is retried while the backup agent of a member
+	// cannot be connected to, e.g. during a rolling restart of the member. Only the attempts failing before the request
+	// is sent are retried, so an upload is never started twice. It is retried 3 times if it is not set.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	AgentConnectRetries *int32 `json:"agentConnectRetries,omitempty"`
+
+	// PendingTimeout is the maximum time the HotBackup can stay in the Pending state, e.g. while waiting for
+	// the Hazelcast cluster to become ready or for the running backup of another HotBackup of the cluster to finish.
+	// The HotBackup fails once it is exceeded.
+	// The HotBackup fails right away if the cluster is not ready and it is not set, a run waits for the backup
+	// of another HotBackup for an hour.
+	// +optional
+	PendingTimeout *metav1.Duration `json:"pendingTimeout,omitempty"`
+
+	// StartingDeadlineSeconds is the deadline for starting a scheduled run missed while the operator was down,
+	// like the one of a CronJob. The last missed run is started when the schedule is registered again within
+	// the deadline, otherwise it is skipped with a missed deadline status. Missed runs are not started if it is not set.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	StartingDeadlineSeconds *int64 `json:"startingDeadlineSeconds,omitempty"`
+
+	// FreshnessSLA is the maximum age of the last successful backup. The BackupFresh condition of the HotBackup
+	// turns False once the last successful backup is older, e.g. to wait for it or to report the health of the resource.
+	// +optional
+	FreshnessSLA *metav1.Duration `json:"freshnessSLA,omitempty"`
+
+	// IncludeJetSnapshots requires the Jet job snapshots to be part of the backup, so a restore can resume
+	// the streaming jobs from a consistent point. The Hazelcast cluster must have persistence.jetLosslessRestart enabled.
+	// +optional
+	IncludeJetSnapshots bool `json:"includeJetSnapshots,omitempty"`
+
+	// UpdateLatest makes the agent write a "latest" pointer object under the prefix of the cluster
+	// after each successful backup, pointing to the folder of the new backup.
+	// +optional
+	UpdateLatest bool `json:"updateLatest,omitempty"`
+
+	// ObjectLock configures the retention of the uploaded objects for buckets with object lock (WORM) enabled.
+	// +optional
+	ObjectLock *ObjectLockConfiguration `json:"objectLock,omitempty"`
+
+	// ObjectACL is applied to the uploaded objects, e.g. to make them readable by another account for a
+	// cross-account restore. It is supported by S3 and GCP buckets. The default ACL of the bucket is used if it is not set.
+	// +optional
+	ObjectACL *ObjectACL `json:"objectACL,omitempty"`
+
+	// Retention prunes the older backups taken by this HotBackup from the bucket and the older local backups it took
+	// from the members after each successful backup, the backups of other HotBackups of the cluster are never pruned.
+	// Without bucketURI only the local backups are pruned. It requires the backup agent, i.e. the External backupType
+	// of the persistence. All backups are kept if it is not set.
+	// +optional
+	Retention *BackupRetention `json:"retention,omitempty"`
+
+	// KeepFailedUploads keeps the objects uploaded by a failed backup in the bucket, e.g. to investigate the failure.
+	// The partial uploads and the objects of the failed run are deleted by default.
+	// +kubebuilder:default:=false
+	// +optional
+	KeepFailedUploads bool `json:"keepFailedUploads,omitempty"`
+
+	// Verify makes the HotBackup verify an already uploaded backup in the bucket instead of taking a new backup.
+	// Combined with a schedule the backup is verified periodically.
+	// +optional
+	Verify *HotBackupVerifyConfiguration `json:"verify,omitempty"`
+
+	// VerifySampleRate is the percentage of the objects of the backup whose checksums are verified by verify,
+	// trading coverage for speed on large backups. The manifest and the digest of the backup set are always verified.
+	// The sample is chosen deterministically from the backup folder, so verifying the same backup again checks the same objects.
+	// All the objects are verified if it is not set.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	VerifySampleRate *int32 `json:"verifySampleRate,omitempty"`
+
+	// Compact makes the HotBackup merge a delta backup in the bucket with the chain of backups it is applied on
+	// into a new full backup instead of taking a new backup, to keep the restore chains short.
+	// It merges a single chain, so it cannot be used with schedule.
+	// +optional
+	Compact *HotBackupCompactConfiguration `json:"compact,omitempty"`
+}
+
+// HotBackupVerificationResult is the result of a verification
+type HotBackupVerificationResult string
+
+const (
+	HotBackupVerificationPassed HotBackupVerificationResult = "Passed"
+	HotBackupVerificationFailed HotBackupVerificationResult = "Failed"
+)
+
+// HotBackupVerificationStatus defines the sample and the result of a verification
+type HotBackupVerificationStatus struct {
+	// SampleRate is the percentage of the objects of the backup which were sampled.
+	SampleRate int32 `json:"sampleRate"`
+
+	// SampledObjects is the number of objects whose checksums were verified.
+	// +optional
+	SampledObjects int32 `json:"sampledObjects,omitempty"`
+
+	// TotalObjects is the number of objects of the backup.
+	// +optional
+	TotalObjects int32 `json:"totalObjects,omitempty"`
+
+	// Result of the verification.
+	Result HotBackupVerificationResult `json:"result"`
+}
+
+// HotBackupEffectiveConfig is the configuration a backup run used
+type HotBackupEffectiveConfig struct {
+	// BucketURI the backup is uploaded to, empty if the backup is kept on the members only.
+	// +optional
+	BucketURI string `json:"bucketURI,omitempty"`
+
+	// Compression algorithm of the uploaded backup archives.
+	// +optional
+	Compression CompressionAlgorithm `json:"compression,omitempty"`
+
+	// CompressionLevel requested from the agents, the default level of the algorithm is used if it is 0.
+	// +optional
+	CompressionLevel int32 `json:"compressionLevel,omitempty"`
+
+	// BackupPath is the directory on the members the backup was uploaded from.
+	// +optional
+	BackupPath string `json:"backupPath,omitempty"`
+
+	// UploadMode is the order the members uploaded their backups in.
+	// +optional
+	UploadMode UploadMode `json:"uploadMode,omitempty"`
+
+	// MaxConcurrentUploads is the maximum number of members uploading at the same time, all the members upload at once if it is 0.
+	// +optional
+	MaxConcurrentUploads int32 `json:"maxConcurrentUploads,omitempty"`
+
+	// MaxFailedUploads is the number of members whose failed upload is abandoned without failing the backup.
+	// +optional
+	MaxFailedUploads int32 `json:"maxFailedUploads,omitempty"`
+
+	// FlushHealthCheckInterval is the interval the cluster is checked at while the members flush the local backup.
+	// +optional
+	FlushHealthCheckInterval *metav1.Duration `json:"flushHealthCheckInterval,omitempty"`
+
+	// HazelcastFetchTimeout is the time the transient API server errors were retried within when the run fetched
+	// the Hazelcast resource.
+	// +optional
+	HazelcastFetchTimeout *metav1.Duration `json:"hazelcastFetchTimeout,omitempty"`
+}
+
+// HotBackupVerifyConfiguration defines the backup to verify
+type HotBackupVerifyConfiguration struct {
+	// BackupFolder is the folder of the backup in the bucket, e.g. the backupFolder in the status of the HotBackup which uploaded it.
+	// +kubebuilder:validation:MinLength:=1
+	BackupFolder string `json:"backupFolder"`
+
+	// RestoreTest makes the backup agent also extract the member backups to a temporary directory
+	// to check they can be restored.
+	// +optional
+	RestoreTest bool `json:"restoreTest,omitempty"`
+}
+
+// HotBackupCompactConfiguration defines the chain of backups to compact
+type HotBackupCompactConfiguration struct {
+	// BackupFolder is the folder of the last delta backup of the chain in the bucket.
+	// +kubebuilder:validation:MinLength:=1
+	BackupFolder string `json:"backupFolder"`
+
+	// PruneChain deletes the merged backups from the bucket once the full backup is written.
+	// The backups other delta backups are still applied on are kept.
+	// +kubebuilder:default:=false
+	// +optional
+	PruneChain bool `json:"pruneChain,omitempty"`
+}
+
+// ObjectACL is the access control list of the uploaded objects
+type ObjectACL struct {
+	// Canned is a predefined ACL, e.g. bucket-owner-full-control. The GCP predefined ACLs are selected
+	// by the same names, e.g. bucket-owner-full-control for bucketOwnerFullControl.
+	// +optional
+	Canned string `json:"canned,omitempty"`
+
+	// Grants give permissions on the uploaded objects to other accounts.
+	// +optional
+	Grants []ObjectACLGrant `json:"grants,omitempty"`
+}
+
+type ObjectACLGrant struct {
+	// Grantee in the format of the bucket, e.g. id=<canonical user id> for S3 or user-<email> for GCP.
+	// +kubebuilder:validation:MinLength:=1
+	Grantee string `json:"grantee"`
+
+	// Permission given to the grantee. GCP buckets support READ and FULL_CONTROL only.
+	Permission ObjectACLPermission `json:"permission"`
+}
+
+// ObjectACLPermission is the permission given to a grantee on the uploaded objects
+// +kubebuilder:validation:Enum=READ;READ_ACP;WRITE_ACP;FULL_CONTROL
+type ObjectACLPermission string
+
+// BackupRetention keeps the backups in grandfather-father-son tiers. The newest backup of each of the most recent
+// hourly, daily, weekly and monthly periods is kept, the backups not kept by any tier are deleted.
+// If only maxCount or maxAge is set, the backups within both limits are kept, otherwise the limits prune
+// the backups kept by the tiers further.
+// The newest backup is always kept. A delta backup keeps the backups it is applied on, a chain of delta backups
+// is deleted only once none of its backups is kept.
+type BackupRetention struct {
+	// Hourly is the number of the most recent hours with a backup whose newest backup is kept.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Hourly int32 `json:"hourly,omitempty"`
+
+	// Daily is the number of the most recent days with a backup whose newest backup is kept.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Daily int32 `json:"daily,omitempty"`
+
+	// Weekly is the number of the most recent weeks with a backup whose newest backup is kept. Weeks start on Monday.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Weekly int32 `json:"weekly,omitempty"`
+
+	// Monthly is the number of the most recent months with a backup whose newest backup is kept.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Monthly int32 `json:"monthly,omitempty"`
+
+	// MaxCount is the number of the newest backups kept. The number is not limited if it is not set.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxCount int32 `json:"maxCount,omitempty"`
+
+	// MaxAge is the age of the oldest backup kept. The age is not limited if it is not set.
+	// +optional
+	MaxAge *metav1.Duration `json:"maxAge,omitempty"`
+}
+
+// ObjectLockMode is the retention mode of the locked objects
+// +kubebuilder:validation:Enum=Governance;Compliance
+type ObjectLockMode string
+
+const (
+	// ObjectLockGovernance objects can be deleted before the retention date by users with special permissions
+	ObjectLockGovernance ObjectLockMode = "Governance"
+	// ObjectLockCompliance objects cannot be deleted by anyone before the retention date
+	ObjectLockCompliance ObjectLockMode = "Compliance"
+)
+
+type ObjectLockConfiguration struct {
+	// Mode of the retention.
+	// +kubebuilder:default:="Governance"
+	// +optional
+	Mode ObjectLockMode `json:"mode,omitempty"`
+
+	// RetentionPeriod is the time the uploaded objects are locked for, counted from the start of the upload.
+	// It must be positive.
+	RetentionPeriod metav1.Duration `json:"retentionPeriod"`
 }
 
 //+kubebuilder:object:root=true
@@ -62,6 +790,10 @@ type HotBackupSpec struct {
 
 // HotBackup is the Schema for the hot backup API
 // +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.state",description="Current state of the HotBackup process"
+// +kubebuilder:printcolumn:name="Scheduled",type="boolean",JSONPath=".status.scheduleRegistered",description="Whether the schedule of the HotBackup is registered"
+// +kubebuilder:printcolumn:name="Next Run",type="date",JSONPath=".status.nextScheduledRun",description="Time of the next scheduled run"
+// +kubebuilder:printcolumn:name="Fresh",type="string",JSONPath=".status.conditions[?(@.type==\"BackupFresh\")].status",description="Whether the last successful backup is within the freshness SLA"
+// +kubebuilder:printcolumn:name="Estimated Size",type="string",JSONPath=".status.estimatedSize",priority=1,description="Estimated size of the next backup before compression"
 type HotBackup struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -83,3 +815,15 @@ type HotBackupList struct {
 func init() {
 	SchemeBuilder.Register(&HotBackup{}, &HotBackupList{})
 }
+
+// MultipartSizes returns the part size and the maximum object size in bytes, 0 for the unset values.
+func (s *HotBackupSpec) MultipartSizes() (int64, int64) {
+	var partSize, maxObjectSize int64
+	if s.PartSize != nil {
+		partSize = s.PartSize.Value()
+	}
+	if s.MaxObjectSize != nil {
+		maxObjectSize = s.MaxObjectSize.Value()
+	}
+	return partSize, maxObjectSize
+}
@@ -0,0 +1,118 @@
+package v1alpha1
+
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+// HotBackupPolicySpec defines the desired state of HotBackupPolicy
+type HotBackupPolicySpec struct {
+	// Selector selects the Hazelcast resources in the namespace of the policy to back up.
+	Selector metav1.LabelSelector `json:"selector"`
+
+	// MaxConcurrentBackups is the maximum number of one-off HotBackups of the selected clusters run at the same time.
+	// All backups are started at once if it is 0. It cannot be set together with a schedule.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxConcurrentBackups int32 `json:"maxConcurrentBackups,omitempty"`
+
+	// Template of the HotBackups created for the selected clusters.
+	// +optional
+	Template HotBackupPolicyTemplate `json:"template,omitempty"`
+}
+
+// HotBackupPolicyTemplate defines the spec of the HotBackups created by a HotBackupPolicy
+type HotBackupPolicyTemplate struct {
+	// Schedule of the HotBackups in the same format as the schedule of a HotBackup.
+	// The HotBackups start only once if it is empty.
+	// +optional
+	Schedule string `json:"schedule,omitempty"`
+
+	// URL of the bucket to upload the backups of the clusters configured with external backups.
+	// +optional
+	BucketURI string `json:"bucketURI,omitempty"`
+
+	// Name of the secret with credentials for cloud providers.
+	// +optional
+	Secret string `json:"secret,omitempty"`
+
+	// Compression algorithm of the backup archives uploaded to the bucket. gzip is used if not set.
+	// +optional
+	Compression CompressionAlgorithm `json:"compression,omitempty"`
+
+	// CompressionLevel of the selected algorithm.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	CompressionLevel int32 `json:"compressionLevel,omitempty"`
+
+	// Metadata is written into the manifest of the uploaded backups.
+	// +optional
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// HotBackupPolicyStatus defines the observed state of HotBackupPolicy
+type HotBackupPolicyStatus struct {
+	// State of the policy. It is InProgress while any HotBackup of the selected clusters is running or waiting to start.
+	State HotBackupState `json:"state,omitempty"`
+
+	// Message is the field to show detail information or error
+	Message string `json:"message,omitempty"`
+
+	// Clusters is the status of the HotBackups of the selected clusters.
+	// +optional
+	Clusters []HotBackupPolicyClusterStatus `json:"clusters,omitempty"`
+
+	// Succeeded is the number of clusters whose last backup finished successfully.
+	Succeeded int32 `json:"succeeded,omitempty"`
+
+	// Failed is the number of clusters whose last backup failed.
+	Failed int32 `json:"failed,omitempty"`
+}
+
+// HotBackupPolicyClusterStatus defines the observed state of the HotBackup of a selected cluster
+type HotBackupPolicyClusterStatus struct {
+	// Name of the Hazelcast resource.
+	Name string `json:"name"`
+
+	// HotBackup is the name of the HotBackup of the cluster, empty while the backup waits for the concurrency limit.
+	// +optional
+	HotBackup string `json:"hotBackup,omitempty"`
+
+	// State of the HotBackup of the cluster.
+	// +optional
+	State HotBackupState `json:"state,omitempty"`
+
+	// Message of the HotBackup of the cluster.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// LastSuccessTime is the time the last backup of the cluster succeeded.
+	// +optional
+	LastSuccessTime *metav1.Time `json:"lastSuccessTime,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.state",description="Current state of the HotBackupPolicy"
+//+kubebuilder:printcolumn:name="Succeeded",type="integer",JSONPath=".status.succeeded",description="Number of clusters backed up successfully"
+//+kubebuilder:printcolumn:name="Failed",type="integer",JSONPath=".status.failed",description="Number of clusters whose backup failed"
+
+// HotBackupPolicy backs up the Hazelcast clusters selected by labels with HotBackups created from a common template.
+// The HotBackups of the clusters are created, updated and deleted as the selected clusters change.
+type HotBackupPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   HotBackupPolicySpec   `json:"spec,omitempty"`
+	Status HotBackupPolicyStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// HotBackupPolicyList contains a list of HotBackupPolicy
+type HotBackupPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []HotBackupPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&HotBackupPolicy{}, &HotBackupPolicyList{})
+}
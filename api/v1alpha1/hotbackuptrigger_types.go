@@ -0,0 +1,74 @@
+package v1alpha1
+
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+// HotBackupTriggerSpec defines the desired state of HotBackupTrigger
+type HotBackupTriggerSpec struct {
+	// MaxConcurrentBackups is the maximum number of HotBackups run at the same time by the trigger.
+	// All backups are started at once if it is 0.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxConcurrentBackups int32 `json:"maxConcurrentBackups,omitempty"`
+
+	// Namespaces are the namespaces the clusters are backed up in.
+	// The clusters of all the namespaces watched by the operator are backed up if it is empty.
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// URL of the bucket to upload the backups of the clusters configured with external backups.
+	// The trigger fails without creating any HotBackup if such a cluster is to be backed up and bucketURI or secret is not set.
+	// +optional
+	BucketURI string `json:"bucketURI,omitempty"`
+
+	// Name of the secret with credentials for cloud providers.
+	// +optional
+	Secret string `json:"secret,omitempty"`
+}
+
+// HotBackupTriggerStatus defines the observed state of HotBackupTrigger
+type HotBackupTriggerStatus struct {
+	// State of the trigger. It is InProgress until all the started HotBackups are finished.
+	State HotBackupState `json:"state,omitempty"`
+
+	// Message is the field to show detail information or error
+	Message string `json:"message,omitempty"`
+
+	// HotBackups lists the HotBackup resources created by the trigger in the namespace/name form.
+	// +optional
+	HotBackups []string `json:"hotBackups,omitempty"`
+
+	// Succeeded is the number of HotBackups finished successfully.
+	Succeeded int32 `json:"succeeded,omitempty"`
+
+	// Failed is the number of failed HotBackups.
+	Failed int32 `json:"failed,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:scope=Cluster
+//+kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.state",description="Current state of the HotBackupTrigger"
+
+// HotBackupTrigger starts a HotBackup of every Hazelcast cluster with persistence enabled that is managed by the operator
+// in the namespaces of the trigger.
+// It is meant for cases when all the clusters need to be backed up immediately.
+type HotBackupTrigger struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   HotBackupTriggerSpec   `json:"spec,omitempty"`
+	Status HotBackupTriggerStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// HotBackupTriggerList contains a list of HotBackupTrigger
+type HotBackupTriggerList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []HotBackupTrigger `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&HotBackupTrigger{}, &HotBackupTriggerList{})
+}
@@ -0,0 +1,74 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// UserCodeSpec defines the desired state of UserCode
+type UserCodeSpec struct {
+	// HazelcastResourceName is the name of the Hazelcast resource the user code is deployed to.
+	// +kubebuilder:validation:MinLength:=1
+	HazelcastResourceName string `json:"hazelcastResourceName"`
+
+	// Jar files in the bucket will be put under CLASSPATH.
+	// +optional
+	BucketConfiguration *BucketConfiguration `json:"bucketConfig,omitempty"`
+
+	// Files in the ConfigMaps will be put under CLASSPATH.
+	// +optional
+	ConfigMaps []string `json:"configMaps,omitempty"`
+}
+
+type UserCodeState string
+
+const (
+	UserCodeFailed  UserCodeState = "Failed"
+	UserCodeSuccess UserCodeState = "Success"
+)
+
+// UserCodeStatus defines the observed state of UserCode
+type UserCodeStatus struct {
+	// State of the user code deployment.
+	State UserCodeState `json:"state,omitempty"`
+
+	// Message is the field to show detail information or error
+	Message string `json:"message,omitempty"`
+
+	// ObservedGeneration is the generation of the UserCode deployed to the Hazelcast cluster.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Digest of the contents of the ConfigMaps and the objects in the bucket deployed to the Hazelcast cluster.
+	// The members are restarted when it changes.
+	// +optional
+	Digest string `json:"digest,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.state",description="Current state of the UserCode"
+//+kubebuilder:printcolumn:name="Message",type="string",priority=1,JSONPath=".status.message",description="Message for the current UserCode"
+
+// UserCode deploys the jar files of a bucket or ConfigMaps to the CLASSPATH of the Hazelcast members.
+// The members are restarted one by one when the user code or the contents of its ConfigMaps or bucket change.
+// The bucket is checked every 5 minutes by the backup agent of a member, agent version 0.2.0 or later is needed.
+type UserCode struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   UserCodeSpec   `json:"spec"`
+	Status UserCodeStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// UserCodeList contains a list of UserCode
+type UserCodeList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []UserCode `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&UserCode{}, &UserCodeList{})
+}
@@ -6,7 +6,8 @@
 package v1alpha1
 
 import (
-	"k8s.io/api/core/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -40,6 +41,26 @@ func (in *AgentConfiguration) DeepCopy() *AgentConfiguration {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupRetention) DeepCopyInto(out *BackupRetention) {
+	*out = *in
+	if in.MaxAge != nil {
+		in, out := &in.MaxAge, &out.MaxAge
+		*out = new(v1.Duration)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupRetention.
+func (in *BackupRetention) DeepCopy() *BackupRetention {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupRetention)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BatchSetting) DeepCopyInto(out *BatchSetting) {
 	*out = *in
@@ -85,6 +106,21 @@ func (in *BucketConfiguration) DeepCopy() *BucketConfiguration {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BucketFailover) DeepCopyInto(out *BucketFailover) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BucketFailover.
+func (in *BucketFailover) DeepCopy() *BucketFailover {
+	if in == nil {
+		return nil
+	}
+	out := new(BucketFailover)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CustomClassConfiguration) DeepCopyInto(out *CustomClassConfiguration) {
 	*out = *in
@@ -187,6 +223,26 @@ func (in *Hazelcast) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HazelcastBackupStatus) DeepCopyInto(out *HazelcastBackupStatus) {
+	*out = *in
+	if in.LastSuccessTime != nil {
+		in, out := &in.LastSuccessTime, &out.LastSuccessTime
+		*out = new(v1.Time)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HazelcastBackupStatus.
+func (in *HazelcastBackupStatus) DeepCopy() *HazelcastBackupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HazelcastBackupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HazelcastClusterConfig) DeepCopyInto(out *HazelcastClusterConfig) {
 	*out = *in
@@ -271,7 +327,7 @@ func (in *HazelcastPersistenceConfiguration) DeepCopyInto(out *HazelcastPersiste
 	if in.Restore != nil {
 		in, out := &in.Restore, &out.Restore
 		*out = new(RestoreConfiguration)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 }
 
@@ -295,7 +351,7 @@ func (in *HazelcastSpec) DeepCopyInto(out *HazelcastSpec) {
 	}
 	if in.ImagePullSecrets != nil {
 		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
-		*out = make([]v1.LocalObjectReference, len(*in))
+		*out = make([]corev1.LocalObjectReference, len(*in))
 		copy(*out, *in)
 	}
 	if in.ExposeExternally != nil {
@@ -310,7 +366,7 @@ func (in *HazelcastSpec) DeepCopyInto(out *HazelcastSpec) {
 	}
 	if in.Resources != nil {
 		in, out := &in.Resources, &out.Resources
-		*out = new(v1.ResourceRequirements)
+		*out = new(corev1.ResourceRequirements)
 		(*in).DeepCopyInto(*out)
 	}
 	if in.Persistence != nil {
@@ -330,63 +386,565 @@ func (in *HazelcastSpec) DeepCopyInto(out *HazelcastSpec) {
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HazelcastSpec.
-func (in *HazelcastSpec) DeepCopy() *HazelcastSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HazelcastSpec.
+func (in *HazelcastSpec) DeepCopy() *HazelcastSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HazelcastSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HazelcastStatus) DeepCopyInto(out *HazelcastStatus) {
+	*out = *in
+	out.Cluster = in.Cluster
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make([]HazelcastMemberStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Restore != nil {
+		in, out := &in.Restore, &out.Restore
+		*out = new(RestoreStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Backup != nil {
+		in, out := &in.Backup, &out.Backup
+		*out = new(HazelcastBackupStatus)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HazelcastStatus.
+func (in *HazelcastStatus) DeepCopy() *HazelcastStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HazelcastStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HotBackup) DeepCopyInto(out *HotBackup) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Status.DeepCopyInto(&out.Status)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HotBackup.
+func (in *HotBackup) DeepCopy() *HotBackup {
+	if in == nil {
+		return nil
+	}
+	out := new(HotBackup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HotBackup) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HotBackupCompactConfiguration) DeepCopyInto(out *HotBackupCompactConfiguration) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HotBackupCompactConfiguration.
+func (in *HotBackupCompactConfiguration) DeepCopy() *HotBackupCompactConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(HotBackupCompactConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HotBackupEffectiveConfig) DeepCopyInto(out *HotBackupEffectiveConfig) {
+	*out = *in
+	if in.FlushHealthCheckInterval != nil {
+		in, out := &in.FlushHealthCheckInterval, &out.FlushHealthCheckInterval
+		*out = new(v1.Duration)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HazelcastFetchTimeout != nil {
+		in, out := &in.HazelcastFetchTimeout, &out.HazelcastFetchTimeout
+		*out = new(v1.Duration)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HotBackupEffectiveConfig.
+func (in *HotBackupEffectiveConfig) DeepCopy() *HotBackupEffectiveConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(HotBackupEffectiveConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HotBackupList) DeepCopyInto(out *HotBackupList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]HotBackup, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HotBackupList.
+func (in *HotBackupList) DeepCopy() *HotBackupList {
+	if in == nil {
+		return nil
+	}
+	out := new(HotBackupList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HotBackupList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HotBackupMemberStatus) DeepCopyInto(out *HotBackupMemberStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HotBackupMemberStatus.
+func (in *HotBackupMemberStatus) DeepCopy() *HotBackupMemberStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HotBackupMemberStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HotBackupNotification) DeepCopyInto(out *HotBackupNotification) {
+	*out = *in
+	if in.RetryInterval != nil {
+		in, out := &in.RetryInterval, &out.RetryInterval
+		*out = new(v1.Duration)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HotBackupNotification.
+func (in *HotBackupNotification) DeepCopy() *HotBackupNotification {
+	if in == nil {
+		return nil
+	}
+	out := new(HotBackupNotification)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HotBackupPolicy) DeepCopyInto(out *HotBackupPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HotBackupPolicy.
+func (in *HotBackupPolicy) DeepCopy() *HotBackupPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(HotBackupPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HotBackupPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HotBackupPolicyClusterStatus) DeepCopyInto(out *HotBackupPolicyClusterStatus) {
+	*out = *in
+	if in.LastSuccessTime != nil {
+		in, out := &in.LastSuccessTime, &out.LastSuccessTime
+		*out = new(v1.Time)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HotBackupPolicyClusterStatus.
+func (in *HotBackupPolicyClusterStatus) DeepCopy() *HotBackupPolicyClusterStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HotBackupPolicyClusterStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HotBackupPolicyList) DeepCopyInto(out *HotBackupPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]HotBackupPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HotBackupPolicyList.
+func (in *HotBackupPolicyList) DeepCopy() *HotBackupPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(HotBackupPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HotBackupPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HotBackupPolicySpec) DeepCopyInto(out *HotBackupPolicySpec) {
+	*out = *in
+	in.Selector.DeepCopyInto(&out.Selector)
+	in.Template.DeepCopyInto(&out.Template)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HotBackupPolicySpec.
+func (in *HotBackupPolicySpec) DeepCopy() *HotBackupPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HotBackupPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HotBackupPolicyStatus) DeepCopyInto(out *HotBackupPolicyStatus) {
+	*out = *in
+	if in.Clusters != nil {
+		in, out := &in.Clusters, &out.Clusters
+		*out = make([]HotBackupPolicyClusterStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HotBackupPolicyStatus.
+func (in *HotBackupPolicyStatus) DeepCopy() *HotBackupPolicyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HotBackupPolicyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HotBackupPolicyTemplate) DeepCopyInto(out *HotBackupPolicyTemplate) {
+	*out = *in
+	if in.Metadata != nil {
+		in, out := &in.Metadata, &out.Metadata
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HotBackupPolicyTemplate.
+func (in *HotBackupPolicyTemplate) DeepCopy() *HotBackupPolicyTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(HotBackupPolicyTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HotBackupSkippedMember) DeepCopyInto(out *HotBackupSkippedMember) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HotBackupSkippedMember.
+func (in *HotBackupSkippedMember) DeepCopy() *HotBackupSkippedMember {
+	if in == nil {
+		return nil
+	}
+	out := new(HotBackupSkippedMember)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HotBackupSpec) DeepCopyInto(out *HotBackupSpec) {
+	*out = *in
+	if in.Failover != nil {
+		in, out := &in.Failover, &out.Failover
+		*out = new(BucketFailover)
+		**out = **in
+	}
+	if in.Notification != nil {
+		in, out := &in.Notification, &out.Notification
+		*out = new(HotBackupNotification)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Metadata != nil {
+		in, out := &in.Metadata, &out.Metadata
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.FlushHealthCheckInterval != nil {
+		in, out := &in.FlushHealthCheckInterval, &out.FlushHealthCheckInterval
+		*out = new(v1.Duration)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RequestHeaders != nil {
+		in, out := &in.RequestHeaders, &out.RequestHeaders
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.PartSize != nil {
+		in, out := &in.PartSize, &out.PartSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.MaxObjectSize != nil {
+		in, out := &in.MaxObjectSize, &out.MaxObjectSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.MaxBackupSize != nil {
+		in, out := &in.MaxBackupSize, &out.MaxBackupSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.DependsOn != nil {
+		in, out := &in.DependsOn, &out.DependsOn
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DependencyFreshness != nil {
+		in, out := &in.DependencyFreshness, &out.DependencyFreshness
+		*out = new(v1.Duration)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CredentialsRefreshInterval != nil {
+		in, out := &in.CredentialsRefreshInterval, &out.CredentialsRefreshInterval
+		*out = new(v1.Duration)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AgentConnectTimeout != nil {
+		in, out := &in.AgentConnectTimeout, &out.AgentConnectTimeout
+		*out = new(v1.Duration)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AgentConnectRetries != nil {
+		in, out := &in.AgentConnectRetries, &out.AgentConnectRetries
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PendingTimeout != nil {
+		in, out := &in.PendingTimeout, &out.PendingTimeout
+		*out = new(v1.Duration)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StartingDeadlineSeconds != nil {
+		in, out := &in.StartingDeadlineSeconds, &out.StartingDeadlineSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.FreshnessSLA != nil {
+		in, out := &in.FreshnessSLA, &out.FreshnessSLA
+		*out = new(v1.Duration)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ObjectLock != nil {
+		in, out := &in.ObjectLock, &out.ObjectLock
+		*out = new(ObjectLockConfiguration)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ObjectACL != nil {
+		in, out := &in.ObjectACL, &out.ObjectACL
+		*out = new(ObjectACL)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Retention != nil {
+		in, out := &in.Retention, &out.Retention
+		*out = new(BackupRetention)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Verify != nil {
+		in, out := &in.Verify, &out.Verify
+		*out = new(HotBackupVerifyConfiguration)
+		**out = **in
+	}
+	if in.VerifySampleRate != nil {
+		in, out := &in.VerifySampleRate, &out.VerifySampleRate
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Compact != nil {
+		in, out := &in.Compact, &out.Compact
+		*out = new(HotBackupCompactConfiguration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HotBackupSpec.
+func (in *HotBackupSpec) DeepCopy() *HotBackupSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(HazelcastSpec)
+	out := new(HotBackupSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HazelcastStatus) DeepCopyInto(out *HazelcastStatus) {
+func (in *HotBackupStatus) DeepCopyInto(out *HotBackupStatus) {
 	*out = *in
-	out.Cluster = in.Cluster
+	if in.NextScheduledRun != nil {
+		in, out := &in.NextScheduledRun, &out.NextScheduledRun
+		*out = new(v1.Time)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastScheduledRun != nil {
+		in, out := &in.LastScheduledRun, &out.LastScheduledRun
+		*out = new(v1.Time)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PendingSince != nil {
+		in, out := &in.PendingSince, &out.PendingSince
+		*out = new(v1.Time)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.EstimatedSize != nil {
+		in, out := &in.EstimatedSize, &out.EstimatedSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.EstimatedSizeTime != nil {
+		in, out := &in.EstimatedSizeTime, &out.EstimatedSizeTime
+		*out = new(v1.Time)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastSuccessTime != nil {
+		in, out := &in.LastSuccessTime, &out.LastSuccessTime
+		*out = new(v1.Time)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RecentDurations != nil {
+		in, out := &in.RecentDurations, &out.RecentDurations
+		*out = make([]v1.Duration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.EffectiveConfig != nil {
+		in, out := &in.EffectiveConfig, &out.EffectiveConfig
+		*out = new(HotBackupEffectiveConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Verification != nil {
+		in, out := &in.Verification, &out.Verification
+		*out = new(HotBackupVerificationStatus)
+		**out = **in
+	}
 	if in.Members != nil {
 		in, out := &in.Members, &out.Members
-		*out = make([]HazelcastMemberStatus, len(*in))
+		*out = make([]HotBackupMemberStatus, len(*in))
 		copy(*out, *in)
 	}
-	if in.Restore != nil {
-		in, out := &in.Restore, &out.Restore
-		*out = new(RestoreStatus)
-		**out = **in
+	if in.SkippedMembers != nil {
+		in, out := &in.SkippedMembers, &out.SkippedMembers
+		*out = make([]HotBackupSkippedMember, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HazelcastStatus.
-func (in *HazelcastStatus) DeepCopy() *HazelcastStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HotBackupStatus.
+func (in *HotBackupStatus) DeepCopy() *HotBackupStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(HazelcastStatus)
+	out := new(HotBackupStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HotBackup) DeepCopyInto(out *HotBackup) {
+func (in *HotBackupTrigger) DeepCopyInto(out *HotBackupTrigger) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Status = in.Status
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HotBackup.
-func (in *HotBackup) DeepCopy() *HotBackup {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HotBackupTrigger.
+func (in *HotBackupTrigger) DeepCopy() *HotBackupTrigger {
 	if in == nil {
 		return nil
 	}
-	out := new(HotBackup)
+	out := new(HotBackupTrigger)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *HotBackup) DeepCopyObject() runtime.Object {
+func (in *HotBackupTrigger) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -394,31 +952,31 @@ func (in *HotBackup) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HotBackupList) DeepCopyInto(out *HotBackupList) {
+func (in *HotBackupTriggerList) DeepCopyInto(out *HotBackupTriggerList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]HotBackup, len(*in))
+		*out = make([]HotBackupTrigger, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HotBackupList.
-func (in *HotBackupList) DeepCopy() *HotBackupList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HotBackupTriggerList.
+func (in *HotBackupTriggerList) DeepCopy() *HotBackupTriggerList {
 	if in == nil {
 		return nil
 	}
-	out := new(HotBackupList)
+	out := new(HotBackupTriggerList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *HotBackupList) DeepCopyObject() runtime.Object {
+func (in *HotBackupTriggerList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -426,31 +984,71 @@ func (in *HotBackupList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HotBackupSpec) DeepCopyInto(out *HotBackupSpec) {
+func (in *HotBackupTriggerSpec) DeepCopyInto(out *HotBackupTriggerSpec) {
 	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HotBackupSpec.
-func (in *HotBackupSpec) DeepCopy() *HotBackupSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HotBackupTriggerSpec.
+func (in *HotBackupTriggerSpec) DeepCopy() *HotBackupTriggerSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(HotBackupSpec)
+	out := new(HotBackupTriggerSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HotBackupStatus) DeepCopyInto(out *HotBackupStatus) {
+func (in *HotBackupTriggerStatus) DeepCopyInto(out *HotBackupTriggerStatus) {
 	*out = *in
+	if in.HotBackups != nil {
+		in, out := &in.HotBackups, &out.HotBackups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HotBackupStatus.
-func (in *HotBackupStatus) DeepCopy() *HotBackupStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HotBackupTriggerStatus.
+func (in *HotBackupTriggerStatus) DeepCopy() *HotBackupTriggerStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(HotBackupStatus)
+	out := new(HotBackupTriggerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HotBackupVerificationStatus) DeepCopyInto(out *HotBackupVerificationStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HotBackupVerificationStatus.
+func (in *HotBackupVerificationStatus) DeepCopy() *HotBackupVerificationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HotBackupVerificationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HotBackupVerifyConfiguration) DeepCopyInto(out *HotBackupVerifyConfiguration) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HotBackupVerifyConfiguration.
+func (in *HotBackupVerifyConfiguration) DeepCopy() *HotBackupVerifyConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(HotBackupVerifyConfiguration)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -544,7 +1142,7 @@ func (in *ManagementCenterSpec) DeepCopyInto(out *ManagementCenterSpec) {
 	*out = *in
 	if in.ImagePullSecrets != nil {
 		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
-		*out = make([]v1.LocalObjectReference, len(*in))
+		*out = make([]corev1.LocalObjectReference, len(*in))
 		copy(*out, *in)
 	}
 	if in.HazelcastClusters != nil {
@@ -569,7 +1167,7 @@ func (in *ManagementCenterSpec) DeepCopyInto(out *ManagementCenterSpec) {
 	}
 	if in.Resources != nil {
 		in, out := &in.Resources, &out.Resources
-		*out = new(v1.ResourceRequirements)
+		*out = new(corev1.ResourceRequirements)
 		(*in).DeepCopyInto(*out)
 	}
 }
@@ -747,6 +1345,57 @@ func (in *MapStoreConfig) DeepCopy() *MapStoreConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectACL) DeepCopyInto(out *ObjectACL) {
+	*out = *in
+	if in.Grants != nil {
+		in, out := &in.Grants, &out.Grants
+		*out = make([]ObjectACLGrant, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectACL.
+func (in *ObjectACL) DeepCopy() *ObjectACL {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectACL)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectACLGrant) DeepCopyInto(out *ObjectACLGrant) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectACLGrant.
+func (in *ObjectACLGrant) DeepCopy() *ObjectACLGrant {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectACLGrant)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectLockConfiguration) DeepCopyInto(out *ObjectLockConfiguration) {
+	*out = *in
+	in.RetentionPeriod.DeepCopyInto(&out.RetentionPeriod)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectLockConfiguration.
+func (in *ObjectLockConfiguration) DeepCopy() *ObjectLockConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectLockConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PersistenceConfiguration) DeepCopyInto(out *PersistenceConfiguration) {
 	*out = *in
@@ -777,7 +1426,7 @@ func (in *PersistencePvcConfiguration) DeepCopyInto(out *PersistencePvcConfigura
 	*out = *in
 	if in.AccessModes != nil {
 		in, out := &in.AccessModes, &out.AccessModes
-		*out = make([]v1.PersistentVolumeAccessMode, len(*in))
+		*out = make([]corev1.PersistentVolumeAccessMode, len(*in))
 		copy(*out, *in)
 	}
 	if in.RequestStorage != nil {
@@ -820,6 +1469,23 @@ func (in *QueueSetting) DeepCopy() *QueueSetting {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RestoreConfiguration) DeepCopyInto(out *RestoreConfiguration) {
 	*out = *in
+	if in.StartupTimeout != nil {
+		in, out := &in.StartupTimeout, &out.StartupTimeout
+		*out = new(v1.Duration)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Hooks != nil {
+		in, out := &in.Hooks, &out.Hooks
+		*out = make([]RestoreHook, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ExcludeStructures != nil {
+		in, out := &in.ExcludeStructures, &out.ExcludeStructures
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreConfiguration.
@@ -832,9 +1498,54 @@ func (in *RestoreConfiguration) DeepCopy() *RestoreConfiguration {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreHook) DeepCopyInto(out *RestoreHook) {
+	*out = *in
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreHook.
+func (in *RestoreHook) DeepCopy() *RestoreHook {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreHook)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreMemberStatus) DeepCopyInto(out *RestoreMemberStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreMemberStatus.
+func (in *RestoreMemberStatus) DeepCopy() *RestoreMemberStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreMemberStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RestoreStatus) DeepCopyInto(out *RestoreStatus) {
 	*out = *in
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make([]RestoreMemberStatus, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreStatus.
@@ -852,12 +1563,12 @@ func (in *SchedulingConfiguration) DeepCopyInto(out *SchedulingConfiguration) {
 	*out = *in
 	if in.Affinity != nil {
 		in, out := &in.Affinity, &out.Affinity
-		*out = new(v1.Affinity)
+		*out = new(corev1.Affinity)
 		(*in).DeepCopyInto(*out)
 	}
 	if in.Tolerations != nil {
 		in, out := &in.Tolerations, &out.Tolerations
-		*out = make([]v1.Toleration, len(*in))
+		*out = make([]corev1.Toleration, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -871,7 +1582,7 @@ func (in *SchedulingConfiguration) DeepCopyInto(out *SchedulingConfiguration) {
 	}
 	if in.TopologySpreadConstraints != nil {
 		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
-		*out = make([]v1.TopologySpreadConstraint, len(*in))
+		*out = make([]corev1.TopologySpreadConstraint, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -888,6 +1599,105 @@ func (in *SchedulingConfiguration) DeepCopy() *SchedulingConfiguration {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UserCode) DeepCopyInto(out *UserCode) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserCode.
+func (in *UserCode) DeepCopy() *UserCode {
+	if in == nil {
+		return nil
+	}
+	out := new(UserCode)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *UserCode) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UserCodeList) DeepCopyInto(out *UserCodeList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]UserCode, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserCodeList.
+func (in *UserCodeList) DeepCopy() *UserCodeList {
+	if in == nil {
+		return nil
+	}
+	out := new(UserCodeList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *UserCodeList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UserCodeSpec) DeepCopyInto(out *UserCodeSpec) {
+	*out = *in
+	if in.BucketConfiguration != nil {
+		in, out := &in.BucketConfiguration, &out.BucketConfiguration
+		*out = new(BucketConfiguration)
+		**out = **in
+	}
+	if in.ConfigMaps != nil {
+		in, out := &in.ConfigMaps, &out.ConfigMaps
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserCodeSpec.
+func (in *UserCodeSpec) DeepCopy() *UserCodeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(UserCodeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UserCodeStatus) DeepCopyInto(out *UserCodeStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserCodeStatus.
+func (in *UserCodeStatus) DeepCopy() *UserCodeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(UserCodeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WanReplication) DeepCopyInto(out *WanReplication) {
 	*out = *in
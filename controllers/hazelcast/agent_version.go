@@ -0,0 +1,127 @@
+package hazelcast
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/version"
+
+	hazelcastv1alpha1 "github.com/hazelcast/hazelcast-platform-operator/api/v1alpha1"
+	n "github.com/hazelcast/hazelcast-platform-operator/internal/naming"
+)
+
+// hotBackupAgentFeature is an option of the HotBackups the agents older than n.MinAgentVersion do not support.
+type hotBackupAgentFeature struct {
+	name string
+	used func(s *hazelcastv1alpha1.HotBackupSpec) bool
+}
+
+// hotBackupAgentFeatures are the options of the HotBackups sent to the agent or needing its endpoints.
+var hotBackupAgentFeatures = []hotBackupAgentFeature{
+	{"updateLatest", func(s *hazelcastv1alpha1.HotBackupSpec) bool { return s.UpdateLatest }},
+	// the older agents always use gzip
+	{"compression", func(s *hazelcastv1alpha1.HotBackupSpec) bool {
+		return s.Compression != "" && s.Compression != hazelcastv1alpha1.CompressionGzip
+	}},
+	{"compressionLevel", func(s *hazelcastv1alpha1.HotBackupSpec) bool { return s.CompressionLevel != 0 }},
+	{"verifyArchive", func(s *hazelcastv1alpha1.HotBackupSpec) bool { return s.VerifyArchive }},
+	{"metadata", func(s *hazelcastv1alpha1.HotBackupSpec) bool { return len(s.Metadata) > 0 }},
+	{"chunkedTransfer", func(s *hazelcastv1alpha1.HotBackupSpec) bool { return s.ChunkedTransfer }},
+	// the older agents only upload to the buckets of the cloud providers, not to the HTTP PUT endpoints
+	{"bucketURI", func(s *hazelcastv1alpha1.HotBackupSpec) bool {
+		return strings.HasPrefix(s.BucketURI, "http://") || strings.HasPrefix(s.BucketURI, "https://")
+	}},
+	{"objectLock", func(s *hazelcastv1alpha1.HotBackupSpec) bool { return s.ObjectLock != nil }},
+	{"failover", func(s *hazelcastv1alpha1.HotBackupSpec) bool { return s.Failover != nil }},
+	{"backupPathOverride", func(s *hazelcastv1alpha1.HotBackupSpec) bool { return s.BackupPathOverride != "" }},
+	{"keyEncoding", func(s *hazelcastv1alpha1.HotBackupSpec) bool { return s.KeyEncoding != "" }},
+	{"uploadMode", func(s *hazelcastv1alpha1.HotBackupSpec) bool {
+		return s.UploadMode == hazelcastv1alpha1.UploadSequential
+	}},
+	{"delta", func(s *hazelcastv1alpha1.HotBackupSpec) bool { return s.Delta }},
+	{"includeConfig", func(s *hazelcastv1alpha1.HotBackupSpec) bool { return s.IncludeConfig }},
+	{"collectDiagnosticsOnFailure", func(s *hazelcastv1alpha1.HotBackupSpec) bool { return s.CollectDiagnosticsOnFailure }},
+	{"requestHeaders", func(s *hazelcastv1alpha1.HotBackupSpec) bool { return len(s.RequestHeaders) > 0 }},
+	{"partSize", func(s *hazelcastv1alpha1.HotBackupSpec) bool { return s.PartSize != nil }},
+	{"maxObjectSize", func(s *hazelcastv1alpha1.HotBackupSpec) bool { return s.MaxObjectSize != nil }},
+	{"maxBackupSize", func(s *hazelcastv1alpha1.HotBackupSpec) bool { return s.MaxBackupSize != nil }},
+	{"credentialsRefreshInterval", func(s *hazelcastv1alpha1.HotBackupSpec) bool { return s.CredentialsRefreshInterval != nil }},
+	{"objectACL", func(s *hazelcastv1alpha1.HotBackupSpec) bool { return s.ObjectACL != nil }},
+	{"retention", func(s *hazelcastv1alpha1.HotBackupSpec) bool { return s.Retention != nil }},
+	{"verify", func(s *hazelcastv1alpha1.HotBackupSpec) bool { return s.Verify != nil }},
+	{"verifySampleRate", func(s *hazelcastv1alpha1.HotBackupSpec) bool { return s.VerifySampleRate != nil }},
+	{"compact", func(s *hazelcastv1alpha1.HotBackupSpec) bool { return s.Compact != nil }},
+}
+
+// restoreAgentFeature is an option of the restore the restore agents older than n.MinAgentVersion do not support.
+type restoreAgentFeature struct {
+	name string
+	used func(r *hazelcastv1alpha1.RestoreConfiguration) bool
+}
+
+// restoreAgentFeatures are the options of the restore passed to the restore agent.
+var restoreAgentFeatures = []restoreAgentFeature{
+	{"latest", func(r *hazelcastv1alpha1.RestoreConfiguration) bool { return r.Latest }},
+	{"allowVersionMismatch", func(r *hazelcastv1alpha1.RestoreConfiguration) bool { return r.AllowVersionMismatch }},
+	{"verifyDigest", func(r *hazelcastv1alpha1.RestoreConfiguration) bool { return r.VerifyDigest }},
+	{"maxConcurrentDownloads", func(r *hazelcastv1alpha1.RestoreConfiguration) bool { return r.MaxConcurrentDownloads != 0 }},
+	{"includeConfig", func(r *hazelcastv1alpha1.RestoreConfiguration) bool { return r.IncludeConfig }},
+	{"zoneAffinity", func(r *hazelcastv1alpha1.RestoreConfiguration) bool { return r.ZoneAffinity }},
+	{"keyEncoding", func(r *hazelcastv1alpha1.RestoreConfiguration) bool { return r.KeyEncoding != "" }},
+	{"sourceClusterName", func(r *hazelcastv1alpha1.RestoreConfiguration) bool { return r.SourceClusterName != "" }},
+	// the older restore agents always use gzip
+	{"compression", func(r *hazelcastv1alpha1.RestoreConfiguration) bool {
+		return r.Compression != "" && r.Compression != hazelcastv1alpha1.CompressionGzip
+	}},
+	{"excludeStructures", func(r *hazelcastv1alpha1.RestoreConfiguration) bool { return len(r.ExcludeStructures) > 0 }},
+}
+
+// agentSupportsFeatures returns true if the agent of the cluster is at least n.MinAgentVersion.
+// The versions which are not semantic, e.g. the tags of custom builds, are assumed to support the features.
+func agentSupportsFeatures(h *hazelcastv1alpha1.Hazelcast) bool {
+	if h.Spec.Agent == nil || h.Spec.Agent.Version == "" {
+		return true
+	}
+	v, err := version.ParseGeneric(h.Spec.Agent.Version)
+	if err != nil {
+		return true
+	}
+	return v.AtLeast(version.MustParseGeneric(n.MinAgentVersion))
+}
+
+// unsupportedRestoreFeatures returns the options of the restore of the cluster its agent does not support.
+func unsupportedRestoreFeatures(h *hazelcastv1alpha1.Hazelcast) []string {
+	if !h.Spec.Persistence.IsRestoreEnabled() || agentSupportsFeatures(h) {
+		return nil
+	}
+	var used []string
+	for _, f := range restoreAgentFeatures {
+		if f.used(h.Spec.Persistence.Restore) {
+			used = append(used, f.name)
+		}
+	}
+	sort.Strings(used)
+	return used
+}
+
+// unsupportedHotBackupFeatures returns the options of the HotBackup the agent of its cluster does not support.
+func unsupportedHotBackupFeatures(hb *hazelcastv1alpha1.HotBackup, h *hazelcastv1alpha1.Hazelcast) []string {
+	if agentSupportsFeatures(h) {
+		return nil
+	}
+	var used []string
+	for _, f := range hotBackupAgentFeatures {
+		if f.used(&hb.Spec) {
+			used = append(used, f.name)
+		}
+	}
+	sort.Strings(used)
+	return used
+}
+
+// agentFeaturesMessage returns the message of the features the agent of the cluster does not support.
+func agentFeaturesMessage(h *hazelcastv1alpha1.Hazelcast, features []string) string {
+	return fmt.Sprintf("agent %s of Hazelcast %s does not support %s, use agent version %s or later",
+		h.Spec.Agent.Version, h.Name, strings.Join(features, ", "), n.MinAgentVersion)
+}
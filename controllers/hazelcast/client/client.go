@@ -256,6 +256,22 @@ func (c *Client) UpdateMembers(ctx context.Context) {
 	c.Unlock()
 }
 
+// Members returns the UUIDs of the current members of the cluster, nil if the client is not connected.
+func (c *Client) Members() map[hztypes.UUID]struct{} {
+	c.Lock()
+	hzClient := c.Client
+	c.Unlock()
+	if hzClient == nil {
+		return nil
+	}
+	memberList := hazelcast.NewClientInternal(hzClient).OrderedMembers()
+	members := make(map[hztypes.UUID]struct{}, len(memberList))
+	for _, m := range memberList {
+		members[m.UUID] = struct{}{}
+	}
+	return members
+}
+
 func fetchTimedMemberState(ctx context.Context, client *hazelcast.Client, uuid hztypes.UUID) (string, error) {
 	ci := hazelcast.NewClientInternal(client)
 	req := codec.EncodeMCGetTimedMemberStateRequest()
@@ -306,6 +322,32 @@ type ClusterHotRestartStatus struct {
 	HotRestartStatus              string `json:"hotRestartStatus"`
 	RemainingValidationTimeMillis int64  `json:"remainingValidationTimeMillis"`
 	RemainingDataLoadTimeMillis   int64  `json:"remainingDataLoadTimeMillis"`
+	// MemberHotRestartStatusMap is the hot restart status of the members keyed by their addresses
+	MemberHotRestartStatusMap map[string]string `json:"memberHotRestartStatusMap"`
+}
+
+// LoadedMembers returns the number of members which finished loading their data and the number of all members.
+func (c ClusterHotRestartStatus) LoadedMembers() (int32, int32) {
+	var loaded int32
+	for _, s := range c.MemberHotRestartStatusMap {
+		if s == "SUCCESSFUL" {
+			loaded++
+		}
+	}
+	return loaded, int32(len(c.MemberHotRestartStatusMap))
+}
+
+// LoadedPartitions returns the number of partitions owned by the members which finished loading their data
+// and the number of partitions owned by all the given members.
+func (c ClusterHotRestartStatus) LoadedPartitions(members map[hztypes.UUID]*MemberData) (int32, int32) {
+	var loaded, total int32
+	for _, m := range members {
+		total += m.Partitions
+		if c.MemberHotRestartStatusMap[m.Address] == "SUCCESSFUL" {
+			loaded += m.Partitions
+		}
+	}
+	return loaded, total
 }
 
 func (c ClusterHotRestartStatus) RemainingValidationTimeSec() int64 {
@@ -4,9 +4,16 @@ import (
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"sort"
+	"sync"
+	"time"
 
 	hazelcastv1alpha1 "github.com/hazelcast/hazelcast-platform-operator/api/v1alpha1"
+	"github.com/robfig/cron/v3"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
@@ -15,6 +22,8 @@ func fakeClient(initObjs ...client.Object) client.Client {
 	scheme, _ := hazelcastv1alpha1.SchemeBuilder.
 		Register(&hazelcastv1alpha1.Hazelcast{}, &hazelcastv1alpha1.HazelcastList{}, &v1.ClusterRole{}, &v1.ClusterRoleBinding{}).
 		Build()
+	_ = corev1.AddToScheme(scheme)
+	_ = appsv1.AddToScheme(scheme)
 	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(initObjs...).Build()
 }
 
@@ -29,3 +38,83 @@ func fakeHttpServer(url string, handler http.HandlerFunc) (*httptest.Server, err
 	ts.Start()
 	return ts, nil
 }
+
+// fakeScheduler runs the scheduled jobs synchronously when its clock is stepped past their next run time.
+type fakeScheduler struct {
+	clock *clock.FakeClock
+
+	mu      sync.Mutex
+	lastID  cron.EntryID
+	entries map[cron.EntryID]*cron.Entry
+}
+
+func newFakeScheduler(c *clock.FakeClock) *fakeScheduler {
+	return &fakeScheduler{
+		clock:   c,
+		entries: make(map[cron.EntryID]*cron.Entry),
+	}
+}
+
+func (s *fakeScheduler) Schedule(schedule cron.Schedule, job cron.Job) cron.EntryID {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastID++
+	s.entries[s.lastID] = &cron.Entry{
+		ID:         s.lastID,
+		Schedule:   schedule,
+		Next:       schedule.Next(s.clock.Now()),
+		Job:        job,
+		WrappedJob: job,
+	}
+	return s.lastID
+}
+
+func (s *fakeScheduler) Remove(id cron.EntryID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, id)
+}
+
+func (s *fakeScheduler) Entry(id cron.EntryID) cron.Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e, ok := s.entries[id]; ok {
+		return *e
+	}
+	return cron.Entry{}
+}
+
+func (s *fakeScheduler) Entries() []cron.Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries := make([]cron.Entry, 0, len(s.entries))
+	for _, e := range s.entries {
+		entries = append(entries, *e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID < entries[j].ID })
+	return entries
+}
+
+func (s *fakeScheduler) Start() {}
+
+// step moves the clock forward and runs the jobs due until the new time.
+func (s *fakeScheduler) step(d time.Duration) {
+	s.clock.Step(d)
+	now := s.clock.Now()
+
+	s.mu.Lock()
+	var due []cron.Job
+	for _, e := range s.entries {
+		if e.Next.IsZero() || e.Next.After(now) {
+			continue
+		}
+		e.Prev = e.Next
+		e.Next = e.Schedule.Next(now)
+		due = append(due, e.Job)
+	}
+	s.mu.Unlock()
+
+	for _, j := range due {
+		j.Run()
+	}
+}
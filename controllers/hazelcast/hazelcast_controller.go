@@ -2,6 +2,7 @@ package hazelcast
 
 import (
 	"context"
+	goerrors "errors"
 	"fmt"
 	"time"
 
@@ -30,6 +31,9 @@ import (
 // retryAfter is the time in seconds to requeue for the Pending phase
 const retryAfter = 10 * time.Second
 
+// errRestoreHotBackupNotReady is returned while the HotBackup referenced by the restore configuration is not finished
+var errRestoreHotBackupNotReady = goerrors.New("HotBackup to restore from is not ready")
+
 // HazelcastReconciler reconciles a Hazelcast object
 type HazelcastReconciler struct {
 	client.Client
@@ -109,6 +113,13 @@ func (r *HazelcastReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 			failedPhase(err).
 				withMessage(fmt.Sprintf("error validating new Spec: %s", err)))
 	}
+	// the older restore agents would ignore the options and restore something else than asked for
+	if features := unsupportedRestoreFeatures(h); len(features) > 0 {
+		err = goerrors.New(agentFeaturesMessage(h, features))
+		return update(ctx, r.Client, h,
+			failedPhase(err).
+				withMessage(fmt.Sprintf("error validating new Spec: %s", err)))
+	}
 
 	err = r.reconcileClusterRole(ctx, h, logger)
 	if err != nil {
@@ -154,11 +165,45 @@ func (r *HazelcastReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		// Conflicts are expected and will be handled on the next reconcile loop, no need to error out here
 		if errors.IsConflict(err) {
 			return ctrl.Result{}, nil
+		} else if goerrors.Is(err, errRestoreHotBackupNotReady) {
+			logger.Info("Waiting for the HotBackup to restore from", "reason", err.Error())
+			return update(ctx, r.Client, h, pendingPhase(retryAfter).withMessage(err.Error()))
 		} else {
 			return update(ctx, r.Client, h, failedPhase(err))
 		}
 	}
 
+	if h.Spec.Persistence.IsEnabled() && h.Spec.Persistence.IsRestoreEnabled() {
+		members, err := r.reconcileRestoreDownloads(ctx, h, logger)
+		if err != nil {
+			return update(ctx, r.Client, h, failedPhase(err))
+		}
+		setRestoreMembers(h, members)
+		if err := failedRestoreDownloads(members); err != nil {
+			return update(ctx, r.Client, h, failedPhase(err).withMessage(err.Error()))
+		}
+		if h.Spec.Persistence.Restore.ZoneAffinity {
+			warning, err := r.reconcileRestoreZones(ctx, h, logger)
+			if err != nil {
+				return update(ctx, r.Client, h, failedPhase(err))
+			}
+			if warning != "" {
+				logger.Info("Zone affinity of the restore cannot be satisfied", "warning", warning)
+			}
+			h.Status.Restore.ZoneAffinityWarning = warning
+		}
+		if len(h.Spec.Persistence.Restore.ExcludeStructures) > 0 {
+			warning, err := r.reconcileRestoreExcludeStructures(ctx, h)
+			if err != nil {
+				return update(ctx, r.Client, h, failedPhase(err))
+			}
+			if warning != "" {
+				logger.Info("Unknown structures are excluded from the restore", "warning", warning)
+			}
+			h.Status.Restore.ExcludeStructuresWarning = warning
+		}
+	}
+
 	if err = r.checkHotRestart(ctx, h, logger); err != nil {
 		logger.Error(err, "Cluster HotRestart did not finish successfully")
 		return update(ctx, r.Client, h, pendingPhase(retryAfter))
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"hash/crc32"
 	"net"
+	"net/url"
+	"path"
 	"strconv"
 	"strings"
 
@@ -442,6 +444,24 @@ func hazelcastConfigMapData(ctx context.Context, c client.Client, h *hazelcastv1
 	return map[string]string{"hazelcast.yaml": string(yml)}, nil
 }
 
+// dynamicConfig returns the dynamic configuration of the cluster persisted in its ConfigMap in YAML,
+// i.e. the configs of the maps created with Map resources.
+func dynamicConfig(ctx context.Context, c client.Client, h *hazelcastv1alpha1.Hazelcast) (string, error) {
+	cm := &corev1.ConfigMap{}
+	if err := c.Get(ctx, types.NamespacedName{Name: h.Name, Namespace: h.Namespace}, cm); err != nil {
+		return "", fmt.Errorf("could not read the configuration of Hazelcast %s: %w", h.Name, err)
+	}
+	hzConfig := &config.HazelcastWrapper{}
+	if err := yaml.Unmarshal([]byte(cm.Data["hazelcast.yaml"]), hzConfig); err != nil {
+		return "", fmt.Errorf("configuration of Hazelcast %s is not formatted correctly: %w", h.Name, err)
+	}
+	yml, err := yaml.Marshal(config.HazelcastWrapper{Hazelcast: config.Hazelcast{Map: hzConfig.Hazelcast.Map}})
+	if err != nil {
+		return "", err
+	}
+	return string(yml), nil
+}
+
 func filterPersistedMaps(ml []hazelcastv1alpha1.Map) []hazelcastv1alpha1.Map {
 	l := make([]hazelcastv1alpha1.Map, 0)
 
@@ -511,7 +531,7 @@ func hazelcastConfigMapStruct(h *hazelcastv1alpha1.Hazelcast) config.Hazelcast {
 		cfg.Persistence = config.Persistence{
 			Enabled:                   &[]bool{true}[0],
 			BaseDir:                   h.Spec.Persistence.BaseDir,
-			BackupDir:                 h.Spec.Persistence.BaseDir + "/hot-backup",
+			BackupDir:                 path.Join(h.Spec.Persistence.BaseDir, n.LocalBackupDir),
 			Parallelism:               1,
 			ValidationTimeoutSec:      120,
 			DataLoadTimeoutSec:        900,
@@ -522,6 +542,16 @@ func hazelcastConfigMapStruct(h *hazelcastv1alpha1.Hazelcast) config.Hazelcast {
 			cfg.Persistence.ValidationTimeoutSec = h.Spec.Persistence.DataRecoveryTimeout
 			cfg.Persistence.DataLoadTimeoutSec = h.Spec.Persistence.DataRecoveryTimeout
 		}
+		if h.Spec.Persistence.IsRestoreEnabled() && h.Spec.Persistence.Restore.StartupTimeout != nil {
+			cfg.Persistence.DataLoadTimeoutSec = int32(h.Spec.Persistence.Restore.StartupTimeout.Seconds())
+		}
+		// the older agents do not write the file, the members would fail to start importing it
+		if h.Spec.Persistence.IsRestoreEnabled() && h.Spec.Persistence.Restore.IncludeConfig && agentSupportsFeatures(h) {
+			cfg.Import = []string{"file://" + restoreConfigFile(h.Spec.Persistence)}
+		}
+		if h.Spec.Persistence.JetLosslessRestart {
+			cfg.Jet.Instance.LosslessRestartEnabled = &[]bool{true}[0]
+		}
 	}
 	return cfg
 }
@@ -724,6 +754,24 @@ func (r *HazelcastReconciler) reconcileStatefulset(ctx context.Context, h *hazel
 		if h.Spec.Persistence.IsExternal() {
 			sts.Spec.Template.Spec.Containers = append(sts.Spec.Template.Spec.Containers, backupAgentContainer(h))
 		}
+		// the restore agents of the members download in parallel, it applies to new clusters only as the field is immutable
+		if h.Spec.Persistence.IsRestoreEnabled() {
+			sts.Spec.PodManagementPolicy = appsv1.ParallelPodManagement
+		}
+	}
+
+	var restoreBucket *hazelcastv1alpha1.BucketConfiguration
+	if h.Spec.Persistence.IsEnabled() && h.Spec.Persistence.IsRestoreEnabled() {
+		// the HotBackup is resolved once, its later runs and the newer backups must not restart the members
+		if b, ok := r.appliedRestoreBucket(ctx, h); ok {
+			restoreBucket = &b
+		} else {
+			b, err := r.restoreBucket(ctx, h)
+			if err != nil {
+				return err
+			}
+			restoreBucket = &b
+		}
 	}
 
 	err := controllerutil.SetControllerReference(h, sts, r.Scheme)
@@ -754,6 +802,9 @@ func (r *HazelcastReconciler) reconcileStatefulset(ctx context.Context, h *hazel
 			sts.Spec.Template.Spec.NodeSelector = nil
 			sts.Spec.Template.Spec.TopologySpreadConstraints = nil
 		}
+		if h.Spec.Persistence.IsEnabled() && h.Spec.Persistence.IsRestoreEnabled() && h.Spec.Persistence.Restore.ZoneAffinity {
+			sts.Spec.Template.Spec.TopologySpreadConstraints = zoneSpreadConstraints(h, sts.Spec.Template.Spec.TopologySpreadConstraints)
+		}
 
 		if h.Spec.Resources != nil {
 			sts.Spec.Template.Spec.Containers[0].Resources = *h.Spec.Resources
@@ -761,7 +812,7 @@ func (r *HazelcastReconciler) reconcileStatefulset(ctx context.Context, h *hazel
 			sts.Spec.Template.Spec.Containers[0].Resources = v1.ResourceRequirements{}
 		}
 
-		sts.Spec.Template.Spec.InitContainers = initContainers(h)
+		sts.Spec.Template.Spec.InitContainers = initContainers(h, restoreBucket)
 		sts.Spec.Template.Spec.Volumes = volumes(h)
 		sts.Spec.Template.Spec.Containers[0].VolumeMounts = volumeMounts(h)
 
@@ -839,10 +890,11 @@ func backupAgentContainer(h *hazelcastv1alpha1.Hazelcast) v1.Container {
 	}
 }
 
-func initContainers(h *hazelcastv1alpha1.Hazelcast) []corev1.Container {
+func initContainers(h *hazelcastv1alpha1.Hazelcast, restoreBucket *hazelcastv1alpha1.BucketConfiguration) []corev1.Container {
 	var containers []corev1.Container
-	if h.Spec.Persistence.IsEnabled() && h.Spec.Persistence.IsRestoreEnabled() {
-		containers = append(containers, restoreAgentContainer(h))
+	if restoreBucket != nil {
+		containers = append(containers, restoreAgentContainer(h, *restoreBucket))
+		containers = append(containers, restoreHookContainers(h)...)
 	}
 	if h.Spec.CustomClass.IsBucketEnabled() {
 		containers = append(containers, ccdAgentContainer(h))
@@ -850,7 +902,125 @@ func initContainers(h *hazelcastv1alpha1.Hazelcast) []corev1.Container {
 	return containers
 }
 
-func restoreAgentContainer(h *hazelcastv1alpha1.Hazelcast) v1.Container {
+// restoreBucket returns the bucket to restore the cluster from. If the restore refers to a HotBackup,
+// the bucket of the HotBackup is returned once the HotBackup has finished successfully.
+func (r *HazelcastReconciler) restoreBucket(ctx context.Context, h *hazelcastv1alpha1.Hazelcast) (hazelcastv1alpha1.BucketConfiguration, error) {
+	rc := h.Spec.Persistence.Restore
+	hbName, err := r.restoreHotBackupName(ctx, h)
+	if err != nil {
+		return hazelcastv1alpha1.BucketConfiguration{}, err
+	}
+	if hbName == "" {
+		return hazelcastv1alpha1.BucketConfiguration{Secret: rc.Secret, BucketURI: rc.BucketURI}, nil
+	}
+	hb := &hazelcastv1alpha1.HotBackup{}
+	if err := r.Get(ctx, types.NamespacedName{Name: hbName, Namespace: h.Namespace}, hb); err != nil {
+		if errors.IsNotFound(err) {
+			return hazelcastv1alpha1.BucketConfiguration{}, fmt.Errorf("%w: HotBackup %s not found", errRestoreHotBackupNotReady, hbName)
+		}
+		return hazelcastv1alpha1.BucketConfiguration{}, err
+	}
+	if hb.Status.State == hazelcastv1alpha1.HotBackupPartialSuccess {
+		return hazelcastv1alpha1.BucketConfiguration{}, fmt.Errorf("%w: the last backup of HotBackup %s is incomplete, %s",
+			errRestoreHotBackupNotReady, hb.Name, hb.Status.Message)
+	}
+	if hb.Status.State != hazelcastv1alpha1.HotBackupSuccess {
+		return hazelcastv1alpha1.BucketConfiguration{}, fmt.Errorf("%w: HotBackup %s is in %s state", errRestoreHotBackupNotReady, hb.Name, hb.Status.State)
+	}
+	if hb.Spec.BucketURI == "" {
+		return hazelcastv1alpha1.BucketConfiguration{}, fmt.Errorf("HotBackup %s has no bucketURI, only external backups can be restored", hb.Name)
+	}
+	if rc.SourceClusterName != "" && hb.Spec.HazelcastResourceName != rc.SourceClusterName {
+		return hazelcastv1alpha1.BucketConfiguration{}, fmt.Errorf("HotBackup %s backs up Hazelcast %s, not the source cluster %s of the restore",
+			hb.Name, hb.Spec.HazelcastResourceName, rc.SourceClusterName)
+	}
+	if rc.IncludeConfig && !hb.Spec.IncludeConfig {
+		return hazelcastv1alpha1.BucketConfiguration{}, fmt.Errorf("HotBackup %s does not include the configuration of the cluster, includeConfig cannot be restored", hb.Name)
+	}
+	bucketURI := hb.Spec.BucketURI
+	if hb.Status.BackupFolder != "" {
+		u, err := url.Parse(bucketURI)
+		if err != nil {
+			return hazelcastv1alpha1.BucketConfiguration{}, err
+		}
+		q := u.Query()
+		q.Set("prefix", hb.Status.BackupFolder+"/")
+		u.RawQuery = q.Encode()
+		bucketURI = u.String()
+	}
+	return hazelcastv1alpha1.BucketConfiguration{Secret: hb.Spec.Secret, BucketURI: bucketURI}, nil
+}
+
+// restoreHotBackupName returns the name of the HotBackup the cluster is restored from, empty if it is restored from bucketURI.
+func (r *HazelcastReconciler) restoreHotBackupName(ctx context.Context, h *hazelcastv1alpha1.Hazelcast) (string, error) {
+	rc := h.Spec.Persistence.Restore
+	if rc.Latest && rc.BucketURI == "" {
+		return r.latestHotBackup(ctx, h)
+	}
+	return rc.HotBackupResourceName, nil
+}
+
+// latestHotBackup returns the name of the HotBackup of the source cluster which uploaded a backup successfully last.
+func (r *HazelcastReconciler) latestHotBackup(ctx context.Context, h *hazelcastv1alpha1.Hazelcast) (string, error) {
+	hbList := &hazelcastv1alpha1.HotBackupList{}
+	if err := r.List(ctx, hbList, client.InNamespace(h.Namespace)); err != nil {
+		return "", err
+	}
+	source := restoreSourceName(h)
+	var latest *hazelcastv1alpha1.HotBackup
+	for i := range hbList.Items {
+		hb := &hbList.Items[i]
+		if hb.Spec.HazelcastResourceName != source || hb.Spec.BucketURI == "" || hb.Spec.Verify != nil ||
+			hb.Status.State != hazelcastv1alpha1.HotBackupSuccess || hb.Status.LastSuccessTime == nil {
+			continue
+		}
+		if latest == nil || latest.Status.LastSuccessTime.Before(hb.Status.LastSuccessTime) {
+			latest = hb
+		}
+	}
+	if latest == nil {
+		return "", fmt.Errorf("no successful HotBackup of Hazelcast %s found to restore the latest backup from", source)
+	}
+	return latest.Name, nil
+}
+
+// restoreSourceName returns the name of the Hazelcast resource the restored backup was taken from.
+func restoreSourceName(h *hazelcastv1alpha1.Hazelcast) string {
+	if s := h.Spec.Persistence.Restore.SourceClusterName; s != "" {
+		return s
+	}
+	return h.Name
+}
+
+// appliedRestoreBucket returns the bucket of the restore agent of the existing StatefulSet
+// if the cluster restores a HotBackup resource or the latest backup.
+func (r *HazelcastReconciler) appliedRestoreBucket(ctx context.Context, h *hazelcastv1alpha1.Hazelcast) (hazelcastv1alpha1.BucketConfiguration, bool) {
+	if rc := h.Spec.Persistence.Restore; !rc.Latest && rc.HotBackupResourceName == "" {
+		return hazelcastv1alpha1.BucketConfiguration{}, false
+	}
+	sts := &appsv1.StatefulSet{}
+	if err := r.Get(ctx, types.NamespacedName{Name: h.Name, Namespace: h.Namespace}, sts); err != nil {
+		return hazelcastv1alpha1.BucketConfiguration{}, false
+	}
+	for _, c := range sts.Spec.Template.Spec.InitContainers {
+		if c.Name != n.RestoreAgent {
+			continue
+		}
+		var b hazelcastv1alpha1.BucketConfiguration
+		for _, e := range c.Env {
+			switch e.Name {
+			case "RESTORE_SECRET_NAME":
+				b.Secret = e.Value
+			case "RESTORE_BUCKET":
+				b.BucketURI = e.Value
+			}
+		}
+		return b, b.BucketURI != ""
+	}
+	return hazelcastv1alpha1.BucketConfiguration{}, false
+}
+
+func restoreAgentContainer(h *hazelcastv1alpha1.Hazelcast, bucket hazelcastv1alpha1.BucketConfiguration) v1.Container {
 	return v1.Container{
 		Name:  n.RestoreAgent,
 		Image: h.AgentDockerImage(),
@@ -858,16 +1028,81 @@ func restoreAgentContainer(h *hazelcastv1alpha1.Hazelcast) v1.Container {
 		Env: []v1.EnvVar{
 			{
 				Name:  "RESTORE_SECRET_NAME",
-				Value: h.Spec.Persistence.Restore.Secret,
+				Value: bucket.Secret,
 			},
 			{
 				Name:  "RESTORE_BUCKET",
-				Value: h.Spec.Persistence.Restore.BucketURI,
+				Value: bucket.BucketURI,
 			},
 			{
 				Name:  "RESTORE_DESTINATION",
 				Value: h.Spec.Persistence.BaseDir,
 			},
+			{
+				Name:  "RESTORE_HAZELCAST_VERSION",
+				Value: h.Spec.Version,
+			},
+			{
+				Name:  "RESTORE_ALLOW_VERSION_MISMATCH",
+				Value: strconv.FormatBool(h.Spec.Persistence.Restore.AllowVersionMismatch),
+			},
+			{
+				Name:  "RESTORE_LATEST",
+				Value: strconv.FormatBool(h.Spec.Persistence.Restore.Latest && h.Spec.Persistence.Restore.BucketURI != ""),
+			},
+			{
+				Name:  "RESTORE_HAZELCAST_NAME",
+				Value: h.Name,
+			},
+			{
+				Name:  "RESTORE_SOURCE_HAZELCAST_NAME",
+				Value: restoreSourceName(h),
+			},
+			{
+				Name:  "RESTORE_CLUSTER_NAME",
+				Value: h.Spec.ClusterName,
+			},
+			{
+				Name:  "RESTORE_VERIFY_DIGEST",
+				Value: strconv.FormatBool(h.Spec.Persistence.Restore.VerifyDigest),
+			},
+			{
+				Name:  "RESTORE_COMPRESSION",
+				Value: restoreCompression(h.Spec.Persistence.Restore),
+			},
+			{
+				Name:  "RESTORE_KEY_ENCODING",
+				Value: string(h.Spec.Persistence.Restore.KeyEncoding),
+			},
+			{
+				Name:  "RESTORE_CONFIG_FILE",
+				Value: restoreConfigFile(h.Spec.Persistence),
+			},
+			{
+				Name:  "RESTORE_DOWNLOAD_GATE",
+				Value: path.Join(n.RestoreDownloadPath, n.RestoreDownloadFile),
+			},
+			{
+				Name:  "RESTORE_DOWNLOAD_WAIT",
+				Value: strconv.FormatBool(h.Spec.Persistence.Restore.MaxConcurrentDownloads > 0),
+			},
+			{
+				Name:  "RESTORE_ZONE_AFFINITY",
+				Value: strconv.FormatBool(h.Spec.Persistence.Restore.ZoneAffinity),
+			},
+			{
+				Name:  "RESTORE_ZONE_FILE",
+				Value: path.Join(n.RestoreDownloadPath, n.RestoreZoneFile),
+			},
+			{
+				// the operator sets the zone once the pod is scheduled, the agent must not read the empty file before
+				Name:  "RESTORE_ZONE_WAIT",
+				Value: strconv.FormatBool(h.Spec.Persistence.Restore.ZoneAffinity),
+			},
+			{
+				Name:  "RESTORE_EXCLUDE_STRUCTURES",
+				Value: strings.Join(h.Spec.Persistence.Restore.ExcludeStructures, ","),
+			},
 			{
 				Name: "RESTORE_HOSTNAME",
 				ValueFrom: &v1.EnvVarSource{
@@ -877,11 +1112,61 @@ func restoreAgentContainer(h *hazelcastv1alpha1.Hazelcast) v1.Container {
 				},
 			},
 		},
-		VolumeMounts: []v1.VolumeMount{{
-			Name:      n.PersistenceVolumeName,
-			MountPath: h.Spec.Persistence.BaseDir,
-		}},
+		VolumeMounts: []v1.VolumeMount{
+			{
+				Name:      n.PersistenceVolumeName,
+				MountPath: h.Spec.Persistence.BaseDir,
+			},
+			{
+				Name:      n.RestoreDownloadVolumeName,
+				MountPath: n.RestoreDownloadPath,
+			},
+		},
+	}
+}
+
+// restoreCompression returns the compression of the restored archives, auto makes the agent detect it.
+func restoreCompression(r *hazelcastv1alpha1.RestoreConfiguration) string {
+	if r.Compression == "" {
+		return "auto"
 	}
+	return string(r.Compression)
+}
+
+// restoreConfigFile returns the file the restore agent writes the dynamic configuration of the backup to,
+// the configuration is not restored if it is empty.
+func restoreConfigFile(p *hazelcastv1alpha1.HazelcastPersistenceConfiguration) string {
+	if !p.Restore.IncludeConfig {
+		return ""
+	}
+	return path.Join(p.BaseDir, n.RestoredConfigFile)
+}
+
+func restoreHookContainers(h *hazelcastv1alpha1.Hazelcast) []v1.Container {
+	var containers []v1.Container
+	for _, hook := range h.Spec.Persistence.Restore.Hooks {
+		containers = append(containers, v1.Container{
+			Name:    n.RestoreHookPrefix + hook.Name,
+			Image:   hook.Image,
+			Command: hook.Command,
+			Args:    hook.Args,
+			Env: []v1.EnvVar{
+				{
+					Name:  "PERSISTENCE_DIR",
+					Value: h.Spec.Persistence.BaseDir,
+				},
+				{
+					Name:  "HAZELCAST_VERSION",
+					Value: h.Spec.Version,
+				},
+			},
+			VolumeMounts: []v1.VolumeMount{{
+				Name:      n.PersistenceVolumeName,
+				MountPath: h.Spec.Persistence.BaseDir,
+			}},
+		})
+	}
+	return containers
 }
 
 func ccdAgentContainer(h *hazelcastv1alpha1.Hazelcast) v1.Container {
@@ -934,9 +1219,57 @@ func volumes(h *hazelcastv1alpha1.Hazelcast) []v1.Volume {
 	if h.Spec.CustomClass.IsConfigMapEnabled() {
 		vols = append(vols, customClassConfigMapVolumes(h)...)
 	}
+	if h.Spec.Persistence.IsEnabled() && h.Spec.Persistence.IsRestoreEnabled() {
+		vols = append(vols, restoreDownloadVolume())
+	}
 	return vols
 }
 
+// restoreDownloadVolume exposes RestoreDownloadAnnotation of the pod to the restore agent,
+// the agent waits for it before the download and cancels the download once it is canceled.
+// RestoreZoneAnnotation is exposed for the zone affinity of the restore, the agent waits for it as well
+// since the node labels are not available through the downward API and the operator sets it after the pod is scheduled.
+func restoreDownloadVolume() v1.Volume {
+	return v1.Volume{
+		Name: n.RestoreDownloadVolumeName,
+		VolumeSource: v1.VolumeSource{
+			DownwardAPI: &v1.DownwardAPIVolumeSource{
+				Items: []v1.DownwardAPIVolumeFile{
+					{
+						Path: n.RestoreDownloadFile,
+						FieldRef: &v1.ObjectFieldSelector{
+							FieldPath: fmt.Sprintf("metadata.annotations['%s']", n.RestoreDownloadAnnotation),
+						},
+					},
+					{
+						Path: n.RestoreZoneFile,
+						FieldRef: &v1.ObjectFieldSelector{
+							FieldPath: fmt.Sprintf("metadata.annotations['%s']", n.RestoreZoneAnnotation),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// zoneSpreadConstraints spreads the members evenly over the zones for the zone affinity of the restore
+// unless the given constraints already spread them over the zones.
+func zoneSpreadConstraints(h *hazelcastv1alpha1.Hazelcast, constraints []v1.TopologySpreadConstraint) []v1.TopologySpreadConstraint {
+	for _, c := range constraints {
+		if c.TopologyKey == v1.LabelTopologyZone {
+			return constraints
+		}
+	}
+	// the members are scheduled even if the zones cannot be satisfied, the restore status reports it
+	return append(append([]v1.TopologySpreadConstraint{}, constraints...), v1.TopologySpreadConstraint{
+		MaxSkew:           1,
+		TopologyKey:       v1.LabelTopologyZone,
+		WhenUnsatisfiable: v1.ScheduleAnyway,
+		LabelSelector:     &metav1.LabelSelector{MatchLabels: labels(h)},
+	})
+}
+
 func customClassAgentVolume(h *hazelcastv1alpha1.Hazelcast) v1.Volume {
 	return v1.Volume{
 		Name: n.CustomClassBucketVolumeName,
@@ -1158,6 +1491,12 @@ func podAnnotations(annotations map[string]string, h *hazelcastv1alpha1.Hazelcas
 		return nil, err
 	}
 	annotations[n.CurrentHazelcastConfigForcingRestartChecksum] = fmt.Sprint(crc32.ChecksumIEEE(cfgYaml))
+	// the members load the changed contents of the user code after the restart
+	if d, ok := h.Annotations[n.UserCodeDigestAnnotation]; ok {
+		annotations[n.UserCodeDigestAnnotation] = d
+	} else {
+		delete(annotations, n.UserCodeDigestAnnotation)
+	}
 
 	return annotations, nil
 }
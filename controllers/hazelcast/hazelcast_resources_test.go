@@ -2,14 +2,21 @@ package hazelcast
 
 import (
 	"context"
+	"errors"
+	"reflect"
 	"testing"
+	"time"
 
+	hztypes "github.com/hazelcast/hazelcast-go-client/types"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 
 	hazelcastv1alpha1 "github.com/hazelcast/hazelcast-platform-operator/api/v1alpha1"
 	hzclient "github.com/hazelcast/hazelcast-platform-operator/controllers/hazelcast/client"
+	n "github.com/hazelcast/hazelcast-platform-operator/internal/naming"
 )
 
 func Test_clientShutdownWhenConnectionNotEstablished(t *testing.T) {
@@ -28,8 +35,616 @@ func Test_clientShutdownWhenConnectionNotEstablished(t *testing.T) {
 	}
 }
 
+func Test_restoreBucketFromHotBackup(t *testing.T) {
+	h := &hazelcastv1alpha1.Hazelcast{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "hazelcast",
+			Namespace: "default",
+		},
+		Spec: hazelcastv1alpha1.HazelcastSpec{
+			Persistence: &hazelcastv1alpha1.HazelcastPersistenceConfiguration{
+				BaseDir: "/data/hot-restart",
+				Restore: &hazelcastv1alpha1.RestoreConfiguration{HotBackupResourceName: "hot-backup"},
+			},
+		},
+	}
+	hb := &hazelcastv1alpha1.HotBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "hot-backup",
+			Namespace: "default",
+		},
+		Spec: hazelcastv1alpha1.HotBackupSpec{
+			BucketURI: "s3://backup",
+			Secret:    "br-secret",
+		},
+		Status: hazelcastv1alpha1.HotBackupStatus{State: hazelcastv1alpha1.HotBackupInProgress},
+	}
+	r := HazelcastReconciler{Client: fakeClient(h, hb)}
+
+	if _, err := r.restoreBucket(context.Background(), h); !errors.Is(err, errRestoreHotBackupNotReady) {
+		t.Errorf("restoreBucket() error = %v, want %v", err, errRestoreHotBackupNotReady)
+	}
+
+	hb.Status.State = hazelcastv1alpha1.HotBackupSuccess
+	hb.Status.BackupFolder = "hazelcast/2022-06-02-21-57-49"
+	if err := r.Status().Update(context.Background(), hb); err != nil {
+		t.Fatalf("Failed to update HotBackup status: %v", err)
+	}
+	b, err := r.restoreBucket(context.Background(), h)
+	if err != nil {
+		t.Fatalf("restoreBucket() error = %v", err)
+	}
+	want := hazelcastv1alpha1.BucketConfiguration{
+		Secret:    "br-secret",
+		BucketURI: "s3://backup?prefix=hazelcast%2F2022-06-02-21-57-49%2F",
+	}
+	if b != want {
+		t.Errorf("restoreBucket() = %v, want %v", b, want)
+	}
+
+	hb.Status.State = hazelcastv1alpha1.HotBackupPartialSuccess
+	if err := r.Status().Update(context.Background(), hb); err != nil {
+		t.Fatalf("Failed to update HotBackup status: %v", err)
+	}
+	if _, err := r.restoreBucket(context.Background(), h); !errors.Is(err, errRestoreHotBackupNotReady) {
+		t.Errorf("restoreBucket() error = %v, want %v for an incomplete backup", err, errRestoreHotBackupNotReady)
+	}
+}
+
+func Test_appliedRestoreBucketFromHotBackup(t *testing.T) {
+	h := &hazelcastv1alpha1.Hazelcast{
+		ObjectMeta: metav1.ObjectMeta{Name: "hazelcast", Namespace: "default"},
+		Spec: hazelcastv1alpha1.HazelcastSpec{
+			Agent: &hazelcastv1alpha1.AgentConfiguration{Repository: "hazelcast/platform-operator-agent", Version: "0.2.0"},
+			Persistence: &hazelcastv1alpha1.HazelcastPersistenceConfiguration{
+				BaseDir: "/data/hot-restart",
+				Restore: &hazelcastv1alpha1.RestoreConfiguration{HotBackupResourceName: "hot-backup"},
+			},
+		},
+	}
+	applied := hazelcastv1alpha1.BucketConfiguration{
+		Secret:    "br-secret",
+		BucketURI: "s3://backup?prefix=hazelcast%2F2022-06-01-21-57-49%2F",
+	}
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "hazelcast", Namespace: "default"},
+		Spec: appsv1.StatefulSetSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{InitContainers: []corev1.Container{restoreAgentContainer(h, applied)}},
+			},
+		},
+	}
+	r := HazelcastReconciler{Client: fakeClient(h)}
+	if _, ok := r.appliedRestoreBucket(context.Background(), h); ok {
+		t.Error("appliedRestoreBucket() ok = true, want false without a StatefulSet")
+	}
+
+	// the HotBackup ran again and backs up a newer folder
+	hb := &hazelcastv1alpha1.HotBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: "hot-backup", Namespace: "default"},
+		Spec:       hazelcastv1alpha1.HotBackupSpec{BucketURI: "s3://backup", Secret: "br-secret"},
+		Status: hazelcastv1alpha1.HotBackupStatus{
+			State:        hazelcastv1alpha1.HotBackupSuccess,
+			BackupFolder: "hazelcast/2022-06-02-21-57-49",
+		},
+	}
+	r = HazelcastReconciler{Client: fakeClient(h, sts, hb)}
+	b, ok := r.appliedRestoreBucket(context.Background(), h)
+	if !ok || b != applied {
+		t.Errorf("appliedRestoreBucket() = %v, %v, want the applied bucket %v", b, ok, applied)
+	}
+
+	h.Spec.Persistence.Restore = &hazelcastv1alpha1.RestoreConfiguration{BucketURI: "s3://backup"}
+	if _, ok := r.appliedRestoreBucket(context.Background(), h); ok {
+		t.Error("appliedRestoreBucket() ok = true, want false when the bucket is set in the spec")
+	}
+}
+
+func Test_restoreBucketFromLatestHotBackup(t *testing.T) {
+	h := &hazelcastv1alpha1.Hazelcast{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "hazelcast",
+			Namespace: "default",
+		},
+		Spec: hazelcastv1alpha1.HazelcastSpec{
+			Persistence: &hazelcastv1alpha1.HazelcastPersistenceConfiguration{
+				BaseDir: "/data/hot-restart",
+				Restore: &hazelcastv1alpha1.RestoreConfiguration{Latest: true},
+			},
+		},
+	}
+	r := HazelcastReconciler{Client: fakeClient(h)}
+	if _, err := r.restoreBucket(context.Background(), h); err == nil {
+		t.Error("restoreBucket() error = nil, want an error if there is no successful HotBackup")
+	}
+
+	hotBackup := func(name, folder string, finished time.Time) *hazelcastv1alpha1.HotBackup {
+		ts := metav1.NewTime(finished)
+		return &hazelcastv1alpha1.HotBackup{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: hazelcastv1alpha1.HotBackupSpec{
+				HazelcastResourceName: "hazelcast",
+				BucketURI:             "s3://backup",
+				Secret:                "br-secret",
+			},
+			Status: hazelcastv1alpha1.HotBackupStatus{
+				State:           hazelcastv1alpha1.HotBackupSuccess,
+				BackupFolder:    folder,
+				LastSuccessTime: &ts,
+			},
+		}
+	}
+	now := time.Now()
+	// the last run of the HotBackup succeeded only partially, its earlier backup is not restored either
+	partial := hotBackup("partial", "hazelcast/2022-06-01-09-57-49", now.Add(time.Hour))
+	partial.Status.State = hazelcastv1alpha1.HotBackupPartialSuccess
+	r = HazelcastReconciler{Client: fakeClient(h,
+		hotBackup("older", "hazelcast/2022-06-01-21-57-49", now.Add(-24*time.Hour)),
+		hotBackup("newer", "hazelcast/2022-06-02-21-57-49", now),
+		partial,
+	)}
+	b, err := r.restoreBucket(context.Background(), h)
+	if err != nil {
+		t.Fatalf("restoreBucket() error = %v", err)
+	}
+	want := hazelcastv1alpha1.BucketConfiguration{
+		Secret:    "br-secret",
+		BucketURI: "s3://backup?prefix=hazelcast%2F2022-06-02-21-57-49%2F",
+	}
+	if b != want {
+		t.Errorf("restoreBucket() = %v, want %v", b, want)
+	}
+}
+
+func Test_restoreFromSourceCluster(t *testing.T) {
+	h := &hazelcastv1alpha1.Hazelcast{
+		ObjectMeta: metav1.ObjectMeta{Name: "prod-dr", Namespace: "default"},
+		Spec: hazelcastv1alpha1.HazelcastSpec{
+			ClusterName: "dr",
+			Agent:       &hazelcastv1alpha1.AgentConfiguration{Repository: "hazelcast/platform-operator-agent", Version: "0.1.0"},
+			Persistence: &hazelcastv1alpha1.HazelcastPersistenceConfiguration{
+				BaseDir: "/data/hot-restart",
+				Restore: &hazelcastv1alpha1.RestoreConfiguration{Latest: true, SourceClusterName: "prod"},
+			},
+		},
+	}
+	ts := metav1.Now()
+	hb := &hazelcastv1alpha1.HotBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: "prod-daily", Namespace: "default"},
+		Spec:       hazelcastv1alpha1.HotBackupSpec{HazelcastResourceName: "prod", BucketURI: "s3://backup"},
+		Status: hazelcastv1alpha1.HotBackupStatus{
+			State:           hazelcastv1alpha1.HotBackupSuccess,
+			BackupFolder:    "prod/2022-06-02-21-57-49",
+			LastSuccessTime: &ts,
+		},
+	}
+	r := HazelcastReconciler{Client: fakeClient(h, hb)}
+	b, err := r.restoreBucket(context.Background(), h)
+	if err != nil {
+		t.Fatalf("restoreBucket() error = %v", err)
+	}
+	if want := "s3://backup?prefix=prod%2F2022-06-02-21-57-49%2F"; b.BucketURI != want {
+		t.Errorf("restoreBucket() = %v, want the latest backup of the source cluster %v", b.BucketURI, want)
+	}
+
+	env := make(map[string]string)
+	for _, e := range restoreAgentContainer(h, b).Env {
+		env[e.Name] = e.Value
+	}
+	if env["RESTORE_SOURCE_HAZELCAST_NAME"] != "prod" || env["RESTORE_HAZELCAST_NAME"] != "prod-dr" || env["RESTORE_CLUSTER_NAME"] != "dr" {
+		t.Errorf("restore agent env = %v, want prod restored into prod-dr with cluster name dr", env)
+	}
+
+	h.Spec.Persistence.Restore = &hazelcastv1alpha1.RestoreConfiguration{HotBackupResourceName: "prod-daily", SourceClusterName: "staging"}
+	if _, err := r.restoreBucket(context.Background(), h); err == nil {
+		t.Error("restoreBucket() error = nil, want an error if the HotBackup does not back up the source cluster")
+	}
+}
+
+func Test_restoreAgentContainerCompression(t *testing.T) {
+	h := &hazelcastv1alpha1.Hazelcast{
+		Spec: hazelcastv1alpha1.HazelcastSpec{
+			Agent: &hazelcastv1alpha1.AgentConfiguration{Repository: "hazelcast/platform-operator-agent", Version: "0.1.0"},
+			Persistence: &hazelcastv1alpha1.HazelcastPersistenceConfiguration{
+				BaseDir: "/data/hot-restart",
+				Restore: &hazelcastv1alpha1.RestoreConfiguration{BucketURI: "s3://backup"},
+			},
+		},
+	}
+	compression := func() string {
+		for _, e := range restoreAgentContainer(h, hazelcastv1alpha1.BucketConfiguration{}).Env {
+			if e.Name == "RESTORE_COMPRESSION" {
+				return e.Value
+			}
+		}
+		return ""
+	}
+	if got := compression(); got != "auto" {
+		t.Errorf("RESTORE_COMPRESSION = %q, want auto", got)
+	}
+	h.Spec.Persistence.Restore.Compression = hazelcastv1alpha1.CompressionZstd
+	if got := compression(); got != "zstd" {
+		t.Errorf("RESTORE_COMPRESSION = %q, want zstd", got)
+	}
+}
+
 func reconcilerWithCR(h *hazelcastv1alpha1.Hazelcast) HazelcastReconciler {
 	return HazelcastReconciler{
 		Client: fakeClient(h),
 	}
 }
+
+func Test_restoreStartupTimeout(t *testing.T) {
+	h := &hazelcastv1alpha1.Hazelcast{
+		Spec: hazelcastv1alpha1.HazelcastSpec{
+			Persistence: &hazelcastv1alpha1.HazelcastPersistenceConfiguration{
+				BaseDir:             "/data/hot-restart",
+				DataRecoveryTimeout: 300,
+				Restore: &hazelcastv1alpha1.RestoreConfiguration{
+					BucketURI:      "s3://backup",
+					StartupTimeout: &metav1.Duration{Duration: 2 * time.Hour},
+				},
+			},
+		},
+	}
+	p := hazelcastConfigMapStruct(h).Persistence
+	if p.DataLoadTimeoutSec != 7200 || p.ValidationTimeoutSec != 300 {
+		t.Errorf("timeouts = %d, %d, want 7200, 300", p.DataLoadTimeoutSec, p.ValidationTimeoutSec)
+	}
+
+	h.Spec.Persistence.Restore = nil
+	if p := hazelcastConfigMapStruct(h).Persistence; p.DataLoadTimeoutSec != 300 {
+		t.Errorf("DataLoadTimeoutSec = %d without restore, want 300", p.DataLoadTimeoutSec)
+	}
+}
+
+func Test_restoreIncludeConfig(t *testing.T) {
+	h := &hazelcastv1alpha1.Hazelcast{
+		Spec: hazelcastv1alpha1.HazelcastSpec{
+			Persistence: &hazelcastv1alpha1.HazelcastPersistenceConfiguration{
+				BaseDir: "/data/hot-restart",
+				Restore: &hazelcastv1alpha1.RestoreConfiguration{
+					BucketURI:     "s3://backup",
+					IncludeConfig: true,
+				},
+			},
+		},
+	}
+	if imports := hazelcastConfigMapStruct(h).Import; len(imports) != 1 || imports[0] != "file:///data/hot-restart/restored-config.yaml" {
+		t.Errorf("Import = %v, want the restored config file", imports)
+	}
+	if f := restoreConfigFile(h.Spec.Persistence); f != "/data/hot-restart/restored-config.yaml" {
+		t.Errorf("restoreConfigFile() = %q", f)
+	}
+
+	// the older agents do not write the restored config file
+	h.Spec.Agent = &hazelcastv1alpha1.AgentConfiguration{Version: "0.1.5"}
+	if imports := hazelcastConfigMapStruct(h).Import; len(imports) != 0 {
+		t.Errorf("Import = %v with agent 0.1.5, want none", imports)
+	}
+	h.Spec.Agent = nil
+
+	h.Spec.Persistence.Restore.IncludeConfig = false
+	if imports := hazelcastConfigMapStruct(h).Import; len(imports) != 0 {
+		t.Errorf("Import = %v without includeConfig, want none", imports)
+	}
+	if f := restoreConfigFile(h.Spec.Persistence); f != "" {
+		t.Errorf("restoreConfigFile() = %q without includeConfig, want empty", f)
+	}
+}
+
+func Test_reconcileRestoreDownloads(t *testing.T) {
+	h := &hazelcastv1alpha1.Hazelcast{
+		ObjectMeta: metav1.ObjectMeta{Name: "hazelcast", Namespace: "default"},
+		Spec: hazelcastv1alpha1.HazelcastSpec{
+			Persistence: &hazelcastv1alpha1.HazelcastPersistenceConfiguration{
+				BaseDir: "/data/hot-restart",
+				Restore: &hazelcastv1alpha1.RestoreConfiguration{
+					BucketURI:              "s3://backup",
+					MaxConcurrentDownloads: 2,
+				},
+			},
+		},
+	}
+	pod := func(name string, state corev1.ContainerState) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: h.Namespace, Labels: labels(h)},
+			Status: corev1.PodStatus{
+				InitContainerStatuses: []corev1.ContainerStatus{{Name: n.RestoreAgent, State: state}},
+			},
+		}
+	}
+	running := corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}
+	done := corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 0}}
+	c := fakeClient(pod("hazelcast-0", done), pod("hazelcast-1", running), pod("hazelcast-2", running), pod("hazelcast-3", running))
+	r := &HazelcastReconciler{Client: c}
+	gate := func(name string) string {
+		p := &corev1.Pod{}
+		if err := c.Get(context.Background(), types.NamespacedName{Name: name, Namespace: h.Namespace}, p); err != nil {
+			t.Fatal(err)
+		}
+		return p.Annotations[n.RestoreDownloadAnnotation]
+	}
+
+	members, err := r.reconcileRestoreDownloads(context.Background(), h, ctrl.Log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if members[0].State != hazelcastv1alpha1.RestoreDownloadSucceeded || members[1].State != hazelcastv1alpha1.RestoreDownloadWaiting {
+		t.Errorf("members = %v, want the finished and the waiting downloads", members)
+	}
+	if gate("hazelcast-1") != n.RestoreDownloadAllowed || gate("hazelcast-2") != n.RestoreDownloadAllowed || gate("hazelcast-3") != "" {
+		t.Errorf("downloads allowed for %q, %q, %q, want the first two waiting members only",
+			gate("hazelcast-1"), gate("hazelcast-2"), gate("hazelcast-3"))
+	}
+
+	setAgentStatus := func(name string, restarts int32, state corev1.ContainerState) {
+		p := &corev1.Pod{}
+		if err := c.Get(context.Background(), types.NamespacedName{Name: name, Namespace: h.Namespace}, p); err != nil {
+			t.Fatal(err)
+		}
+		p.Status.InitContainerStatuses[0].RestartCount = restarts
+		p.Status.InitContainerStatuses[0].State = state
+		if err := c.Update(context.Background(), p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	failure := corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 1, Message: "access denied"}}
+
+	// the failed download is retried by the restart of the agent, the others go on
+	setAgentStatus("hazelcast-1", 1, failure)
+	members, err = r.reconcileRestoreDownloads(context.Background(), h, ctrl.Log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if members[1].State != hazelcastv1alpha1.RestoreDownloadRetrying || gate("hazelcast-2") != n.RestoreDownloadAllowed {
+		t.Errorf("member = %v, download %q, want the failed download retried and the others allowed", members[1], gate("hazelcast-2"))
+	}
+	if err := failedRestoreDownloads(members); err != nil {
+		t.Errorf("failedRestoreDownloads() = %v, want no failure while the download is retried", err)
+	}
+
+	// the download failing after the restarts cancels the others
+	setAgentStatus("hazelcast-1", restoreDownloadRestarts, failure)
+	members, err = r.reconcileRestoreDownloads(context.Background(), h, ctrl.Log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gate("hazelcast-2") != n.RestoreDownloadCanceled || gate("hazelcast-3") != n.RestoreDownloadCanceled {
+		t.Errorf("downloads %q, %q, want both canceled", gate("hazelcast-2"), gate("hazelcast-3"))
+	}
+	if members[1].Message != "access denied" {
+		t.Errorf("message = %q, want the message of the restore agent", members[1].Message)
+	}
+	members, _ = r.reconcileRestoreDownloads(context.Background(), h, ctrl.Log)
+	if err := failedRestoreDownloads(members); err == nil || err.Error() != "download of the backup failed on members hazelcast-1, the downloads of the other members are canceled" {
+		t.Errorf("failedRestoreDownloads() = %v", err)
+	}
+
+	// the canceled downloads are resumed once no download failed anymore
+	setAgentStatus("hazelcast-1", 0, running)
+	members, err = r.reconcileRestoreDownloads(context.Background(), h, ctrl.Log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gate("hazelcast-2") != n.RestoreDownloadAllowed || gate("hazelcast-3") != "" {
+		t.Errorf("downloads %q, %q, want the canceled downloads waiting for the concurrency limit again", gate("hazelcast-2"), gate("hazelcast-3"))
+	}
+	if err := failedRestoreDownloads(members); err != nil {
+		t.Errorf("failedRestoreDownloads() = %v, want no failure", err)
+	}
+}
+
+func Test_reconcileRestoreZones(t *testing.T) {
+	h := &hazelcastv1alpha1.Hazelcast{
+		ObjectMeta: metav1.ObjectMeta{Name: "hazelcast", Namespace: "default"},
+		Spec: hazelcastv1alpha1.HazelcastSpec{
+			ClusterSize: &[]int32{3}[0],
+			Persistence: &hazelcastv1alpha1.HazelcastPersistenceConfiguration{
+				BaseDir: "/data/hot-restart",
+				Restore: &hazelcastv1alpha1.RestoreConfiguration{
+					HotBackupResourceName: "backup",
+					ZoneAffinity:          true,
+				},
+			},
+		},
+	}
+	node := func(name, zone string) *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{corev1.LabelTopologyZone: zone}}}
+	}
+	pod := func(name, node string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: h.Namespace, Labels: labels(h)},
+			Spec:       corev1.PodSpec{NodeName: node},
+		}
+	}
+	hb := &hazelcastv1alpha1.HotBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: "backup", Namespace: h.Namespace},
+		Status: hazelcastv1alpha1.HotBackupStatus{
+			Members: []hazelcastv1alpha1.HotBackupMemberStatus{{Zone: "zone-a"}, {Zone: "zone-b"}, {Zone: "zone-b"}},
+		},
+	}
+	c := fakeClient(node("node-a", "zone-a"), node("node-b", "zone-b"), hb,
+		pod("hazelcast-0", "node-a"), pod("hazelcast-1", "node-b"), pod("hazelcast-2", ""))
+	r := &HazelcastReconciler{Client: c}
+	zone := func(name string) string {
+		p := &corev1.Pod{}
+		if err := c.Get(context.Background(), types.NamespacedName{Name: name, Namespace: h.Namespace}, p); err != nil {
+			t.Fatal(err)
+		}
+		return p.Annotations[n.RestoreZoneAnnotation]
+	}
+
+	warning, err := r.reconcileRestoreZones(context.Background(), h, ctrl.Log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if warning != "" {
+		t.Errorf("warning = %q, want none until all the members are scheduled", warning)
+	}
+	if zone("hazelcast-0") != "zone-a" || zone("hazelcast-1") != "zone-b" || zone("hazelcast-2") != "" {
+		t.Errorf("zones %q, %q, %q, want the zones of the nodes of the scheduled members", zone("hazelcast-0"), zone("hazelcast-1"), zone("hazelcast-2"))
+	}
+
+	// the last member is scheduled in the zone which had one member only
+	p := &corev1.Pod{}
+	if err := c.Get(context.Background(), types.NamespacedName{Name: "hazelcast-2", Namespace: h.Namespace}, p); err != nil {
+		t.Fatal(err)
+	}
+	p.Spec.NodeName = "node-a"
+	if err := c.Update(context.Background(), p); err != nil {
+		t.Fatal(err)
+	}
+	warning, err = r.reconcileRestoreZones(context.Background(), h, ctrl.Log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "zone topology of the cluster cannot satisfy the backup: zone-b has 1 of 2 members"; warning != want {
+		t.Errorf("warning = %q, want %q", warning, want)
+	}
+
+	h.Spec.Agent = &hazelcastv1alpha1.AgentConfiguration{Repository: "hazelcast/platform-operator-agent", Version: "0.2.0"}
+	env := make(map[string]string)
+	for _, e := range restoreAgentContainer(h, hazelcastv1alpha1.BucketConfiguration{}).Env {
+		env[e.Name] = e.Value
+	}
+	if env["RESTORE_ZONE_WAIT"] != "true" {
+		t.Errorf("RESTORE_ZONE_WAIT = %q, want the restore agent to wait for the zone of its node", env["RESTORE_ZONE_WAIT"])
+	}
+}
+
+func Test_restoreExcludeStructures(t *testing.T) {
+	h := &hazelcastv1alpha1.Hazelcast{
+		Spec: hazelcastv1alpha1.HazelcastSpec{
+			Agent: &hazelcastv1alpha1.AgentConfiguration{Repository: "hazelcast/platform-operator-agent", Version: "0.2.0"},
+			Persistence: &hazelcastv1alpha1.HazelcastPersistenceConfiguration{
+				BaseDir: "/data/hot-restart",
+				Restore: &hazelcastv1alpha1.RestoreConfiguration{
+					BucketURI:         "s3://backup",
+					ExcludeStructures: []string{"cache", "missing"},
+				},
+			},
+		},
+	}
+	var zero int32
+	maps := []hazelcastv1alpha1.Map{
+		{ObjectMeta: metav1.ObjectMeta{Name: "cache"}, Spec: hazelcastv1alpha1.MapSpec{PersistenceEnabled: true}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "orders"}, Spec: hazelcastv1alpha1.MapSpec{PersistenceEnabled: true}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "sessions"}, Spec: hazelcastv1alpha1.MapSpec{Name: "missing"}},
+	}
+	for i := range maps {
+		maps[i].Spec.BackupCount = &zero
+		maps[i].Spec.TimeToLiveSeconds = &zero
+		maps[i].Spec.Eviction = &hazelcastv1alpha1.EvictionConfig{MaxSize: &zero}
+	}
+
+	for _, tt := range []struct {
+		m    hazelcastv1alpha1.Map
+		want bool
+	}{
+		// the excluded maps are still persisted, only their data is not restored
+		{maps[0], true},
+		{maps[1], true},
+		{maps[2], false},
+	} {
+		mc, err := createMapConfig(context.Background(), nil, h, &tt.m)
+		if err != nil {
+			t.Fatalf("createMapConfig(%s): %v", tt.m.Name, err)
+		}
+		if mc.HotRestart.Enabled != tt.want {
+			t.Errorf("hot restart of map %s = %v, want %v", tt.m.Name, mc.HotRestart.Enabled, tt.want)
+		}
+	}
+
+	if unknown := unknownExcludedStructures(h.Spec.Persistence.Restore.ExcludeStructures, maps); len(unknown) != 1 || unknown[0] != "missing" {
+		t.Errorf("unknownExcludedStructures() = %v, want [missing]", unknown)
+	}
+
+	env := map[string]string{}
+	for _, e := range restoreAgentContainer(h, hazelcastv1alpha1.BucketConfiguration{BucketURI: "s3://backup"}).Env {
+		env[e.Name] = e.Value
+	}
+	if env["RESTORE_EXCLUDE_STRUCTURES"] != "cache,missing" {
+		t.Errorf("RESTORE_EXCLUDE_STRUCTURES = %q, want cache,missing", env["RESTORE_EXCLUDE_STRUCTURES"])
+	}
+}
+
+func Test_restorePartitionProgress(t *testing.T) {
+	h := &hazelcastv1alpha1.Hazelcast{
+		ObjectMeta: metav1.ObjectMeta{Name: "hazelcast", Namespace: "default"},
+		Spec: hazelcastv1alpha1.HazelcastSpec{
+			Persistence: &hazelcastv1alpha1.HazelcastPersistenceConfiguration{BaseDir: "/data/hot-restart"},
+		},
+	}
+	r := reconcilerWithCR(h)
+	status := &hzclient.Status{
+		MemberMap: map[hztypes.UUID]*hzclient.MemberData{
+			hztypes.NewUUID(): {Address: "10.0.0.1:5701", Partitions: 136},
+			hztypes.NewUUID(): {Address: "10.0.0.2:5701", Partitions: 135},
+		},
+		ClusterHotRestartStatus: hzclient.ClusterHotRestartStatus{
+			HotRestartStatus: "IN_PROGRESS",
+			MemberHotRestartStatusMap: map[string]string{
+				"10.0.0.1:5701": "SUCCESSFUL",
+				"10.0.0.2:5701": "LOAD_IN_PROGRESS",
+			},
+		},
+	}
+	if _, err := update(context.Background(), r.Client, h, runningPhase().withStatus(status)); err != nil {
+		t.Fatalf("update() error = %v", err)
+	}
+	if rs := h.Status.Restore; rs == nil || rs.LoadedPartitions != 136 || rs.TotalPartitions != 271 {
+		t.Errorf("restore status = %+v, want 136 of 271 partitions loaded", rs)
+	}
+}
+
+func Test_unsupportedAgentFeatures(t *testing.T) {
+	h := &hazelcastv1alpha1.Hazelcast{
+		ObjectMeta: metav1.ObjectMeta{Name: "hazelcast", Namespace: "default"},
+		Spec: hazelcastv1alpha1.HazelcastSpec{
+			Agent: &hazelcastv1alpha1.AgentConfiguration{Version: "0.1.5"},
+			Persistence: &hazelcastv1alpha1.HazelcastPersistenceConfiguration{
+				BaseDir: "/data/hot-restart",
+				Restore: &hazelcastv1alpha1.RestoreConfiguration{BucketURI: "s3://backup"},
+			},
+		},
+	}
+	hb := &hazelcastv1alpha1.HotBackup{Spec: hazelcastv1alpha1.HotBackupSpec{
+		BucketURI:    "s3://backup",
+		UpdateLatest: true,
+	}}
+
+	if got := unsupportedHotBackupFeatures(hb, h); !reflect.DeepEqual(got, []string{"updateLatest"}) {
+		t.Errorf("unsupportedHotBackupFeatures() = %v", got)
+	}
+	// the restore from a bucket is supported by all the agents
+	if got := unsupportedRestoreFeatures(h); got != nil {
+		t.Errorf("unsupportedRestoreFeatures() = %v, want none", got)
+	}
+	http := &hazelcastv1alpha1.HotBackup{Spec: hazelcastv1alpha1.HotBackupSpec{
+		BucketURI:       "https://backup.example.com/hazelcast",
+		ChunkedTransfer: true,
+	}}
+	if got := unsupportedHotBackupFeatures(http, h); !reflect.DeepEqual(got, []string{"bucketURI", "chunkedTransfer"}) {
+		t.Errorf("unsupportedHotBackupFeatures() of HTTP bucket = %v", got)
+	}
+	h.Spec.Persistence.Restore.Latest = true
+	if got := unsupportedRestoreFeatures(h); !reflect.DeepEqual(got, []string{"latest"}) {
+		t.Errorf("unsupportedRestoreFeatures() = %v", got)
+	}
+	want := "agent 0.1.5 of Hazelcast hazelcast does not support updateLatest, use agent version 0.2.0 or later"
+	if got := agentFeaturesMessage(h, []string{"updateLatest"}); got != want {
+		t.Errorf("agentFeaturesMessage() = %q, want %q", got, want)
+	}
+
+	// the supporting and the custom versions
+	for _, v := range []string{"0.2.0", "v0.3.1", "latest-snapshot"} {
+		h.Spec.Agent.Version = v
+		if got := unsupportedHotBackupFeatures(hb, h); got != nil {
+			t.Errorf("unsupportedHotBackupFeatures() of agent %s = %v, want none", v, got)
+		}
+		if got := unsupportedRestoreFeatures(h); got != nil {
+			t.Errorf("unsupportedRestoreFeatures() of agent %s = %v, want none", v, got)
+		}
+	}
+}
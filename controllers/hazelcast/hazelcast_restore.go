@@ -0,0 +1,312 @@
+package hazelcast
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	hazelcastv1alpha1 "github.com/hazelcast/hazelcast-platform-operator/api/v1alpha1"
+	n "github.com/hazelcast/hazelcast-platform-operator/internal/naming"
+)
+
+// restoreDownloadCanceledMessage is the message of the members whose download was canceled by the failure of another one
+const restoreDownloadCanceledMessage = "Download was canceled as the download of another member failed"
+
+// restoreDownloadRestarts is the number of restarts of a restore agent after a failed download, the download
+// of the member fails once they are exhausted
+const restoreDownloadRestarts = 3
+
+// reconcileRestoreDownloads lets the restore agents of the members download the backup within the concurrency limit
+// and cancels the downloads of all the members once the download of one of them failed after its restarts.
+// The canceled downloads are resumed if no download failed anymore, e.g. the failed member was replaced.
+// It returns the state of the download of each member.
+func (r *HazelcastReconciler) reconcileRestoreDownloads(ctx context.Context, h *hazelcastv1alpha1.Hazelcast, logger logr.Logger) ([]hazelcastv1alpha1.RestoreMemberStatus, error) {
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, client.InNamespace(h.Namespace), client.MatchingLabels(labels(h))); err != nil {
+		return nil, err
+	}
+	sort.Slice(pods.Items, func(i, j int) bool { return pods.Items[i].Name < pods.Items[j].Name })
+
+	limit := h.Spec.Persistence.Restore.MaxConcurrentDownloads
+	members := make([]hazelcastv1alpha1.RestoreMemberStatus, len(pods.Items))
+	var failed bool
+	var allowed int32
+	for i := range pods.Items {
+		p := &pods.Items[i]
+		members[i] = restoreMemberStatus(p, limit > 0)
+		switch {
+		case members[i].State == hazelcastv1alpha1.RestoreDownloadFailed && members[i].Message != restoreDownloadCanceledMessage:
+			failed = true
+		case members[i].State != hazelcastv1alpha1.RestoreDownloadSucceeded && p.Annotations[n.RestoreDownloadAnnotation] == n.RestoreDownloadAllowed:
+			allowed++
+		}
+	}
+
+	for i := range pods.Items {
+		p := &pods.Items[i]
+		canceled := p.Annotations[n.RestoreDownloadAnnotation] == n.RestoreDownloadCanceled
+		if isRestoreDownloadFinished(members[i].State) && !(canceled && !failed) {
+			continue
+		}
+		var gate string
+		switch {
+		case failed:
+			gate = n.RestoreDownloadCanceled
+		case limit > 0 && p.Annotations[n.RestoreDownloadAnnotation] != n.RestoreDownloadAllowed && allowed < limit:
+			gate = n.RestoreDownloadAllowed
+			allowed++
+		case canceled:
+			// the download waits for the concurrency limit again
+			gate = ""
+		default:
+			continue
+		}
+		if p.Annotations[n.RestoreDownloadAnnotation] == gate {
+			continue
+		}
+		patch := client.MergeFrom(p.DeepCopy())
+		if p.Annotations == nil {
+			p.Annotations = make(map[string]string)
+		}
+		if gate == "" {
+			delete(p.Annotations, n.RestoreDownloadAnnotation)
+		} else {
+			p.Annotations[n.RestoreDownloadAnnotation] = gate
+		}
+		if err := r.Patch(ctx, p, patch); err != nil {
+			return nil, err
+		}
+		if canceled && !failed {
+			members[i] = restoreMemberStatus(p, limit > 0)
+		}
+		logger.Info("Updated the download of the restore agent", "pod", p.Name, "download", gate)
+	}
+	return members, nil
+}
+
+// restoreMemberStatus returns the state of the download of the member from the status of its restore agent.
+// The agents of gated downloads wait until the download is allowed. A failed download is retried by the restart
+// of the agent, it fails once the agent was restarted restoreDownloadRestarts times.
+func restoreMemberStatus(p *corev1.Pod, gated bool) hazelcastv1alpha1.RestoreMemberStatus {
+	ms := hazelcastv1alpha1.RestoreMemberStatus{PodName: p.Name, State: hazelcastv1alpha1.RestoreDownloadWaiting}
+	for _, s := range p.Status.InitContainerStatuses {
+		if s.Name != n.RestoreAgent {
+			continue
+		}
+		t := s.State.Terminated
+		if t == nil && s.LastTerminationState.Terminated != nil && s.LastTerminationState.Terminated.ExitCode != 0 {
+			// the agent is restarted after the failed download
+			t = s.LastTerminationState.Terminated
+		}
+		switch {
+		case s.State.Terminated != nil && s.State.Terminated.ExitCode == 0:
+			ms.State = hazelcastv1alpha1.RestoreDownloadSucceeded
+		case p.Annotations[n.RestoreDownloadAnnotation] == n.RestoreDownloadCanceled:
+			ms.State = hazelcastv1alpha1.RestoreDownloadFailed
+			ms.Message = restoreDownloadCanceledMessage
+		case t != nil && s.RestartCount >= restoreDownloadRestarts:
+			ms.State = hazelcastv1alpha1.RestoreDownloadFailed
+			ms.Message = terminationMessage(t)
+		case t != nil:
+			ms.State = hazelcastv1alpha1.RestoreDownloadRetrying
+			ms.Message = fmt.Sprintf("Download is retried after %d of %d restarts: %s", s.RestartCount, restoreDownloadRestarts, terminationMessage(t))
+		case s.State.Running != nil && (!gated || p.Annotations[n.RestoreDownloadAnnotation] == n.RestoreDownloadAllowed):
+			ms.State = hazelcastv1alpha1.RestoreDownloadInProgress
+		}
+	}
+	return ms
+}
+
+func terminationMessage(t *corev1.ContainerStateTerminated) string {
+	if t.Message != "" {
+		return t.Message
+	}
+	return fmt.Sprintf("restore agent exited with code %d: %s", t.ExitCode, t.Reason)
+}
+
+func isRestoreDownloadFinished(s hazelcastv1alpha1.RestoreDownloadState) bool {
+	return s == hazelcastv1alpha1.RestoreDownloadSucceeded || s == hazelcastv1alpha1.RestoreDownloadFailed
+}
+
+// failedRestoreDownloads returns the error of the members whose download failed, nil if none of them failed.
+func failedRestoreDownloads(members []hazelcastv1alpha1.RestoreMemberStatus) error {
+	var failed []string
+	for _, m := range members {
+		if m.State == hazelcastv1alpha1.RestoreDownloadFailed && m.Message != restoreDownloadCanceledMessage {
+			failed = append(failed, m.PodName)
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return fmt.Errorf("download of the backup failed on members %s, the downloads of the other members are canceled",
+		strings.Join(failed, ", "))
+}
+
+// setRestoreMembers reports the downloads of the members in the restore status of the cluster.
+func setRestoreMembers(h *hazelcastv1alpha1.Hazelcast, members []hazelcastv1alpha1.RestoreMemberStatus) {
+	if h.Status.Restore == nil {
+		h.Status.Restore = &hazelcastv1alpha1.RestoreStatus{State: hazelcastv1alpha1.RestoreInProgress}
+	}
+	h.Status.Restore.Members = members
+}
+
+// reconcileRestoreZones sets the zone of the node of each scheduled member on its pod for the zone affinity of the restore.
+// It returns the zones of the backup which have fewer restored members than the backup had. It is empty until all the members
+// are scheduled or if the backup is restored from bucketURI as the zones of the backup are not known then.
+func (r *HazelcastReconciler) reconcileRestoreZones(ctx context.Context, h *hazelcastv1alpha1.Hazelcast, logger logr.Logger) (string, error) {
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, client.InNamespace(h.Namespace), client.MatchingLabels(labels(h))); err != nil {
+		return "", err
+	}
+	nodes := make(map[string]string)
+	restored := make(map[string]int)
+	var scheduled int32
+	for i := range pods.Items {
+		p := &pods.Items[i]
+		if p.Spec.NodeName == "" {
+			continue
+		}
+		zone, err := nodeZone(ctx, r.Client, p.Spec.NodeName, nodes)
+		if err != nil {
+			return "", err
+		}
+		scheduled++
+		restored[zone]++
+		if _, ok := p.Annotations[n.RestoreZoneAnnotation]; ok {
+			continue
+		}
+		if zone == "" {
+			zone = n.RestoreZoneUnknown
+		}
+		patch := client.MergeFrom(p.DeepCopy())
+		if p.Annotations == nil {
+			p.Annotations = make(map[string]string)
+		}
+		p.Annotations[n.RestoreZoneAnnotation] = zone
+		if err := r.Patch(ctx, p, patch); err != nil {
+			return "", err
+		}
+		logger.Info("Set the zone of the restore agent", "pod", p.Name, "zone", zone)
+	}
+
+	if scheduled < *h.Spec.ClusterSize {
+		return "", nil
+	}
+	hbName, err := r.restoreHotBackupName(ctx, h)
+	if err != nil || hbName == "" {
+		return "", err
+	}
+	hb := &hazelcastv1alpha1.HotBackup{}
+	if err := r.Get(ctx, types.NamespacedName{Name: hbName, Namespace: h.Namespace}, hb); err != nil {
+		return "", err
+	}
+	backup := make(map[string]int)
+	for _, m := range hb.Status.Members {
+		backup[m.Zone]++
+	}
+	return zoneAffinityWarning(backup, restored), nil
+}
+
+// zoneAffinityWarning returns the zones of the backup which have fewer restored members than members in the backup.
+func zoneAffinityWarning(backup, restored map[string]int) string {
+	if backup[""] > 0 {
+		return fmt.Sprintf("zones of %d members of the backup are not known, they are restored regardless of the zone", backup[""])
+	}
+	zones := make([]string, 0, len(backup))
+	for z := range backup {
+		zones = append(zones, z)
+	}
+	sort.Strings(zones)
+	var unsatisfied []string
+	for _, z := range zones {
+		if restored[z] < backup[z] {
+			unsatisfied = append(unsatisfied, fmt.Sprintf("%s has %d of %d members", z, restored[z], backup[z]))
+		}
+	}
+	if len(unsatisfied) == 0 {
+		return ""
+	}
+	return "zone topology of the cluster cannot satisfy the backup: " + strings.Join(unsatisfied, ", ")
+}
+
+// memberZones returns the zones of the nodes the members of the cluster run on by the IP of their pods.
+func memberZones(ctx context.Context, c client.Client, h *hazelcastv1alpha1.Hazelcast) (map[string]string, error) {
+	pods := &corev1.PodList{}
+	if err := c.List(ctx, pods, client.InNamespace(h.Namespace), client.MatchingLabels(labels(h))); err != nil {
+		return nil, err
+	}
+	nodes := make(map[string]string)
+	zones := make(map[string]string, len(pods.Items))
+	for _, p := range pods.Items {
+		if p.Status.PodIP == "" || p.Spec.NodeName == "" {
+			continue
+		}
+		zone, err := nodeZone(ctx, c, p.Spec.NodeName, nodes)
+		if err != nil {
+			return nil, err
+		}
+		zones[p.Status.PodIP] = zone
+	}
+	return zones, nil
+}
+
+// memberZone returns the zone of the member with the given address, empty if it is not known.
+func memberZone(zones map[string]string, address string) string {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return ""
+	}
+	return zones[host]
+}
+
+// nodeZone returns the zone of the node, the zones of the nodes already read are cached in nodes.
+func nodeZone(ctx context.Context, c client.Client, name string, nodes map[string]string) (string, error) {
+	if zone, ok := nodes[name]; ok {
+		return zone, nil
+	}
+	node := &corev1.Node{}
+	if err := c.Get(ctx, types.NamespacedName{Name: name}, node); err != nil {
+		return "", err
+	}
+	nodes[name] = node.Labels[corev1.LabelTopologyZone]
+	return nodes[name], nil
+}
+
+// reconcileRestoreExcludeStructures returns the warning about the structures excluded from the restore which are not maps
+// with persistence enabled in the cluster, empty if all of them are known.
+func (r *HazelcastReconciler) reconcileRestoreExcludeStructures(ctx context.Context, h *hazelcastv1alpha1.Hazelcast) (string, error) {
+	mapList := &hazelcastv1alpha1.MapList{}
+	if err := r.List(ctx, mapList, client.InNamespace(h.Namespace), client.MatchingFields{"hazelcastResourceName": h.Name}); err != nil {
+		return "", err
+	}
+	unknown := unknownExcludedStructures(h.Spec.Persistence.Restore.ExcludeStructures, mapList.Items)
+	if len(unknown) == 0 {
+		return "", nil
+	}
+	return fmt.Sprintf("structures %s are not maps with persistence enabled in the cluster", strings.Join(unknown, ", ")), nil
+}
+
+// unknownExcludedStructures returns the excluded names which are not maps with persistence enabled.
+func unknownExcludedStructures(excluded []string, maps []hazelcastv1alpha1.Map) []string {
+	persisted := make(map[string]bool, len(maps))
+	for i := range maps {
+		if maps[i].Spec.PersistenceEnabled {
+			persisted[maps[i].MapName()] = true
+		}
+	}
+	var unknown []string
+	for _, s := range excluded {
+		if !persisted[s] {
+			unknown = append(unknown, s)
+		}
+	}
+	return unknown
+}
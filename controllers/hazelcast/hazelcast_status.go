@@ -142,10 +142,26 @@ func update(ctx context.Context, c client.Client, h *hazelcastv1alpha1.Hazelcast
 		}
 	}
 	if rs := options.restoreState.RestoreState(); h.Spec.Persistence.IsEnabled() && rs != hazelcastv1alpha1.RestoreUnknown {
+		loaded, total := options.restoreState.LoadedMembers()
+		loadedPartitions, totalPartitions := options.restoreState.LoadedPartitions(options.readyMembers)
+		var members []hazelcastv1alpha1.RestoreMemberStatus
+		var zoneWarning, excludeWarning string
+		if h.Status.Restore != nil {
+			members = h.Status.Restore.Members
+			zoneWarning = h.Status.Restore.ZoneAffinityWarning
+			excludeWarning = h.Status.Restore.ExcludeStructuresWarning
+		}
 		h.Status.Restore = &hazelcastv1alpha1.RestoreStatus{
-			State:                   options.restoreState.RestoreState(),
-			RemainingDataLoadTime:   options.restoreState.RemainingDataLoadTimeSec(),
-			RemainingValidationTime: options.restoreState.RemainingValidationTimeSec(),
+			State:                    options.restoreState.RestoreState(),
+			RemainingDataLoadTime:    options.restoreState.RemainingDataLoadTimeSec(),
+			RemainingValidationTime:  options.restoreState.RemainingValidationTimeSec(),
+			LoadedMembers:            loaded,
+			TotalMembers:             total,
+			LoadedPartitions:         loadedPartitions,
+			TotalPartitions:          totalPartitions,
+			Members:                  members,
+			ZoneAffinityWarning:      zoneWarning,
+			ExcludeStructuresWarning: excludeWarning,
 		}
 	}
 	if err := c.Status().Update(ctx, h); err != nil {
@@ -0,0 +1,73 @@
+package hazelcast
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	hazelcastv1alpha1 "github.com/hazelcast/hazelcast-platform-operator/api/v1alpha1"
+	n "github.com/hazelcast/hazelcast-platform-operator/internal/naming"
+)
+
+// errRunCanceled ends the run canceled by the cancel field or the annotation
+var errRunCanceled = errors.New("backup run was canceled")
+
+// registerRun makes the run of the backup cancelable by the annotation until the returned function is called.
+func (r *HotBackupReconciler) registerRun(name types.NamespacedName, cancel context.CancelFunc) func() {
+	r.runs.Store(name, cancel)
+	return func() { r.runs.Delete(name) }
+}
+
+// cancelCurrentRun cancels the run of the HotBackup in progress and removes the annotation requesting it.
+// The schedule of the HotBackup is kept, the next runs start as usual.
+func (r *HotBackupReconciler) cancelCurrentRun(ctx context.Context, hb *hazelcastv1alpha1.HotBackup, logger logr.Logger) error {
+	name := types.NamespacedName{Name: hb.Name, Namespace: hb.Namespace}
+	if cancel, ok := r.runs.Load(name); ok {
+		logger.Info("Canceling the current run", "runID", hb.Status.RunID)
+		cancel.(context.CancelFunc)()
+		r.recorder.AnnotatedEventf(hb, runAnnotations(hb.Status.RunID), corev1.EventTypeNormal, "RunCanceled",
+			"Canceled backup run %s by the %s annotation", hb.Status.RunID, n.CancelCurrentAnnotation)
+	} else {
+		logger.Info("No run in progress to cancel")
+		r.recorder.Eventf(hb, corev1.EventTypeNormal, "RunNotCanceled", "No backup run is in progress to cancel")
+	}
+
+	patch := client.MergeFrom(hb.DeepCopy())
+	delete(hb.Annotations, n.CancelCurrentAnnotation)
+	return r.Patch(ctx, hb, patch)
+}
+
+// runCanceled returns true if the run context was canceled by the annotation, not by its parent.
+func runCanceled(ctx, runCtx context.Context) bool {
+	return runCtx.Err() != nil && ctx.Err() == nil
+}
+
+// cancelBackup cancels the run of the HotBackup in progress requested by its cancel field and suspends its schedule.
+// The canceled run ends in the Canceled state, a HotBackup which is not running and not finished is canceled at once.
+func (r *HotBackupReconciler) cancelBackup(ctx context.Context, hb *hazelcastv1alpha1.HotBackup, logger logr.Logger) (ctrl.Result, error) {
+	name := types.NamespacedName{Name: hb.Name, Namespace: hb.Namespace}
+	if r.removeSchedule(name, logger) {
+		logger.Info("Schedule suspended by the cancel field")
+		if err := r.updateScheduleStatus(ctx, name, false, time.Time{}, time.Time{}); err != nil {
+			logger.Error(err, "Could not update the schedule status")
+		}
+	}
+	if cancel, ok := r.runs.Load(name); ok {
+		logger.Info("Canceling the current run", "runID", hb.Status.RunID)
+		cancel.(context.CancelFunc)()
+		r.recorder.AnnotatedEventf(hb, runAnnotations(hb.Status.RunID), corev1.EventTypeNormal, "RunCanceled",
+			"Canceled backup run %s by the cancel field", hb.Status.RunID)
+		return ctrl.Result{}, nil
+	}
+	if hb.Status.State.IsFinished() || r.checkBackup(name) {
+		return ctrl.Result{}, nil
+	}
+	logger.Info("Canceling the HotBackup before it starts")
+	return r.updateStatus(ctx, name, failedHbStatus(errRunCanceled))
+}
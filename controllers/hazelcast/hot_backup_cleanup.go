@@ -0,0 +1,79 @@
+package hazelcast
+
+import (
+	"context"
+	"time"
+
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	hazelcastv1alpha1 "github.com/hazelcast/hazelcast-platform-operator/api/v1alpha1"
+	hzclient "github.com/hazelcast/hazelcast-platform-operator/controllers/hazelcast/client"
+	"github.com/hazelcast/hazelcast-platform-operator/internal/upload"
+)
+
+// orphanedUploadsCheckInterval is the time between two checks of the agents for orphaned uploads
+const orphanedUploadsCheckInterval = 5 * time.Minute
+
+// cancelOrphanedUploads cancels the uploads still running on the backup agents whose HotBackup
+// is deleted or already finished, e.g. because the operator was restarted in the middle of the backup.
+func (r *HotBackupReconciler) cancelOrphanedUploads(ctx context.Context) {
+	logger := r.Log.WithName("orphaned-uploads")
+
+	hzList := &hazelcastv1alpha1.HazelcastList{}
+	if err := r.List(ctx, hzList); err != nil {
+		logger.Error(err, "Could not list Hazelcast resources")
+		return
+	}
+
+	for _, h := range hzList.Items {
+		// the agents older than n.MinAgentVersion cannot list their uploads
+		if !h.Spec.Persistence.IsExternal() || !agentSupportsFeatures(&h) {
+			continue
+		}
+		hzCtx := upload.WithNamespace(ctx, h.Namespace)
+		for _, address := range memberAddresses(types.NamespacedName{Name: h.Name, Namespace: h.Namespace}) {
+			uploads, err := upload.ListInProgress(hzCtx, address)
+			if err != nil {
+				logger.Error(err, "Could not list uploads of the backup agent", "address", address)
+				continue
+			}
+			for _, u := range uploads {
+				name := types.NamespacedName{Name: u.HotBackupName(), Namespace: h.Namespace}
+				if !r.isUploadOrphaned(ctx, name) {
+					continue
+				}
+				logger.Info("Canceling orphaned upload", "hotBackup", name, "address", address)
+				if err := u.Cancel(hzCtx); err != nil {
+					logger.Error(err, "Could not cancel orphaned upload", "hotBackup", name, "address", address)
+				}
+			}
+		}
+	}
+}
+
+func (r *HotBackupReconciler) isUploadOrphaned(ctx context.Context, name types.NamespacedName) bool {
+	// uploads of agents not reporting the HotBackup cannot be matched
+	if name.Name == "" || r.checkBackup(name) {
+		return false
+	}
+	hb := &hazelcastv1alpha1.HotBackup{}
+	if err := r.Get(ctx, name, hb); err != nil {
+		return apiErrors.IsNotFound(err)
+	}
+	return hb.GetDeletionTimestamp() != nil || hb.Status.State.IsFinished()
+}
+
+func memberAddresses(name types.NamespacedName) []string {
+	c, ok := hzclient.GetClient(name)
+	if !ok {
+		return nil
+	}
+	c.Lock()
+	defer c.Unlock()
+	addresses := make([]string, 0, len(c.Status.MemberMap))
+	for _, m := range c.Status.MemberMap {
+		addresses = append(addresses, m.Address)
+	}
+	return addresses
+}
@@ -0,0 +1,119 @@
+package hazelcast
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/attribute"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	hazelcastv1alpha1 "github.com/hazelcast/hazelcast-platform-operator/api/v1alpha1"
+	"github.com/hazelcast/hazelcast-platform-operator/internal/rest"
+	"github.com/hazelcast/hazelcast-platform-operator/internal/tracing"
+	"github.com/hazelcast/hazelcast-platform-operator/internal/upload"
+)
+
+// compactBackup merges the delta backup referenced by the HotBackup with the chain of backups it is applied on into a new
+// full backup using the backup agent of a member instead of taking a new backup.
+func (r *HotBackupReconciler) compactBackup(ctx context.Context, hb *hazelcastv1alpha1.HotBackup, hz *hazelcastv1alpha1.Hazelcast, logger logr.Logger) (ctrl.Result, error) {
+	started := r.clock.Now()
+	backupName := types.NamespacedName{Name: hb.Name, Namespace: hb.Namespace}
+	folder := hb.Spec.Compact.BackupFolder
+
+	addresses := memberAddresses(types.NamespacedName{Name: hz.Name, Namespace: hz.Namespace})
+	if len(addresses) == 0 {
+		return r.updateStatus(ctx, backupName, failedHbStatus(fmt.Errorf("no member of Hazelcast %s is available to compact the backup", hz.Name)))
+	}
+
+	logger.Info("Compacting backup chain", "backupFolder", folder, "pruneChain", hb.Spec.Compact.PruneChain)
+	config := &upload.Config{
+		BucketURI:     hb.Spec.BucketURI,
+		HazelcastName: hb.Spec.HazelcastResourceName,
+		SecretName:    hb.Spec.Secret,
+	}
+	progress := func(p int32) {
+		logger.Info("Compacting backup chain", "progress", p)
+		_, err := r.updateStatus(ctx, backupName, hbWithStatus(hazelcastv1alpha1.HotBackupInProgress).
+			withMessage(fmt.Sprintf("Compacting backup %s: %d%%", folder, p)).
+			withCompactionProgress(p))
+		if err != nil {
+			logger.Error(err, "Could not update the progress of the compaction")
+		}
+	}
+	_, span := tracing.Start(ctx, "compact", attribute.String("backup.folder", folder))
+	c, err := upload.Compact(ctx, addresses[0], config, folder, progress)
+	tracing.End(span, err)
+	if err != nil {
+		return r.updateStatus(ctx, backupName, failedHbStatus(fmt.Errorf("compaction of backup %s failed: %w", folder, err)))
+	}
+
+	message := fmt.Sprintf("Backups %s merged into %s", strings.Join(c.MergedFolders, ", "), c.BackupFolder)
+	if hb.Spec.Compact.PruneChain {
+		if m := pruneChain(ctx, hb, addresses[0], c, logger); m != "" {
+			message = m
+		}
+	}
+	result, err := r.updateStatus(ctx, backupName, hbWithStatus(hazelcastv1alpha1.HotBackupSuccess).
+		withMessage(message).
+		withBackupFolder(c.BackupFolder).
+		withCompactionProgress(100).
+		withDuration(r.clock.Since(started)))
+	if err != nil {
+		return result, err
+	}
+	if err := r.updateLastSuccessfulConfiguration(ctx, backupName, logger); err != nil {
+		logger.Error(err, "Could not save the current successful spec as annotation to the custom resource")
+	}
+	return result, nil
+}
+
+// pruneChain deletes the backups merged by the compaction from the bucket, except the ones other delta backups are still applied on.
+// It is best-effort, it returns the message of the failure to show in the status.
+func pruneChain(ctx context.Context, hb *hazelcastv1alpha1.HotBackup, memberAddress string, c *upload.Compaction, logger logr.Logger) string {
+	config := &upload.Config{
+		BucketURI:     hb.Spec.BucketURI,
+		HazelcastName: hb.Spec.HazelcastResourceName,
+		SecretName:    hb.Spec.Secret,
+	}
+	folders, err := upload.ListBackups(ctx, memberAddress, config)
+	if err != nil {
+		logger.Error(err, "Could not list the backups to prune the compacted chain")
+		return fmt.Sprintf("Compacted backups could not be pruned: %v", err)
+	}
+	prunable := prunableFolders(folders, c.MergedFolders)
+	if len(prunable) == 0 {
+		return ""
+	}
+	logger.Info("Deleting the compacted backups", "backupFolders", prunable)
+	if err := upload.DeleteBackups(ctx, memberAddress, config, prunable); err != nil {
+		logger.Error(err, "Could not delete the compacted backups")
+		return fmt.Sprintf("Compacted backups could not be pruned: %v", err)
+	}
+	return ""
+}
+
+// prunableFolders returns the merged backup folders no other backup in the bucket is applied on.
+func prunableFolders(folders []rest.BackupFolder, merged []string) []string {
+	isMerged := make(map[string]bool, len(merged))
+	for _, m := range merged {
+		isMerged[m] = true
+	}
+	keep := make(map[string]bool, len(folders))
+	for _, f := range folders {
+		if !isMerged[f.Name] {
+			keep[f.Name] = true
+		}
+	}
+	keepDeltaBases(folders, keep)
+
+	var prunable []string
+	for _, m := range merged {
+		if !keep[m] {
+			prunable = append(prunable, m)
+		}
+	}
+	return prunable
+}
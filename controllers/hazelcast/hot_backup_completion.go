@@ -0,0 +1,97 @@
+package hazelcast
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hazelcast/hazelcast-go-client"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	hazelcastv1alpha1 "github.com/hazelcast/hazelcast-platform-operator/api/v1alpha1"
+	hzclient "github.com/hazelcast/hazelcast-platform-operator/controllers/hazelcast/client"
+)
+
+// completionTimeout is the time the completion record of a finished backup is written to the cluster within
+const completionTimeout = 30 * time.Second
+
+// publishCompletion writes the record of the finished backup to the completion map and topic of the HotBackup in the background
+// through the client connection of the operator to the cluster. It is called once for each run.
+// A failure is logged and recorded in an event, the backup is not failed.
+func (r *HotBackupReconciler) publishCompletion(hb *hazelcastv1alpha1.HotBackup) {
+	if hb.Spec.CompletionMap == "" && hb.Spec.CompletionTopic == "" {
+		return
+	}
+	record, err := json.Marshal(auditRecord(hb))
+	if err != nil {
+		r.Log.Error(err, "Could not encode the completion record", "hotBackup", hb.Name)
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+		defer cancel()
+
+		name := types.NamespacedName{Name: hb.Spec.HazelcastResourceName, Namespace: hb.Namespace}
+		if err := writeCompletion(ctx, name, hb.Spec.CompletionMap, hb.Spec.CompletionTopic, hb.Name, string(record)); err != nil {
+			r.Log.Error(err, "Could not publish the completion record", "hotBackup", hb.Name, "runID", hb.Status.RunID)
+			r.recorder.AnnotatedEventf(hb, runAnnotations(hb.Status.RunID), corev1.EventTypeWarning, "CompletionNotPublished",
+				"Could not publish the completion record of backup run %s: %v", hb.Status.RunID, err)
+		}
+	}()
+}
+
+// completionStore is the part of the cluster the completion records are written to.
+type completionStore interface {
+	Set(ctx context.Context, mapName, key, value string) error
+	Publish(ctx context.Context, topicName, message string) error
+}
+
+// newCompletionStore returns the store of the cluster the operator is connected to
+var newCompletionStore = func(name types.NamespacedName) (completionStore, error) {
+	c, ok := hzclient.GetClient(name)
+	if !ok || c.Client == nil {
+		return nil, fmt.Errorf("the operator is not connected to Hazelcast %s", name.Name)
+	}
+	return clusterCompletionStore{c.Client}, nil
+}
+
+type clusterCompletionStore struct {
+	client *hazelcast.Client
+}
+
+func (s clusterCompletionStore) Set(ctx context.Context, mapName, key, value string) error {
+	m, err := s.client.GetMap(ctx, mapName)
+	if err != nil {
+		return err
+	}
+	return m.Set(ctx, key, value)
+}
+
+func (s clusterCompletionStore) Publish(ctx context.Context, topicName, message string) error {
+	t, err := s.client.GetTopic(ctx, topicName)
+	if err != nil {
+		return err
+	}
+	return t.Publish(ctx, message)
+}
+
+// writeCompletion puts the record into the map under the key and publishes it to the topic of the cluster, the empty names are skipped.
+func writeCompletion(ctx context.Context, name types.NamespacedName, mapName, topicName, key, record string) error {
+	s, err := newCompletionStore(name)
+	if err != nil {
+		return err
+	}
+	if mapName != "" {
+		if err := s.Set(ctx, mapName, key, record); err != nil {
+			return fmt.Errorf("could not put the record into map %s: %w", mapName, err)
+		}
+	}
+	if topicName != "" {
+		if err := s.Publish(ctx, topicName, record); err != nil {
+			return fmt.Errorf("could not publish the record to topic %s: %w", topicName, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package hazelcast
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	hazelcastv1alpha1 "github.com/hazelcast/hazelcast-platform-operator/api/v1alpha1"
+	n "github.com/hazelcast/hazelcast-platform-operator/internal/naming"
+)
+
+var errConflictingSchedule = errors.New("another scheduled HotBackup already backs up the Hazelcast cluster")
+
+// checkConflictingSchedule returns an error if an older scheduled HotBackup in the namespace backs up the same Hazelcast cluster,
+// so the overlapping runs of the two schedules do not contend for the members. The oldest HotBackup of the cluster keeps its schedule.
+// The check is skipped for the HotBackups with the hazelcast.com/allow-concurrent-schedules annotation set to true.
+func (r *HotBackupReconciler) checkConflictingSchedule(ctx context.Context, hb *hazelcastv1alpha1.HotBackup) error {
+	if !takesScheduledBackups(hb) || hb.Annotations[n.AllowConcurrentSchedulesAnnotation] == n.LabelValueTrue {
+		return nil
+	}
+	list := &hazelcastv1alpha1.HotBackupList{}
+	if err := r.List(ctx, list, client.InNamespace(hb.Namespace)); err != nil {
+		return err
+	}
+	for i := range list.Items {
+		other := &list.Items[i]
+		if other.Name == hb.Name || other.Spec.HazelcastResourceName != hb.Spec.HazelcastResourceName ||
+			!takesScheduledBackups(other) || other.GetDeletionTimestamp() != nil || !olderThan(other, hb) {
+			continue
+		}
+		return fmt.Errorf("%w: HotBackup %s backs up Hazelcast %s on schedule %q, set the %s annotation to true to allow both",
+			errConflictingSchedule, other.Name, hb.Spec.HazelcastResourceName, other.Spec.Schedule, n.AllowConcurrentSchedulesAnnotation)
+	}
+	return nil
+}
+
+// takesScheduledBackups returns true if the HotBackup takes new backups on a schedule, the verifications and compactions
+// of the uploaded backups do not back up the members.
+func takesScheduledBackups(hb *hazelcastv1alpha1.HotBackup) bool {
+	return hb.Spec.Schedule != "" && hb.Spec.Verify == nil && hb.Spec.Compact == nil
+}
+
+// olderThan orders the HotBackups by their creation, the names break the ties.
+func olderThan(a, b *hazelcastv1alpha1.HotBackup) bool {
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
+	}
+	return a.Name < b.Name
+}
@@ -5,42 +5,123 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"net"
+	"path"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/go-logr/logr"
+	"github.com/google/uuid"
 	"github.com/robfig/cron/v3"
-	"golang.org/x/sync/errgroup"
+	"go.opentelemetry.io/otel/attribute"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	hazelcastv1alpha1 "github.com/hazelcast/hazelcast-platform-operator/api/v1alpha1"
+	"github.com/hazelcast/hazelcast-platform-operator/controllers/hazelcast/validation"
+	"github.com/hazelcast/hazelcast-platform-operator/internal/audit"
 	"github.com/hazelcast/hazelcast-platform-operator/internal/backup"
+	"github.com/hazelcast/hazelcast-platform-operator/internal/diagnostics"
 	n "github.com/hazelcast/hazelcast-platform-operator/internal/naming"
+	"github.com/hazelcast/hazelcast-platform-operator/internal/rest"
+	"github.com/hazelcast/hazelcast-platform-operator/internal/tracing"
 	"github.com/hazelcast/hazelcast-platform-operator/internal/upload"
 	"github.com/hazelcast/hazelcast-platform-operator/internal/util"
 )
 
+// backupDisabledRequeueInterval is the time after the skipped HotBackup of a cluster with disabled backups is checked again
+const backupDisabledRequeueInterval = time.Minute
+
+// pendingRequeueInterval is the time after a pending HotBackup waiting for the cluster is checked again
+const pendingRequeueInterval = 10 * time.Second
+
+// errPendingTimeout is returned when a HotBackup does not start within its pending timeout
+var errPendingTimeout = errors.New("PendingTimeout")
+
+// defaultClusterQueueTimeout is the time a run waits for the running backup of its cluster if the HotBackup has no pending timeout
+const defaultClusterQueueTimeout = time.Hour
+
+// errScheduleNeverFires is returned for a schedule which is valid but has no next run time
+var errScheduleNeverFires = errors.New("schedule has no next run time")
+
 type HotBackupReconciler struct {
 	client.Client
 	Log       logr.Logger
 	scheduled sync.Map
-	cron      *cron.Cron
+	cron      scheduler
+	parser    cron.Parser
+	// clock is the time source of the schedules
+	clock clock.Clock
+
+	maxConcurrentReconciles int
+	recorder                record.EventRecorder
+
+	// disableExternalBackups keeps every backup on the members, nothing is uploaded to the buckets
+	disableExternalBackups bool
+	// maintenanceMode skips the runs of all schedules, the one-off backups are still started
+	maintenanceMode bool
 
-	backup map[types.NamespacedName]struct{}
+	// backupMu guards backup which is accessed by the reconciles, the started backups and the cron jobs
+	backupMu sync.Mutex
+	backup   map[types.NamespacedName]struct{}
+	// clusters are the backups running on each Hazelcast cluster, guarded by backupMu
+	clusters map[types.NamespacedName]*clusterBackup
+	// runs are the cancel functions of the runs in progress by the HotBackup
+	runs sync.Map
+	// finishedMu guards finished, the ID of the last run of each HotBackup whose end was reported
+	finishedMu sync.Mutex
+	finished   map[types.NamespacedName]string
+
+	// logs are the recent log lines of each HotBackup for the diagnostic bundles
+	logs *diagnostics.Logs
+
+	// hazelcastFetchTimeout is the time the transient errors of fetching the Hazelcast CR are retried within
+	// when a backup starts, they are not retried if it is zero
+	hazelcastFetchTimeout time.Duration
 }
 
-func NewHotBackupReconciler(c client.Client, log logr.Logger) *HotBackupReconciler {
+func NewHotBackupReconciler(c client.Client, log logr.Logger, p cron.Parser, maxConcurrentReconciles int, disableExternalBackups, maintenanceMode bool, hazelcastFetchTimeout time.Duration) *HotBackupReconciler {
 	return &HotBackupReconciler{
-		Client: c,
-		Log:    log,
-		cron:   cron.New(),
-		backup: make(map[types.NamespacedName]struct{}),
+		Client:                  c,
+		Log:                     log,
+		parser:                  p,
+		maxConcurrentReconciles: maxConcurrentReconciles,
+		disableExternalBackups:  disableExternalBackups,
+		maintenanceMode:         maintenanceMode,
+		cron:                    cron.New(cron.WithParser(p)),
+		clock:                   clock.RealClock{},
+		backup:                  make(map[types.NamespacedName]struct{}),
+		logs:                    diagnostics.NewLogs(diagnosticLogLines),
+		hazelcastFetchTimeout:   hazelcastFetchTimeout,
+	}
+}
+
+// NewScheduleParser returns the parser of HotBackup schedules. Standard five field cron expressions and
+// descriptors like @daily or @every 6h are always accepted, withSeconds adds an optional leading seconds field.
+func NewScheduleParser(withSeconds bool) cron.Parser {
+	options := cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor
+	if withSeconds {
+		options |= cron.SecondOptional
 	}
+	return cron.NewParser(options)
 }
 
 // Openshift related permissions
@@ -51,15 +132,23 @@ func NewHotBackupReconciler(c client.Client, log logr.Logger) *HotBackupReconcil
 //+kubebuilder:rbac:groups=hazelcast.com,resources=hotbackups/finalizers,verbs=update,namespace=system
 // ClusterRole related to Reconcile()
 //+kubebuilder:rbac:groups="rbac.authorization.k8s.io",resources=clusterroles;clusterrolebindings,verbs=get;list;watch;create;update;patch;delete
+// ClusterRole related to the agents reached through the API server proxy, only used with the api-server-proxy agent addressing.
+// The proxied calls are authorized by their HTTP method: polling and listing need get, starting the uploads create,
+// storing the latest pointer, the manifest and the diagnostics update, canceling and purging the uploads delete.
+//+kubebuilder:rbac:groups="",resources=pods/proxy,verbs=get;create;update;delete
 
 func (r *HotBackupReconciler) Reconcile(ctx context.Context, req reconcile.Request) (result reconcile.Result, err error) {
-	logger := r.Log.WithValues("hazelcast-hot-backup", req.NamespacedName)
+	logger := r.logs.Logger(req.NamespacedName, r.Log.WithValues("hazelcast-hot-backup", req.NamespacedName))
+	defer func(start time.Time) { observeHotBackupReconcile(r.clock.Since(start), result, err) }(r.clock.Now())
+	// the Hazelcast resource of the HotBackup is in the same namespace
+	ctx = upload.WithNamespace(ctx, req.Namespace)
 
 	hb := &hazelcastv1alpha1.HotBackup{}
 	err = r.Client.Get(ctx, req.NamespacedName, hb)
 	if err != nil {
 		if apiErrors.IsNotFound(err) {
 			logger.Info("HotBackup resource not found. Ignoring since object must be deleted")
+			r.logs.Forget(req.NamespacedName)
 			return result, nil
 		}
 		logger.Error(err, "Failed to get HotBackup")
@@ -80,14 +169,32 @@ func (r *HotBackupReconciler) Reconcile(ctx context.Context, req reconcile.Reque
 		logger.V(util.DebugLevel).Info("Finalizer's pre-delete function executed successfully and the finalizer removed from custom resource", "Name:", n.Finalizer)
 		return
 	}
+	// the HotBackups are all reconciled when the operator starts, the gauge starts from the last successful backups
+	setHotBackupLastSuccess(hb)
+
+	if _, ok := hb.Annotations[n.CancelCurrentAnnotation]; ok {
+		return result, r.cancelCurrentRun(ctx, hb, logger)
+	}
+	if hb.Spec.Cancel {
+		return r.cancelBackup(ctx, hb, logger)
+	}
 
-	if hb.Status.State.IsRunning() || r.checkBackup(req.NamespacedName) {
+	if hb.Status.State == hazelcastv1alpha1.HotBackupPending && !r.checkBackup(req.NamespacedName) {
+		// the backup was not started yet, e.g. it waits for the cluster or the operator was restarted
+		if err := pendingTimeoutError(hb, r.clock.Now()); err != nil {
+			r.recorder.Event(hb, corev1.EventTypeWarning, "PendingTimeout", err.Error())
+			return r.updateStatus(ctx, req.NamespacedName, failedHbStatus(err))
+		}
+	} else if hb.Status.State.IsRunning() || r.checkBackup(req.NamespacedName) {
 		logger.Info("HotBackup is already running.",
 			"name", hb.Name, "namespace", hb.Namespace, "state", hb.Status.State)
 		return
 	}
 
-	if hb.Status.State.IsFinished() {
+	// the schedules live in memory only, they are registered again after the operator restarts
+	unregistered := hb.Spec.Schedule != "" && !r.isScheduled(req.NamespacedName)
+
+	if hb.Status.State.IsFinished() && !unregistered {
 		logger.Info("HotBackup already finished.",
 			"name", hb.Name, "namespace", hb.Namespace, "state", hb.Status.State)
 		return
@@ -97,11 +204,15 @@ func (r *HotBackupReconciler) Reconcile(ctx context.Context, req reconcile.Reque
 	if err != nil {
 		return r.updateStatus(ctx, req.NamespacedName, failedHbStatus(fmt.Errorf("error marshaling Hot Backup as JSON: %w", err)))
 	}
-	if s, ok := hb.ObjectMeta.Annotations[n.LastSuccessfulSpecAnnotation]; ok && s == string(hs) {
+	if s, ok := hb.ObjectMeta.Annotations[n.LastSuccessfulSpecAnnotation]; ok && s == string(hs) && !unregistered {
 		logger.Info("HotBackup was already applied.", "name", hb.Name, "namespace", hb.Namespace)
 		return
 	}
 
+	if err := r.checkDependencyCycle(ctx, hb); err != nil {
+		return r.updateStatus(ctx, req.NamespacedName, failedHbStatus(err))
+	}
+
 	hazelcastName := types.NamespacedName{Namespace: req.Namespace, Name: hb.Spec.HazelcastResourceName}
 
 	h := &hazelcastv1alpha1.Hazelcast{}
@@ -110,27 +221,115 @@ func (r *HotBackupReconciler) Reconcile(ctx context.Context, req reconcile.Reque
 		return r.updateStatus(ctx, req.NamespacedName, failedHbStatus(fmt.Errorf("could not trigger Hot Backup: Hazelcast resource not found: %w", err)))
 	}
 	if h.Status.Phase != hazelcastv1alpha1.Running {
-		return r.updateStatus(ctx, req.NamespacedName, failedHbStatus(apiErrors.NewServiceUnavailable("Hazelcast CR is not ready")))
+		if hb.Spec.PendingTimeout == nil {
+			return r.updateStatus(ctx, req.NamespacedName, failedHbStatus(apiErrors.NewServiceUnavailable("Hazelcast CR is not ready")))
+		}
+		logger.Info("Waiting for Hazelcast CR to be ready")
+		result, err = r.updateStatus(ctx, req.NamespacedName, hbWithStatus(hazelcastv1alpha1.HotBackupPending).
+			withMessage("Waiting for Hazelcast CR to be ready"))
+		if err != nil {
+			return result, err
+		}
+		return ctrl.Result{RequeueAfter: pendingRequeueInterval}, nil
+	}
+	if hb.Spec.IncludeJetSnapshots && !h.Spec.Persistence.IsJetLosslessRestartEnabled() {
+		return r.updateStatus(ctx, req.NamespacedName, failedHbStatus(
+			fmt.Errorf("cannot include Jet snapshots: persistence.jetLosslessRestart is not enabled for Hazelcast %s", h.Name)))
+	}
+	if hb.Spec.Verify != nil && !h.Spec.Persistence.IsExternal() {
+		return r.updateStatus(ctx, req.NamespacedName, failedHbStatus(
+			fmt.Errorf("cannot verify backups: Hazelcast %s has no backup agent, persistence.backupType is not External", h.Name)))
+	}
+	if hb.Spec.Compact != nil && !h.Spec.Persistence.IsExternal() {
+		return r.updateStatus(ctx, req.NamespacedName, failedHbStatus(
+			fmt.Errorf("cannot compact backups: Hazelcast %s has no backup agent, persistence.backupType is not External", h.Name)))
+	}
+	if hb.Spec.Retention != nil && !h.Spec.Persistence.IsExternal() {
+		return r.updateStatus(ctx, req.NamespacedName, failedHbStatus(
+			fmt.Errorf("cannot prune backups: Hazelcast %s has no backup agent, persistence.backupType is not External", h.Name)))
+	}
+	if features := unsupportedHotBackupFeatures(hb, h); len(features) > 0 && h.Spec.Persistence.IsExternal() {
+		return r.updateStatus(ctx, req.NamespacedName, failedHbStatus(errors.New(agentFeaturesMessage(h, features))))
+	}
+	// scheduled backups check the label before every run
+	if hb.Spec.Schedule == "" && isBackupDisabled(h) {
+		logger.Info("Backups of the Hazelcast cluster are disabled by label, skipping")
+		result, err = r.updateStatus(ctx, req.NamespacedName, skippedHbStatus(h))
+		if err != nil {
+			return result, err
+		}
+		// check again later as removing the label does not trigger the reconciliation
+		return ctrl.Result{RequeueAfter: backupDisabledRequeueInterval}, nil
 	}
 
-	err = r.updateLastSuccessfulConfiguration(ctx, req.NamespacedName, logger)
-	if err != nil {
-		logger.Info("Could not save the current successful spec as annotation to the custom resource")
-		return
+	if err := validation.ValidateHotBackup(hb, r.parser); err != nil {
+		return r.updateStatus(ctx, req.NamespacedName, failedHbStatus(err))
+	}
+
+	if err := r.checkConflictingSchedule(ctx, hb); err != nil {
+		if errors.Is(err, errConflictingSchedule) {
+			r.recorder.Event(hb, corev1.EventTypeWarning, "ConflictingSchedule", err.Error())
+		}
+		return r.updateStatus(ctx, req.NamespacedName, failedHbStatus(err))
+	}
+
+	if r.isExternal(h) && hb.Spec.Secret != "" {
+		if err := r.validateBucketSecret(ctx, hb); err != nil {
+			return r.updateStatus(ctx, req.NamespacedName, failedHbStatus(err))
+		}
 	}
 
 	logger.Info("Ready to start backup")
 	if hb.Spec.Schedule != "" {
+		// the run missed while the operator was down is looked up before the status of the schedule is updated
+		var sched cron.Schedule
+		var missed time.Time
+		if unregistered {
+			if sched, err = r.parser.Parse(hb.Spec.Schedule); err == nil {
+				missed = missedScheduledRun(hb, sched, r.clock.Now())
+			}
+		}
 		logger.Info("Adding backup to schedule")
-		r.scheduleBackup(context.Background(), hb.Spec.Schedule, req.NamespacedName, hazelcastName, logger)
+		if err := r.scheduleBackup(context.Background(), hb.Spec.Schedule, req.NamespacedName, hazelcastName, logger); err != nil {
+			if errors.Is(err, errScheduleNeverFires) {
+				r.recorder.Event(hb, corev1.EventTypeWarning, "ScheduleNeverFires", err.Error())
+			}
+			return r.updateStatus(ctx, req.NamespacedName, failedHbStatus(err))
+		}
+		if !missed.IsZero() {
+			r.startMissedRun(ctx, hb, sched, missed, hazelcastName, logger)
+		}
 	} else {
+		var pending []string
+		pending, err = r.pendingDependencies(ctx, hb, r.clock.Now())
+		if err != nil {
+			return r.updateStatus(ctx, req.NamespacedName, failedHbStatus(err))
+		}
+		if len(pending) > 0 {
+			logger.Info("Waiting for HotBackup dependencies", "pending", pending)
+			result, err = r.updateStatus(ctx, req.NamespacedName, waitingForDependenciesStatus(pending))
+			if err != nil {
+				return result, err
+			}
+			return ctrl.Result{RequeueAfter: dependenciesRequeueInterval}, nil
+		}
+
+		// checking and locking the backup at once, another reconcile may have started it in the meantime
+		if !r.lockBackup(req.NamespacedName) {
+			logger.Info("HotBackup is already running.", "name", hb.Name, "namespace", hb.Namespace)
+			return
+		}
 		result, err = r.updateStatus(ctx, req.NamespacedName, hbWithStatus(hazelcastv1alpha1.HotBackupPending))
 		if err != nil {
+			r.unlockBackup(req.NamespacedName)
 			return result, err
 		}
-		r.removeSchedule(req.NamespacedName, logger)
-		r.lockBackup(req.NamespacedName)
-		go r.startBackup(context.Background(), req.NamespacedName, hazelcastName, logger) //nolint:errcheck
+		if r.removeSchedule(req.NamespacedName, logger) {
+			if err := r.updateScheduleStatus(ctx, req.NamespacedName, false, time.Time{}, time.Time{}); err != nil {
+				logger.Error(err, "Could not update the schedule status")
+			}
+		}
+		go r.startBackup(context.Background(), req.NamespacedName, hazelcastName, appliedTriggerCause(hb), logger) //nolint:errcheck
 	}
 
 	return
@@ -147,9 +346,10 @@ func (r *HotBackupReconciler) updateLastSuccessfulConfiguration(ctx context.Cont
 		if err != nil {
 			return err
 		}
-		if hb.ObjectMeta.Annotations != nil {
-			hb.ObjectMeta.Annotations[n.LastSuccessfulSpecAnnotation] = string(hs)
+		if hb.ObjectMeta.Annotations == nil {
+			hb.ObjectMeta.Annotations = make(map[string]string)
 		}
+		hb.ObjectMeta.Annotations[n.LastSuccessfulSpecAnnotation] = string(hs)
 		return r.Client.Update(ctx, hb)
 	})
 }
@@ -176,6 +376,7 @@ func (r *HotBackupReconciler) executeFinalizer(ctx context.Context, hb *hazelcas
 	}
 	r.unlockBackup(key)
 	r.removeSchedule(key, logger)
+	deleteHotBackupMetrics(key)
 	controllerutil.RemoveFinalizer(hb, n.Finalizer)
 	err := r.Update(ctx, hb)
 	if err != nil {
@@ -184,105 +385,520 @@ func (r *HotBackupReconciler) executeFinalizer(ctx context.Context, hb *hazelcas
 	return nil
 }
 
-func (r *HotBackupReconciler) removeSchedule(key types.NamespacedName, logger logr.Logger) {
-	if jobId, ok := r.scheduled.LoadAndDelete(key); ok {
+// removeSchedule removes the cron job of the HotBackup, it returns false if the HotBackup was not scheduled.
+func (r *HotBackupReconciler) removeSchedule(key types.NamespacedName, logger logr.Logger) bool {
+	jobId, ok := r.scheduled.LoadAndDelete(key)
+	if ok {
 		logger.V(util.DebugLevel).Info("Removing cron Job.", "EntryId", jobId)
 		r.cron.Remove(jobId.(cron.EntryID))
 	}
+	return ok
+}
+
+// memberProgressInterval is the minimum interval between the updates of the uploaded bytes of a member in the status
+const memberProgressInterval = 10 * time.Second
+
+// updateMemberStatus replaces the status of the member with the same UUID in the status of the HotBackup during the run,
+// the other members and the state of the HotBackup are kept. A failed update is only logged.
+func (r *HotBackupReconciler) updateMemberStatus(ctx context.Context, name types.NamespacedName, ms hazelcastv1alpha1.HotBackupMemberStatus, logger logr.Logger) {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		hb := &hazelcastv1alpha1.HotBackup{}
+		if err := r.Get(ctx, name, hb); err != nil {
+			return err
+		}
+		for i := range hb.Status.Members {
+			if hb.Status.Members[i].UUID == ms.UUID {
+				hb.Status.Members[i] = ms
+				return r.Status().Update(ctx, hb)
+			}
+		}
+		hb.Status.Members = append(hb.Status.Members, ms)
+		return r.Status().Update(ctx, hb)
+	})
+	if err != nil {
+		logger.Error(err, "Could not update the status of the member", "member", ms.Address)
+	}
 }
 
 func (r *HotBackupReconciler) updateStatus(ctx context.Context, name types.NamespacedName, options hotBackupOptionsBuilder) (ctrl.Result, error) {
+	hb := &hazelcastv1alpha1.HotBackup{}
+	var finishes bool
 	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
 		// Always fetch the new version of the resource
-		hb := &hazelcastv1alpha1.HotBackup{}
 		if err := r.Get(ctx, name, hb); err != nil {
 			return err
 		}
+		// the requeued updates of a finished run do not report its end again
+		finishes = options.status.IsFinished() &&
+			(!hb.Status.State.IsFinished() || (options.runID != "" && options.runID != hb.Status.RunID))
+		if options.status == hazelcastv1alpha1.HotBackupPending && hb.Status.State != hazelcastv1alpha1.HotBackupPending {
+			now := metav1.NewTime(r.clock.Now())
+			hb.Status.PendingSince = &now
+		}
 		hb.Status.State = options.status
 		hb.Status.Message = options.message
+		hb.Status.Reason = options.reason
+		if options.runID != "" {
+			hb.Status.RunID = options.runID
+			hb.Status.TriggerCause = options.triggerCause
+			hb.Status.TriggerSource = options.triggerSource
+		}
+		if options.status == hazelcastv1alpha1.HotBackupSuccess {
+			hb.Status.CompressionLevel = options.compressionLevel
+			hb.Status.CompressionRatio = options.compressionRatio
+			hb.Status.BackupFolder = options.backupFolder
+			hb.Status.Digest = options.digest
+			hb.Status.DeltaBase = options.deltaBase
+			hb.Status.JetSnapshotsIncluded = options.jetSnapshots
+			hb.Status.LocalOnly = options.localOnly
+			now := metav1.NewTime(r.clock.Now())
+			hb.Status.LastSuccessTime = &now
+			if options.duration > 0 {
+				hb.Status.RecentDurations = appendRecentDuration(hb.Status.RecentDurations, options.duration)
+			}
+			hb.Status.ScheduleWarning = scheduleWarning(r.parser, hb, now.Time)
+		}
+		if options.status == hazelcastv1alpha1.HotBackupSuccess || options.status == hazelcastv1alpha1.HotBackupPartialSuccess {
+			hb.Status.SkippedMembers = skippedMembers(options.members)
+		}
+		if options.status == hazelcastv1alpha1.HotBackupInProgress || options.status == hazelcastv1alpha1.HotBackupSuccess {
+			hb.Status.CompactionProgress = options.compactionProgress
+		}
+		if options.memberCount > 0 {
+			hb.Status.SourceMemberCount = options.memberCount
+		}
+		if options.effectiveConfig != nil {
+			hb.Status.EffectiveConfig = options.effectiveConfig
+		}
+		if options.verification != nil {
+			hb.Status.Verification = options.verification
+		}
+		setFreshnessCondition(hb, r.clock.Now())
+		if options.members != nil {
+			hb.Status.Members = options.members
+		}
 		return r.Status().Update(ctx, hb)
 	})
 
+	if err == nil && options.status == hazelcastv1alpha1.HotBackupSuccess {
+		setHotBackupLastSuccess(hb)
+		if w := hb.Status.ScheduleWarning; w != "" {
+			r.recorder.AnnotatedEventf(hb, runAnnotations(hb.Status.RunID), corev1.EventTypeWarning, "ScheduleTooFrequent", w)
+		}
+	}
+	if err == nil && (options.status == hazelcastv1alpha1.HotBackupSuccess || options.status == hazelcastv1alpha1.HotBackupPartialSuccess) {
+		if skipped := hb.Status.SkippedMembers; len(skipped) > 0 {
+			addHotBackupMembersSkipped(name, len(skipped))
+			addresses := make([]string, len(skipped))
+			for i, m := range skipped {
+				addresses[i] = m.Address
+			}
+			r.recorder.AnnotatedEventf(hb, runAnnotations(hb.Status.RunID), corev1.EventTypeWarning, "MembersSkipped",
+				"Members %s are not covered by the backup, see skippedMembers in the status", strings.Join(addresses, ", "))
+		}
+	}
+	if err == nil && (options.status.IsFinished() || options.status == hazelcastv1alpha1.HotBackupInProgress ||
+		options.status == hazelcastv1alpha1.HotBackupUploading) {
+		if hzErr := r.updateHazelcastBackupStatus(ctx, hb); hzErr != nil {
+			r.Log.Error(hzErr, "Could not update the backup status of the Hazelcast resource", "hotBackup", name)
+		}
+	}
+	if err == nil && finishes && r.reportFinished(name, hb.Status.RunID) {
+		if auditErr := audit.Write(ctx, auditRecord(hb)); auditErr != nil {
+			r.Log.Error(auditErr, "Could not write audit record", "hotBackup", name)
+		}
+		r.notify(hb.DeepCopy())
+		r.publishCompletion(hb.DeepCopy())
+		r.collectDiagnostics(hb.DeepCopy())
+	}
 	if options.status == hazelcastv1alpha1.HotBackupFailure {
 		return ctrl.Result{}, options.err
 	}
 	return ctrl.Result{}, err
 }
 
-func (r *HotBackupReconciler) scheduleBackup(ctx context.Context, schedule string, backupName types.NamespacedName, hazelcastName types.NamespacedName, logger logr.Logger) {
-	entry, err := r.cron.AddFunc(schedule, func() {
-		r.startBackup(ctx, backupName, hazelcastName, logger) //nolint:errcheck
-	})
+// reportFinished returns true if the end of the run of the HotBackup was not reported yet and records it as reported.
+// The runs failing before they get an ID are reported every time they finish.
+func (r *HotBackupReconciler) reportFinished(name types.NamespacedName, runID string) bool {
+	if runID == "" {
+		return true
+	}
+	r.finishedMu.Lock()
+	defer r.finishedMu.Unlock()
+	if r.finished == nil {
+		r.finished = make(map[types.NamespacedName]string)
+	}
+	if r.finished[name] == runID {
+		return false
+	}
+	r.finished[name] = runID
+	return true
+}
+
+// runScheduledBackup starts a scheduled run of the backup.
+func (r *HotBackupReconciler) runScheduledBackup(ctx context.Context, sched cron.Schedule, backupName types.NamespacedName, hazelcastName types.NamespacedName, logger logr.Logger) {
+	now := r.clock.Now()
+	if err := r.updateScheduleStatus(ctx, backupName, true, sched.Next(now), now); err != nil {
+		logger.Error(err, "Could not update the schedule status")
+	}
+	if r.maintenanceMode {
+		logger.Info("Operator is in maintenance mode, skipping scheduled backup")
+		r.updateStatus(ctx, backupName, maintenanceHbStatus()) //nolint:errcheck
+		return
+	}
+	r.startBackup(ctx, backupName, hazelcastName, hazelcastv1alpha1.HotBackupTriggerScheduled, logger) //nolint:errcheck
+}
+
+func (r *HotBackupReconciler) scheduleBackup(ctx context.Context, schedule string, backupName types.NamespacedName, hazelcastName types.NamespacedName, logger logr.Logger) error {
+	sched, err := r.parser.Parse(schedule)
 	if err != nil {
 		logger.Error(err, "Error creating new Schedule Hot Restart.")
+		return err
+	}
+	// a valid schedule may still never match, e.g. on the 30th of February
+	if sched.Next(r.clock.Now()).IsZero() {
+		return fmt.Errorf("%w: %q", errScheduleNeverFires, schedule)
 	}
+	entry := r.cron.Schedule(sched, cron.FuncJob(func() {
+		r.runScheduledBackup(ctx, sched, backupName, hazelcastName, logger)
+	}))
 	if old, loaded := r.scheduled.LoadOrStore(backupName, entry); loaded {
 		r.cron.Remove(old.(cron.EntryID))
 		r.scheduled.Store(backupName, entry)
 	}
 	r.cron.Start()
+	if err := r.updateScheduleStatus(ctx, backupName, true, sched.Next(r.clock.Now()), time.Time{}); err != nil {
+		logger.Error(err, "Could not update the schedule status")
+	}
+	return nil
+}
+
+func (r *HotBackupReconciler) isScheduled(name types.NamespacedName) bool {
+	_, ok := r.scheduled.Load(name)
+	return ok
 }
 
 func (r *HotBackupReconciler) checkBackup(name types.NamespacedName) bool {
+	r.backupMu.Lock()
+	defer r.backupMu.Unlock()
 	_, ok := r.backup[name]
 	return ok
 }
 
-func (r *HotBackupReconciler) lockBackup(name types.NamespacedName) {
+// lockBackup marks the backup as running. It returns false if the backup was already running.
+func (r *HotBackupReconciler) lockBackup(name types.NamespacedName) bool {
+	r.backupMu.Lock()
+	defer r.backupMu.Unlock()
+	if _, ok := r.backup[name]; ok {
+		return false
+	}
 	r.backup[name] = struct{}{}
+	return true
 }
 
 func (r *HotBackupReconciler) unlockBackup(name types.NamespacedName) {
+	r.backupMu.Lock()
+	defer r.backupMu.Unlock()
 	delete(r.backup, name)
 }
 
-func (r *HotBackupReconciler) startBackup(ctx context.Context, backupName types.NamespacedName, hazelcastName types.NamespacedName, logger logr.Logger) (ctrl.Result, error) {
-	logger.Info("Starting backup")
+// clusterBackup is the slot of the backup running on a Hazelcast cluster.
+type clusterBackup struct {
+	slot chan struct{}
+	// holder is the HotBackup whose backup is running
+	holder types.NamespacedName
+}
+
+// acquireCluster waits until no other backup runs on the Hazelcast cluster, so the backups of the cluster started by different
+// HotBackups run one after another. The queued function is called if the backup has to wait, with the HotBackup it waits for.
+// The returned function releases the cluster.
+func (r *HotBackupReconciler) acquireCluster(ctx context.Context, hazelcastName, backupName types.NamespacedName, queued func(holder types.NamespacedName)) (func(), error) {
+	r.backupMu.Lock()
+	if r.clusters == nil {
+		r.clusters = make(map[types.NamespacedName]*clusterBackup)
+	}
+	c, ok := r.clusters[hazelcastName]
+	if !ok {
+		c = &clusterBackup{slot: make(chan struct{}, 1)}
+		r.clusters[hazelcastName] = c
+	}
+	release := func() {
+		r.backupMu.Lock()
+		defer r.backupMu.Unlock()
+		c.holder = types.NamespacedName{}
+		<-c.slot
+	}
+	select {
+	case c.slot <- struct{}{}:
+		c.holder = backupName
+		r.backupMu.Unlock()
+		return release, nil
+	default:
+	}
+	holder := c.holder
+	r.backupMu.Unlock()
+
+	queued(holder)
+	select {
+	case c.slot <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	r.backupMu.Lock()
+	c.holder = backupName
+	r.backupMu.Unlock()
+	return release, nil
+}
+
+func (r *HotBackupReconciler) startBackup(ctx context.Context, backupName types.NamespacedName, hazelcastName types.NamespacedName, cause hazelcastv1alpha1.HotBackupTriggerCause, logger logr.Logger) (result ctrl.Result, err error) {
+	// the steps of the run are traced as the children of its span
+	ctx, span := tracing.Start(ctx, "HotBackup",
+		attribute.String("hotbackup.name", backupName.Name),
+		attribute.String("hotbackup.namespace", backupName.Namespace),
+		attribute.String("hotbackup.cause", string(cause)))
+	defer func() { tracing.End(span, err) }()
+	// the pods of the members are looked up once for all the agent calls of the run
+	ctx = upload.WithPodCache(upload.WithNamespace(ctx, hazelcastName.Namespace))
+
+	hb := &hazelcastv1alpha1.HotBackup{}
+	if err := r.Get(ctx, backupName, hb); err != nil {
+		return r.updateStatus(ctx, backupName, failedHbStatus(err))
+	}
+	// all the log lines, events and the manifest of the run carry its ID
+	runID := newRunID(hb)
+	source := triggerSource(hb, cause)
+	logger = logger.WithValues("runID", runID)
+	span.SetAttributes(attribute.String("hotbackup.run_id", runID))
+
+	logger.Info("Starting backup", "cause", cause, "source", source)
 	defer logger.Info("Finished backup")
+	started := r.clock.Now()
+
+	// the members are canceled with runCtx by the annotation, the status is still updated with ctx
+	runCtx, cancelRun := context.WithCancel(ctx)
+	defer cancelRun()
+	defer r.registerRun(backupName, cancelRun)()
 
 	// Change state to In Progress
-	_, err := r.updateStatus(ctx, backupName, hbWithStatus(hazelcastv1alpha1.HotBackupInProgress))
+	_, err = r.updateStatus(ctx, backupName, hbWithStatus(hazelcastv1alpha1.HotBackupInProgress).
+		withRunID(runID).withTrigger(cause, source))
 	if err != nil {
 		// setting status failed so this most likely will fail too
 		return r.updateStatus(ctx, backupName, failedHbStatus(err))
 	}
+	r.recorder.AnnotatedEventf(hb, runAnnotations(runID), corev1.EventTypeNormal, "BackupStarted", "Started backup run %s", runID)
 
 	// Get latest version as this may be running in cron
-	hz := &hazelcastv1alpha1.Hazelcast{}
-	if err := r.Get(ctx, hazelcastName, hz); err != nil {
+	hz, err := r.getHazelcast(ctx, hazelcastName, logger)
+	if err != nil {
 		logger.Error(err, "Get latest hazelcast CR failed")
 		return r.updateStatus(ctx, backupName, failedHbStatus(err))
 	}
 
+	if isBackupDisabled(hz) {
+		logger.Info("Backups of the Hazelcast cluster are disabled by label, skipping")
+		return r.updateStatus(ctx, backupName, skippedHbStatus(hz))
+	}
+
+	if err := r.Get(ctx, backupName, hb); err != nil {
+		return r.updateStatus(ctx, backupName, failedHbStatus(err))
+	}
+
+	// scheduled runs are skipped until the dependencies succeed
+	pending, err := r.pendingDependencies(ctx, hb, r.clock.Now())
+	if err != nil {
+		return r.updateStatus(ctx, backupName, failedHbStatus(err))
+	}
+	if len(pending) > 0 {
+		logger.Info("Skipping backup, waiting for HotBackup dependencies", "pending", pending)
+		return r.updateStatus(ctx, backupName, waitingForDependenciesStatus(pending))
+	}
+
+	if hb.Spec.Verify != nil {
+		return r.verifyBackup(ctx, hb, hz, logger)
+	}
+	if hb.Spec.Compact != nil {
+		return r.compactBackup(ctx, hb, hz, logger)
+	}
+
+	// the backups of the cluster started by other HotBackups are waited for, the members take one backup at a time
+	queueTimeout := clusterQueueTimeout(hb)
+	waitCtx, cancelWait := context.WithTimeout(runCtx, queueTimeout)
+	var waitingFor string
+	releaseCluster, err := r.acquireCluster(waitCtx, hazelcastName, backupName, func(holder types.NamespacedName) {
+		logger.Info("Waiting for the running backup of the cluster to finish", "hotBackup", holder.Name)
+		waitingFor = fmt.Sprintf("Waiting for the backup of HotBackup %s of Hazelcast %s to finish", holder.Name, hazelcastName.Name)
+		_, err := r.updateStatus(ctx, backupName, hbWithStatus(hazelcastv1alpha1.HotBackupPending).withMessage(waitingFor))
+		if err != nil {
+			logger.Error(err, "Could not update the status of the queued backup")
+		}
+	})
+	cancelWait()
+	if err != nil {
+		if runCanceled(ctx, runCtx) {
+			err = errRunCanceled
+		} else if errors.Is(err, context.DeadlineExceeded) {
+			err = fmt.Errorf("%w: HotBackup did not start within %s: %s", errPendingTimeout, queueTimeout, waitingFor)
+			r.recorder.Event(hb, corev1.EventTypeWarning, "PendingTimeout", err.Error())
+		}
+		return r.updateStatus(ctx, backupName, failedHbStatus(err))
+	}
+	defer releaseCluster()
+	if waitingFor != "" {
+		if _, err := r.updateStatus(ctx, backupName, hbWithStatus(hazelcastv1alpha1.HotBackupInProgress)); err != nil {
+			return r.updateStatus(ctx, backupName, failedHbStatus(err))
+		}
+	}
+
+	external := r.isExternal(hz)
+	if hz.Spec.Persistence.IsExternal() && !external {
+		logger.Info("External backups are disabled by the operator, the backup is kept on the members only")
+	}
+
+	// fail fast without a wasted local backup if the bucket is failing repeatedly
+	var bucketURI string
+	if external {
+		bucketURI = hb.Spec.BucketURI
+		if err := upload.CheckBucket(bucketURI); err != nil {
+			return r.updateStatus(ctx, backupName, failedHbStatus(err))
+		}
+	}
+
+	var base string
+	if external {
+		base = deltaBase(hb)
+	}
+	// the configuration is read before the backup starts not to waste the backup if it cannot be read
+	var dynamicCfg string
+	if external && hb.Spec.IncludeConfig {
+		if dynamicCfg, err = dynamicConfig(ctx, r.Client, hz); err != nil {
+			return r.updateStatus(ctx, backupName, failedHbStatus(err))
+		}
+	}
+
 	b, err := backup.NewClusterBackup(hz)
 	if err != nil {
 		return r.updateStatus(ctx, backupName, failedHbStatus(err))
 	}
+	members := b.Members()
+
+	// unreachable agents fail the backup before the local backup is wasted
+	if external {
+		addresses := backupAddresses(members)
+		if err := upload.CheckAgents(ctx, addresses); err != nil {
+			return r.updateStatus(ctx, backupName, failedHbStatus(err))
+		}
+		if hb.Spec.BackupPathOverride != "" {
+			if err := upload.CheckPath(ctx, addresses, hb.Spec.BackupPathOverride); err != nil {
+				return r.updateStatus(ctx, backupName, failedHbStatus(err))
+			}
+		}
+	}
+
+	// the zones are stored in the manifest for the zone affinity of the restore, the backup does not need them
+	zones, err := memberZones(ctx, r.Client, hz)
+	if err != nil {
+		logger.Error(err, "Could not read the zones of the members")
+	}
+
+	// each member monitor updates only its own status
+	memberStatuses := make([]hazelcastv1alpha1.HotBackupMemberStatus, len(members))
+	for i, m := range members {
+		memberStatuses[i] = hazelcastv1alpha1.HotBackupMemberStatus{
+			Address: m.Address,
+			UUID:    m.UUID.String(),
+			Zone:    memberZone(zones, m.Address),
+			Phase:   hazelcastv1alpha1.HotBackupMemberInProgress,
+		}
+	}
+
+	_, err = r.updateStatus(ctx, backupName, hbWithStatus(hazelcastv1alpha1.HotBackupInProgress).
+		withSourceMemberCount(int32(len(members))).
+		withEffectiveConfig(r.effectiveConfig(hb, hz, external)).
+		withMembers(memberStatuses))
+	if err != nil {
+		return r.updateStatus(ctx, backupName, failedHbStatus(err))
+	}
 
-	if err := b.Start(ctx); err != nil {
+	// the members are checked while they flush the local backup, a degraded cluster interrupts it
+	flush := monitorFlush(ctx, b, len(members), flushHealthCheckInterval(hb), logger)
+	defer flush.stop()
+	err = b.Start(runCtx)
+	// Start activates the cluster again with runCtx, the cluster stays passive if the run is canceled during the flush
+	if err != nil || runCtx.Err() != nil {
+		if err := b.Activate(ctx); err != nil {
+			logger.Error(err, "Could not activate the cluster")
+		}
+	}
+	if err != nil {
+		if healthErr := flush.failure(); healthErr != nil {
+			err = healthErr
+		} else if runCanceled(ctx, runCtx) {
+			err = errRunCanceled
+		}
 		return r.updateStatus(ctx, backupName, failedHbStatus(err))
 	}
+	if hb.Spec.Retention != nil && hz.Spec.Persistence.IsExternal() {
+		markLocalBackups(ctx, hb, hz, backupAddresses(members), logger)
+	}
+
+	var statsMu sync.Mutex
+	var bucketFailed bool
+	// uploads of this run to purge if the backup fails
+	var sinks []upload.BackupSink
+	results := newUploadResults(len(members))
 
-	// for each member monitor and upload backup if needed
-	g, groupCtx := errgroup.WithContext(ctx)
-	for _, m := range b.Members() {
+	// the bytes uploaded by all the members are charged to the size budget
+	var maxBackupSize int64
+	if hb.Spec.MaxBackupSize != nil {
+		maxBackupSize = hb.Spec.MaxBackupSize.Value()
+	}
+	budget := upload.NewSizeBudget(maxBackupSize)
+
+	uploadSlots := memberUploadSlots(hb, hz)
+	sequential := hb.Spec.UploadMode == hazelcastv1alpha1.UploadSequential
+
+	// the backup is uploading once the local backups of all the members finished,
+	// the members do not wait for each other but start their uploads right after their own local backup
+	uploading := newUploadingGate(len(members), func() {
+		r.reportUploading(ctx, hb, runID, len(members), bucketURI, logger)
+	})
+
+	// for each member monitor and upload backup if needed, the failed uploads are abandoned within the tolerance
+	g, groupCtx := newMemberGroup(runCtx, hb.Spec.MaxFailedUploads)
+	for i, m := range members {
 		m := m
-		g.Go(func() error {
+		ms := &memberStatuses[i]
+		g.Go(func() (err error) {
 			logger := logger.WithValues("uuid", m.UUID)
 
 			logger.Info("Member status monitor started")
 			defer logger.Info("Member status monitor finished")
+			// the final phase of the member is shown as soon as it finishes, not only once the backup finishes
+			defer func() {
+				if err != nil || ms.Abandoned {
+					ms.Phase = hazelcastv1alpha1.HotBackupMemberFailed
+				}
+				r.updateMemberStatus(ctx, backupName, *ms, logger)
+			}()
 
 			logger.Info("Wait for member backup to finish")
-			if err := m.Wait(groupCtx); err != nil {
+			_, flushSpan := tracing.Start(ctx, "flush", attribute.String("member.address", m.Address))
+			waitErr := m.Wait(groupCtx)
+			tracing.End(flushSpan, waitErr)
+			flush.flushed()
+			if waitErr != nil {
+				ms.Phase = hazelcastv1alpha1.HotBackupMemberFailed
 				// cancel cluster backup
 				return b.Cancel(ctx)
 			}
 
 			// skip upload for local backup
-			if !hz.Spec.Persistence.IsExternal() {
+			if !external {
+				ms.Phase = hazelcastv1alpha1.HotBackupMemberBackedUp
 				return nil
 			}
+			uploading.flushed()
 
 			hb := &hazelcastv1alpha1.HotBackup{}
 			if err := r.Get(groupCtx, backupName, hb); err != nil {
@@ -290,48 +906,772 @@ func (r *HotBackupReconciler) startBackup(ctx context.Context, backupName types.
 			}
 
 			logger.Info("Start and wait for member backup upload")
-			u, err := upload.NewUpload(&upload.Config{
-				MemberAddress: m.Address,
-				BucketURI:     hb.Spec.BucketURI,
-				BackupPath:    hz.Spec.Persistence.BaseDir,
-				HazelcastName: hb.Spec.HazelcastResourceName,
-				SecretName:    hb.Spec.Secret,
-			})
-			if err != nil {
-				return err
+			_, uploadSpan := tracing.Start(ctx, "upload", attribute.String("member.address", m.Address))
+			defer func() {
+				uploadSpan.SetAttributes(attribute.Int64("upload.bytes", ms.UploadedBytes),
+					attribute.Int("upload.retries", int(ms.RetryCount)), attribute.Bool("upload.abandoned", ms.Abandoned))
+				tracing.End(uploadSpan, err)
+			}()
+			config := &upload.Config{
+				MemberAddress:    m.Address,
+				BucketURI:        hb.Spec.BucketURI,
+				BackupPath:       backupPath(hb, hz),
+				HazelcastName:    hb.Spec.HazelcastResourceName,
+				HotBackupName:    hb.Name,
+				SecretName:       hb.Spec.Secret,
+				HazelcastVersion: hz.Spec.Version,
+				Compression:      string(hb.Spec.Compression),
+				KeyEncoding:      string(hb.Spec.KeyEncoding),
+				CompressionLevel: hb.Spec.CompressionLevel,
+				VerifyArchive:    hb.Spec.VerifyArchive,
+				Metadata:         hb.Spec.Metadata,
+				DynamicConfig:    dynamicCfg,
+				DeltaBase:        base,
+				Zone:             ms.Zone,
+				RunID:            runID,
+				TriggerCause:     string(cause),
+				TriggerSource:    source,
+				RequestHeaders:   hb.Spec.RequestHeaders,
+				MaxSize:          maxBackupSize,
+				SizeBudget:       budget,
+			}
+			config.PartSize, config.MaxObjectSize = hb.Spec.MultipartSizes()
+			var progressReported time.Time
+			config.Progress = func(uploaded int64) {
+				ms.UploadedBytes = uploaded
+				if r.clock.Since(progressReported) < memberProgressInterval {
+					return
+				}
+				progressReported = r.clock.Now()
+				r.updateMemberStatus(ctx, backupName, *ms, logger)
+			}
+			if hb.Spec.CredentialsRefreshInterval != nil {
+				config.CredentialsRefreshInterval = hb.Spec.CredentialsRefreshInterval.Duration
+			}
+			if t := hb.Spec.AgentConnectTimeout; t != nil {
+				config.ConnectTimeout = t.Duration
+			}
+			if retries := hb.Spec.AgentConnectRetries; retries != nil {
+				// zero retries of the spec disable them, zero of the config is the default
+				config.ConnectRetries = int(*retries)
+				if *retries == 0 {
+					config.ConnectRetries = -1
+				}
+			}
+			config.ObjectACL = objectACL(hb.Spec.ObjectACL)
+			config.ChunkedTransfer = hb.Spec.ChunkedTransfer
+			config.DeleteLocal = sequential
+			if ol := hb.Spec.ObjectLock; ol != nil {
+				config.ObjectLockMode = string(ol.Mode)
+				config.RetainUntil = r.clock.Now().Add(ol.RetentionPeriod.Duration)
+			}
+			uploadFailed := func(err error) {
+				if !errors.Is(err, context.Canceled) {
+					statsMu.Lock()
+					bucketFailed = true
+					statsMu.Unlock()
+				}
+			}
+			// abandonUpload gives up the failed upload of the member without failing the others if the tolerance allows it
+			abandonUpload := func(err error) bool {
+				if errors.Is(err, context.Canceled) || errors.Is(err, upload.ErrSizeBudgetExceeded) || !g.abandon() {
+					return false
+				}
+				logger.Error(err, "Abandoning the failed upload of the member")
+				ms.Abandoned = true
+				ms.Error = err.Error()
+				return true
 			}
 
-			// now start and wait for upload
-			if err := u.Start(groupCtx); err != nil {
-				return err
+			if uploadSlots != nil {
+				select {
+				case uploadSlots <- struct{}{}:
+					defer func() { <-uploadSlots }()
+				case <-groupCtx.Done():
+					return groupCtx.Err()
+				}
 			}
+			ms.Phase = hazelcastv1alpha1.HotBackupMemberUploading
+			r.updateMemberStatus(ctx, backupName, *ms, logger)
+
+			// the replica of the bucket in the failover region is used once the upload to the primary bucket failed
+			configs := []*upload.Config{config}
+			if f := hb.Spec.Failover; f != nil {
+				configs = append(configs, failoverConfig(config, f))
+			}
+			var u upload.BackupSink
+			for i, config := range configs {
+				failover := i < len(configs)-1
+				u, err = upload.NewUpload(groupCtx, config)
+				if err != nil {
+					return err
+				}
+				ms.BucketURI = config.BucketURI
+				ms.FailedOver = i > 0
 
-			if err := u.Wait(groupCtx); err != nil {
+				// now start and wait for upload
+				err = u.Start(groupCtx)
+				ms.RetryCount = u.RetryCount()
+				if retryErr := u.LastRetryError(); retryErr != nil {
+					ms.LastRetryError = retryErr.Error()
+				}
+				if err != nil {
+					if i == 0 {
+						uploadFailed(err)
+					}
+					if failover && !errors.Is(err, context.Canceled) {
+						logger.Error(err, "Upload to the primary bucket failed, failing over", "bucketURI", configs[i+1].BucketURI)
+						continue
+					}
+					if abandonUpload(err) {
+						return nil
+					}
+					return err
+				}
+				statsMu.Lock()
+				sinks = append(sinks, u)
+				statsMu.Unlock()
+
+				err = u.Wait(groupCtx)
+				ms.AgentVersion = u.Status().AgentVersion
+				if err == nil {
+					break
+				}
+				if i == 0 {
+					uploadFailed(err)
+				}
 				if errors.Is(err, context.Canceled) {
 					// notify agent so we can cleanup if needed
 					logger.Info("Cancel upload")
 					return u.Cancel(ctx)
 				}
+				if errors.Is(err, upload.ErrSizeBudgetExceeded) {
+					// delete the partial upload, the other members are canceled by the group
+					logger.Info("Cancel upload exceeding the size budget")
+					if cancelErr := u.Cancel(ctx); cancelErr != nil {
+						logger.Error(cancelErr, "Could not cancel upload")
+					}
+					return err
+				}
+				if failover {
+					// delete the partial upload before uploading to the failover bucket
+					logger.Error(err, "Upload to the primary bucket failed, failing over", "bucketURI", configs[i+1].BucketURI)
+					if cancelErr := u.Cancel(ctx); cancelErr != nil {
+						logger.Error(cancelErr, "Could not cancel upload")
+					}
+					continue
+				}
+				if abandonUpload(err) {
+					// delete the partial upload, the other members go on
+					if cancelErr := u.Cancel(ctx); cancelErr != nil {
+						logger.Error(cancelErr, "Could not cancel upload")
+					}
+					return nil
+				}
 				return err
 			}
 
+			s := u.Status()
+			ms.UploadedBytes = uploadedBytes(s)
+			ms.Phase = hazelcastv1alpha1.HotBackupMemberUploaded
+			results.add(s)
+
 			// member success
 			return nil
 		})
 	}
 
 	logger.Info("Waiting for members")
-	if err := g.Wait(); err != nil {
+	err = g.Wait()
+	if healthErr := flush.failure(); healthErr != nil {
+		err = healthErr
+	} else if runCanceled(ctx, runCtx) {
+		err = errRunCanceled
+	}
+	if bucketURI != "" {
+		if bucketFailed {
+			upload.BucketFailed(bucketURI)
+		} else if err == nil {
+			upload.BucketSucceeded(bucketURI)
+		}
+	}
+	if err != nil {
 		logger.Error(err, "One or more members failed, returning first error")
-		return r.updateStatus(ctx, backupName, failedHbStatus(err))
+		if m := agentVersionMismatch(memberStatuses); m != "" {
+			err = fmt.Errorf("%w, %s", err, m)
+		}
+		defer r.purgeUploads(ctx, hb, hz, sinks, logger)
+		return r.updateStatus(ctx, backupName, failedHbStatus(err).withMembers(memberStatuses))
 	}
 
 	logger.Info("All members finished with no errors")
-	return r.updateStatus(ctx, backupName, hbWithStatus(hazelcastv1alpha1.HotBackupSuccess))
+	// the digest is computed only if every agent reported the checksum of its object
+	// and can store it in the manifest, which the agents older than n.MinAgentVersion cannot
+	var digest string
+	if external && agentSupportsFeatures(hz) && results.hasChecksums() && len(members) > 0 {
+		_, digestSpan := tracing.Start(ctx, "digest")
+		digest, err = storeDigest(ctx, hb, members[0].Address, results.backupFolder, results.checksums, logger)
+		tracing.End(digestSpan, err)
+		if err != nil {
+			defer r.purgeUploads(ctx, hb, hz, sinks, logger)
+			return r.updateStatus(ctx, backupName, failedHbStatus(err).withMembers(memberStatuses))
+		}
+	}
+
+	var message string
+	localOnly := hz.Spec.Persistence.IsExternal() && !external
+	if localOnly {
+		message = "External backups are disabled by the operator, the backup was not uploaded"
+	}
+	// the backup without the abandoned members is not complete, it does not replace the latest one
+	abandoned := abandonedMembers(memberStatuses)
+	if len(abandoned) > 0 {
+		message = fmt.Sprintf("Uploads of members %s were abandoned, the backup %s is incomplete",
+			strings.Join(abandoned, ", "), results.backupFolder)
+	}
+	// neither bucket holds the whole backup if only some of the members failed over
+	failedOver := failedOverMembers(memberStatuses)
+	if len(failedOver) > 0 {
+		message = fmt.Sprintf("Members %s uploaded to the failover bucket %s", strings.Join(failedOver, ", "), hb.Spec.Failover.BucketURI)
+		abandoned = append(abandoned, failedOver...)
+	}
+	if hb.Spec.UpdateLatest && results.backupFolder != "" && len(members) > 0 && len(abandoned) == 0 {
+		if m := updateLatestPointer(ctx, hb, members[0].Address, results.backupFolder, logger); m != "" {
+			message = m
+		}
+	}
+	if external && hb.Spec.Retention != nil && results.backupFolder != "" && len(members) > 0 && len(abandoned) == 0 {
+		if m := pruneBackups(ctx, hb, members[0].Address, results.backupFolder, r.clock.Now(), logger); m != "" {
+			message = m
+		}
+	}
+	if hb.Spec.Retention != nil && hz.Spec.Persistence.IsExternal() && len(members) > 0 {
+		if m := pruneLocalBackups(ctx, hb, hz, backupAddresses(members), r.clock.Now(), logger); m != "" {
+			message = m
+		}
+	}
+	if len(abandoned) > 0 {
+		result, err = r.updateStatus(ctx, backupName, hbWithStatus(hazelcastv1alpha1.HotBackupPartialSuccess).
+			withMessage(message).
+			withDuration(r.clock.Since(started)).
+			withMembers(memberStatuses))
+		// the spec is not marked as applied, the incomplete backup can be triggered again like a failed one
+		return result, err
+	}
+	result, err = r.updateStatus(ctx, backupName, hbWithStatus(hazelcastv1alpha1.HotBackupSuccess).
+		withMessage(message).
+		withCompression(results.compressionLevel, results.originalSize, results.compressedSize).
+		withBackupFolder(results.backupFolder).
+		withDigest(digest).
+		withDeltaBase(base).
+		withLocalOnly(localOnly).
+		withJetSnapshots(hz.Spec.Persistence.IsJetLosslessRestartEnabled()).
+		withDuration(r.clock.Since(started)).
+		withMembers(memberStatuses))
+	if err != nil {
+		return result, err
+	}
+
+	// The spec is marked as applied only after the backup succeeded so a failed backup can be triggered again
+	if err := r.updateLastSuccessfulConfiguration(ctx, backupName, logger); err != nil {
+		logger.Error(err, "Could not save the current successful spec as annotation to the custom resource")
+	}
+	return result, nil
+}
+
+// reportUploading moves the backup to the Uploading state once the local backups of all the members finished.
+func (r *HotBackupReconciler) reportUploading(ctx context.Context, hb *hazelcastv1alpha1.HotBackup, runID string, members int, bucketURI string, logger logr.Logger) {
+	logger.Info("Local backups of all the members finished, uploading")
+	_, err := r.updateStatus(ctx, types.NamespacedName{Name: hb.Name, Namespace: hb.Namespace}, hbWithStatus(hazelcastv1alpha1.HotBackupUploading).
+		withMessage(fmt.Sprintf("Local backups of %d members finished, uploading to %s", members, bucketURI)))
+	if err != nil {
+		logger.Error(err, "Could not update the status of the uploading backup")
+	}
+	r.recorder.AnnotatedEventf(hb, runAnnotations(runID), corev1.EventTypeNormal, "UploadStarted",
+		"Local backups of all the members finished, uploading to %s", bucketURI)
+}
+
+// uploadResults collects what the agents report about the finished member uploads of a backup.
+type uploadResults struct {
+	mu               sync.Mutex
+	compressionLevel int32
+	originalSize     int64
+	compressedSize   int64
+	backupFolder     string
+	// object checksums reported by the agents keyed by the object keys
+	checksums       map[string]string
+	missingChecksum bool
+}
+
+func newUploadResults(members int) *uploadResults {
+	return &uploadResults{checksums: make(map[string]string, members)}
+}
+
+func (u *uploadResults) add(s rest.UploadStatus) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.compressionLevel = s.CompressionLevel
+	u.originalSize += s.OriginalSize
+	u.compressedSize += s.CompressedSize
+	if s.BackupKey != "" {
+		// member backups are stored next to each other in the backup folder
+		u.backupFolder = path.Dir(s.BackupKey)
+	}
+	if s.Checksum != "" && s.BackupKey != "" {
+		u.checksums[s.BackupKey] = s.Checksum
+	} else {
+		u.missingChecksum = true
+	}
+}
+
+// hasChecksums returns true if every agent reported the checksum of its object, the digest is computed only then.
+func (u *uploadResults) hasChecksums() bool {
+	return !u.missingChecksum && u.backupFolder != ""
+}
+
+// updateHazelcastBackupStatus writes the summary of the HotBackup to the status of its Hazelcast resource.
+// Only the backup field is patched, so it does not conflict with the status updates of the Hazelcast controller.
+func (r *HotBackupReconciler) updateHazelcastBackupStatus(ctx context.Context, hb *hazelcastv1alpha1.HotBackup) error {
+	h := &hazelcastv1alpha1.Hazelcast{}
+	if err := r.Get(ctx, types.NamespacedName{Name: hb.Spec.HazelcastResourceName, Namespace: hb.Namespace}, h); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	s := &hazelcastv1alpha1.HazelcastBackupStatus{
+		HotBackup:       hb.Name,
+		State:           hb.Status.State,
+		Message:         hb.Status.Message,
+		LastSuccessTime: hb.Status.LastSuccessTime,
+	}
+	// the last success of the cluster is kept if it is newer, e.g. made by another HotBackup of the cluster
+	if old := h.Status.Backup; old != nil && old.LastSuccessTime != nil &&
+		(s.LastSuccessTime == nil || old.LastSuccessTime.After(s.LastSuccessTime.Time)) {
+		s.LastSuccessTime = old.LastSuccessTime
+	}
+	if equality.Semantic.DeepEqual(h.Status.Backup, s) {
+		return nil
+	}
+	patch := client.MergeFrom(h.DeepCopy())
+	h.Status.Backup = s
+	return r.Status().Patch(ctx, h, patch)
+}
+
+// abandonedMembers returns the addresses of the members whose upload was abandoned.
+func abandonedMembers(members []hazelcastv1alpha1.HotBackupMemberStatus) []string {
+	var abandoned []string
+	for _, m := range members {
+		if m.Abandoned {
+			abandoned = append(abandoned, m.Address)
+		}
+	}
+	return abandoned
+}
+
+// skippedMembers returns the members left out of the backup with the reasons.
+func skippedMembers(members []hazelcastv1alpha1.HotBackupMemberStatus) []hazelcastv1alpha1.HotBackupSkippedMember {
+	var skipped []hazelcastv1alpha1.HotBackupSkippedMember
+	for _, m := range members {
+		if m.Abandoned {
+			skipped = append(skipped, hazelcastv1alpha1.HotBackupSkippedMember{
+				Address: m.Address,
+				Reason:  "Upload was abandoned within maxFailedUploads: " + m.Error,
+			})
+		}
+	}
+	return skipped
+}
+
+// failedOverMembers returns the addresses of the members uploaded to the failover bucket.
+func failedOverMembers(members []hazelcastv1alpha1.HotBackupMemberStatus) []string {
+	var failedOver []string
+	for _, m := range members {
+		if m.FailedOver && !m.Abandoned {
+			failedOver = append(failedOver, m.Address)
+		}
+	}
+	return failedOver
+}
+
+// effectiveConfig returns the configuration of the run with the defaults of the operator and of the cluster applied.
+func (r *HotBackupReconciler) effectiveConfig(hb *hazelcastv1alpha1.HotBackup, hz *hazelcastv1alpha1.Hazelcast, external bool) *hazelcastv1alpha1.HotBackupEffectiveConfig {
+	c := &hazelcastv1alpha1.HotBackupEffectiveConfig{
+		FlushHealthCheckInterval: &metav1.Duration{Duration: flushHealthCheckInterval(hb)},
+		HazelcastFetchTimeout:    &metav1.Duration{Duration: r.hazelcastFetchTimeout},
+	}
+	// the upload settings do not apply to the backups kept on the members only
+	if !external {
+		return c
+	}
+	c.BucketURI = hb.Spec.BucketURI
+	c.BackupPath = backupPath(hb, hz)
+	c.UploadMode = hb.Spec.UploadMode
+	if c.UploadMode == "" {
+		c.UploadMode = hazelcastv1alpha1.UploadParallel
+	}
+	c.MaxFailedUploads = hb.Spec.MaxFailedUploads
+	c.Compression = hb.Spec.Compression
+	if c.Compression == "" {
+		c.Compression = hazelcastv1alpha1.CompressionGzip
+	}
+	c.CompressionLevel = hb.Spec.CompressionLevel
+	if p := hz.Spec.Persistence; p != nil {
+		c.MaxConcurrentUploads = p.MaxConcurrentUploads
+	}
+	if c.UploadMode == hazelcastv1alpha1.UploadSequential {
+		c.MaxConcurrentUploads = 1
+	}
+	return c
+}
+
+func backupAddresses(members []*backup.MemberBackup) []string {
+	addresses := make([]string, len(members))
+	for i, m := range members {
+		addresses[i] = m.Address
+	}
+	return addresses
+}
+
+// backupPath returns the directory on the members the backup is uploaded from.
+func backupPath(hb *hazelcastv1alpha1.HotBackup, hz *hazelcastv1alpha1.Hazelcast) string {
+	if hb.Spec.BackupPathOverride != "" {
+		return hb.Spec.BackupPathOverride
+	}
+	if hz.Spec.Persistence == nil {
+		return ""
+	}
+	return hz.Spec.Persistence.BaseDir
+}
+
+// deltaBase returns the backup folder the delta backup is taken since, i.e. the last successful backup of the HotBackup
+// if it was uploaded to the same bucket. It is empty if the HotBackup takes full backups or no base backup exists.
+func deltaBase(hb *hazelcastv1alpha1.HotBackup) string {
+	if !hb.Spec.Delta || hb.Status.LastSuccessTime == nil || hb.Status.LocalOnly || hb.Status.BackupFolder == "" {
+		return ""
+	}
+	s, ok := hb.Annotations[n.LastSuccessfulSpecAnnotation]
+	if !ok {
+		return ""
+	}
+	last := &hazelcastv1alpha1.HotBackupSpec{}
+	if err := json.Unmarshal([]byte(s), last); err != nil || last.BucketURI != hb.Spec.BucketURI {
+		return ""
+	}
+	return hb.Status.BackupFolder
+}
+
+// failoverConfig returns the configuration of the upload to the replica of the bucket in the failover region.
+func failoverConfig(config *upload.Config, f *hazelcastv1alpha1.BucketFailover) *upload.Config {
+	c := *config
+	c.BucketURI = f.BucketURI
+	if f.Secret != "" {
+		c.SecretName = f.Secret
+	}
+	return &c
+}
+
+// uploadedBytes returns the bytes sent by the finished upload, agents not reporting the progress report the compressed size only.
+func uploadedBytes(s rest.UploadStatus) int64 {
+	if s.UploadedSize > s.CompressedSize {
+		return s.UploadedSize
+	}
+	return s.CompressedSize
+}
+
+// storeDigest computes the digest of the backup set from the checksums of its objects and stores it
+// in the manifest of the backup folder by the agent of the member.
+func storeDigest(ctx context.Context, hb *hazelcastv1alpha1.HotBackup, memberAddress, backupFolder string, checksums map[string]string, logger logr.Logger) (string, error) {
+	digest := upload.Digest(checksums)
+	logger.Info("Storing backup digest in the manifest", "digest", digest)
+	config := &upload.Config{
+		BucketURI:  hb.Spec.BucketURI,
+		SecretName: hb.Spec.Secret,
+	}
+	if err := upload.UpdateManifest(ctx, memberAddress, config, backupFolder, digest); err != nil {
+		return "", fmt.Errorf("could not store backup digest: %w", err)
+	}
+	return digest, nil
+}
+
+// updateLatestPointer points the latest backup pointer of the cluster to the backup folder.
+// The backup itself is complete even if it fails, so the error is only returned as a status message.
+func updateLatestPointer(ctx context.Context, hb *hazelcastv1alpha1.HotBackup, memberAddress, backupFolder string, logger logr.Logger) string {
+	logger.Info("Updating latest backup pointer", "backupFolder", backupFolder)
+	config := &upload.Config{
+		BucketURI:     hb.Spec.BucketURI,
+		HazelcastName: hb.Spec.HazelcastResourceName,
+		SecretName:    hb.Spec.Secret,
+	}
+	if err := upload.UpdateLatest(ctx, memberAddress, config, backupFolder); err != nil {
+		logger.Error(err, "Could not update latest backup pointer")
+		return fmt.Sprintf("Latest backup pointer could not be updated: %v", err)
+	}
+	return ""
+}
+
+func objectACL(acl *hazelcastv1alpha1.ObjectACL) *rest.ObjectACL {
+	if acl == nil {
+		return nil
+	}
+	r := &rest.ObjectACL{Canned: acl.Canned}
+	for _, g := range acl.Grants {
+		r.Grants = append(r.Grants, rest.ACLGrant{Grantee: g.Grantee, Permission: string(g.Permission)})
+	}
+	return r
+}
+
+// purgeUploads deletes the objects uploaded by a failed backup unless they are kept by the HotBackup.
+// It is best-effort, the errors are only logged. The agents older than n.MinAgentVersion cannot purge the uploads.
+func (r *HotBackupReconciler) purgeUploads(ctx context.Context, hb *hazelcastv1alpha1.HotBackup, hz *hazelcastv1alpha1.Hazelcast, sinks []upload.BackupSink, logger logr.Logger) {
+	if hb.Spec.KeepFailedUploads {
+		return
+	}
+	if !agentSupportsFeatures(hz) {
+		logger.Info("Keeping the uploaded objects of the failed backup, the agent cannot delete them", "agentVersion", hz.Spec.Agent.Version)
+		return
+	}
+	for _, s := range sinks {
+		if err := s.Purge(ctx); err != nil {
+			logger.Error(err, "Could not delete the uploaded objects of the failed backup")
+		}
+	}
+}
+
+// memberUploadSlots returns the slots of the member uploads running at the same time or nil if they are not limited.
+// The members back up at once but the uploads are limited by the capacity of the cluster.
+func memberUploadSlots(hb *hazelcastv1alpha1.HotBackup, hz *hazelcastv1alpha1.Hazelcast) chan struct{} {
+	// the sequential uploads free the disk of each member before the next one starts
+	if hb.Spec.UploadMode == hazelcastv1alpha1.UploadSequential {
+		return make(chan struct{}, 1)
+	}
+	if p := hz.Spec.Persistence; p != nil && p.MaxConcurrentUploads > 0 {
+		return make(chan struct{}, p.MaxConcurrentUploads)
+	}
+	return nil
+}
+
+// clusterQueueTimeout returns the time a run of the HotBackup waits for the running backup of its cluster at most.
+func clusterQueueTimeout(hb *hazelcastv1alpha1.HotBackup) time.Duration {
+	if hb.Spec.PendingTimeout != nil {
+		return hb.Spec.PendingTimeout.Duration
+	}
+	return defaultClusterQueueTimeout
+}
+
+// pendingTimeoutError returns an error if the pending HotBackup exceeded its pending timeout.
+func pendingTimeoutError(hb *hazelcastv1alpha1.HotBackup, now time.Time) error {
+	if hb.Spec.PendingTimeout == nil || hb.Status.PendingSince == nil {
+		return nil
+	}
+	if now.Sub(hb.Status.PendingSince.Time) < hb.Spec.PendingTimeout.Duration {
+		return nil
+	}
+	err := fmt.Errorf("%w: HotBackup did not start within %s", errPendingTimeout, hb.Spec.PendingTimeout.Duration)
+	if hb.Status.Message != "" {
+		err = fmt.Errorf("%w: %s", err, hb.Status.Message)
+	}
+	return err
+}
+
+// validateBucketSecret fails before any backup work if the credentials of the bucket or its failover replica are missing.
+func (r *HotBackupReconciler) validateBucketSecret(ctx context.Context, hb *hazelcastv1alpha1.HotBackup) error {
+	if err := r.validateSecret(ctx, hb.Namespace, hb.Spec.BucketURI, hb.Spec.Secret); err != nil {
+		return err
+	}
+	if f := hb.Spec.Failover; f != nil && f.Secret != "" {
+		return r.validateSecret(ctx, hb.Namespace, f.BucketURI, f.Secret)
+	}
+	return nil
+}
+
+func (r *HotBackupReconciler) validateSecret(ctx context.Context, namespace, bucketURI, name string) error {
+	s := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, s); err != nil {
+		if apiErrors.IsNotFound(err) {
+			return fmt.Errorf("secret %s not found", name)
+		}
+		return err
+	}
+	return validation.ValidateBucketSecret(bucketURI, s)
+}
+
+func auditRecord(hb *hazelcastv1alpha1.HotBackup) *audit.Record {
+	triggeredBy := "manual"
+	switch {
+	case hb.Status.TriggerCause == hazelcastv1alpha1.HotBackupTriggerTrigger:
+		triggeredBy = "trigger"
+	case hb.Spec.Schedule != "":
+		triggeredBy = "schedule"
+	}
+	return &audit.Record{
+		Time:         time.Now().UTC(),
+		Namespace:    hb.Namespace,
+		HotBackup:    hb.Name,
+		Hazelcast:    hb.Spec.HazelcastResourceName,
+		TriggeredBy:  triggeredBy,
+		Result:       string(hb.Status.State),
+		Message:      hb.Status.Message,
+		BucketURI:    hb.Spec.BucketURI,
+		BackupFolder: hb.Status.BackupFolder,
+		RunID:        hb.Status.RunID,
+	}
+}
+
+// newRunID returns the ID of a new run of the backup. One-off backups use the RunIDAnnotation if it is set.
+func newRunID(hb *hazelcastv1alpha1.HotBackup) string {
+	if id := hb.Annotations[n.RunIDAnnotation]; id != "" && hb.Spec.Schedule == "" {
+		return id
+	}
+	return uuid.New().String()
+}
+
+// hazelcastFetchBackoff is the backoff of the retries of fetching the Hazelcast CR, the retries are bounded by hazelcastFetchTimeout
+var hazelcastFetchBackoff = wait.Backoff{
+	Duration: 500 * time.Millisecond,
+	Factor:   2.0,
+	Jitter:   0.1,
+	Steps:    math.MaxInt32,
+	Cap:      10 * time.Second,
+}
+
+// getHazelcast fetches the Hazelcast CR of the backup. The transient errors of the API server are retried within
+// hazelcastFetchTimeout, the other errors like NotFound are returned at once.
+func (r *HotBackupReconciler) getHazelcast(ctx context.Context, name types.NamespacedName, logger logr.Logger) (*hazelcastv1alpha1.Hazelcast, error) {
+	deadline := r.clock.Now().Add(r.hazelcastFetchTimeout)
+	backoff := hazelcastFetchBackoff
+	for {
+		h := &hazelcastv1alpha1.Hazelcast{}
+		err := r.Get(ctx, name, h)
+		if err == nil {
+			return h, nil
+		}
+		if !isTransientAPIError(err) {
+			return nil, err
+		}
+		delay := backoff.Step()
+		if r.clock.Now().Add(delay).After(deadline) {
+			return nil, fmt.Errorf("could not get Hazelcast CR within %s: %w", r.hazelcastFetchTimeout, err)
+		}
+		logger.Info("Could not get Hazelcast CR, retrying", "error", err.Error(), "retryAfter", delay)
+		select {
+		case <-r.clock.After(delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// isTransientAPIError returns true for the errors of an API server which is overloaded or not reachable for a while.
+func isTransientAPIError(err error) bool {
+	var netErr net.Error
+	return apiErrors.IsServerTimeout(err) || apiErrors.IsTimeout(err) || apiErrors.IsTooManyRequests(err) ||
+		apiErrors.IsServiceUnavailable(err) || apiErrors.IsInternalError(err) || apiErrors.IsUnexpectedServerError(err) ||
+		errors.As(err, &netErr)
+}
+
+// appliedTriggerCause returns the cause of the run started by the reconcile of the HotBackup.
+func appliedTriggerCause(hb *hazelcastv1alpha1.HotBackup) hazelcastv1alpha1.HotBackupTriggerCause {
+	if _, ok := hb.Labels[n.HotBackupTriggerLabel]; ok {
+		return hazelcastv1alpha1.HotBackupTriggerTrigger
+	}
+	return hazelcastv1alpha1.HotBackupTriggerManual
+}
+
+// triggerSource returns the schedule of the scheduled runs and the HotBackupTrigger of the triggered runs.
+func triggerSource(hb *hazelcastv1alpha1.HotBackup, cause hazelcastv1alpha1.HotBackupTriggerCause) string {
+	switch cause {
+	case hazelcastv1alpha1.HotBackupTriggerScheduled:
+		return hb.Spec.Schedule
+	case hazelcastv1alpha1.HotBackupTriggerTrigger:
+		return hb.Labels[n.HotBackupTriggerLabel]
+	}
+	return ""
+}
+
+// runAnnotations returns the annotations of the events of the run of the backup.
+func runAnnotations(runID string) map[string]string {
+	if runID == "" {
+		return nil
+	}
+	return map[string]string{n.RunIDAnnotation: runID}
+}
+
+// isExternal returns true if the backups of the Hazelcast cluster are uploaded by the backup agents.
+func (r *HotBackupReconciler) isExternal(h *hazelcastv1alpha1.Hazelcast) bool {
+	return h.Spec.Persistence.IsExternal() && !r.disableExternalBackups
+}
+
+// isBackupDisabled returns true if the backups of the Hazelcast cluster are disabled by the backup=disabled label.
+func isBackupDisabled(h *hazelcastv1alpha1.Hazelcast) bool {
+	return h.Labels[n.BackupLabel] == n.BackupLabelDisabled
+}
+
+func skippedHbStatus(h *hazelcastv1alpha1.Hazelcast) hotBackupOptionsBuilder {
+	return hbWithStatus(hazelcastv1alpha1.HotBackupSkipped).
+		withMessage(fmt.Sprintf("Backups of Hazelcast %s are disabled by the %s=%s label", h.Name, n.BackupLabel, n.BackupLabelDisabled))
+}
+
+func maintenanceHbStatus() hotBackupOptionsBuilder {
+	return hbWithStatus(hazelcastv1alpha1.HotBackupSkipped).
+		withMessage("Scheduled backup was skipped due to maintenance mode of the operator")
 }
 
 func (r *HotBackupReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.recorder = mgr.GetEventRecorderFor("hotbackup-controller")
+	if err := mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+		r.recoverInterruptedBackups(ctx)
+		return nil
+	})); err != nil {
+		return err
+	}
+	if err := mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+		wait.UntilWithContext(ctx, r.checkFreshness, freshnessCheckInterval)
+		return nil
+	})); err != nil {
+		return err
+	}
+	if err := mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+		wait.UntilWithContext(ctx, r.cancelOrphanedUploads, orphanedUploadsCheckInterval)
+		return nil
+	})); err != nil {
+		return err
+	}
+	if err := mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+		wait.UntilWithContext(ctx, r.estimateSizes, sizeEstimateInterval)
+		return nil
+	})); err != nil {
+		return err
+	}
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&hazelcastv1alpha1.HotBackup{}).
+		For(&hazelcastv1alpha1.HotBackup{}, builder.WithPredicates(hotBackupUpdates())).
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.maxConcurrentReconciles}).
 		Complete(r)
 }
+
+// hotBackupUpdates filters out the updates of HotBackups which do not need a reconciliation, e.g. the status updates
+// made by the operator. Changes of the spec, the deletion and the annotations set by users are reconciled.
+func hotBackupUpdates() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			if e.ObjectOld == nil || e.ObjectNew == nil {
+				return true
+			}
+			if e.ObjectOld.GetGeneration() != e.ObjectNew.GetGeneration() {
+				return true
+			}
+			if e.ObjectOld.GetDeletionTimestamp().IsZero() != e.ObjectNew.GetDeletionTimestamp().IsZero() {
+				return true
+			}
+			return !equality.Semantic.DeepEqual(userAnnotations(e.ObjectOld), userAnnotations(e.ObjectNew))
+		},
+	}
+}
+
+// userAnnotations returns the annotations of the object without the ones written by the operator itself.
+func userAnnotations(o client.Object) map[string]string {
+	a := make(map[string]string, len(o.GetAnnotations()))
+	for k, v := range o.GetAnnotations() {
+		if k != n.LastSuccessfulSpecAnnotation {
+			a[k] = v
+		}
+	}
+	return a
+}
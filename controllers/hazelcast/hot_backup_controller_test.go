@@ -2,7 +2,11 @@ package hazelcast
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -10,20 +14,31 @@ import (
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/robfig/cron/v3"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 
 	. "github.com/onsi/gomega"
 
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
+	corev1 "k8s.io/api/core/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	hztypes "github.com/hazelcast/hazelcast-go-client/types"
 	hazelcastv1alpha1 "github.com/hazelcast/hazelcast-platform-operator/api/v1alpha1"
 	hzclient "github.com/hazelcast/hazelcast-platform-operator/controllers/hazelcast/client"
 	hzconfig "github.com/hazelcast/hazelcast-platform-operator/controllers/hazelcast/config"
+	"github.com/hazelcast/hazelcast-platform-operator/controllers/hazelcast/validation"
+	"github.com/hazelcast/hazelcast-platform-operator/internal/audit"
+	"github.com/hazelcast/hazelcast-platform-operator/internal/naming"
+	"github.com/hazelcast/hazelcast-platform-operator/internal/upload"
 )
 
 func TestHotBackupReconciler_shouldScheduleHotBackupExecution(t *testing.T) {
@@ -49,7 +64,7 @@ func TestHotBackupReconciler_shouldScheduleHotBackupExecution(t *testing.T) {
 		},
 		Spec: hazelcastv1alpha1.HotBackupSpec{
 			HazelcastResourceName: "hazelcast",
-			Schedule:              "0 23 31 2 *",
+			Schedule:              "0 23 29 2 *",
 		},
 	}
 	r := hotBackupReconcilerWithCRs(h, hb)
@@ -67,6 +82,48 @@ func TestHotBackupReconciler_shouldScheduleHotBackupExecution(t *testing.T) {
 	))
 }
 
+func TestHotBackupReconciler_shouldRegisterScheduleAgainAfterRestart(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	n := types.NamespacedName{
+		Name:      "hazelcast",
+		Namespace: "default",
+	}
+	h := &hazelcastv1alpha1.Hazelcast{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      n.Name,
+			Namespace: n.Namespace,
+		},
+		Status: hazelcastv1alpha1.HazelcastStatus{
+			Phase: hazelcastv1alpha1.Running,
+		},
+	}
+	hb := &hazelcastv1alpha1.HotBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      n.Name,
+			Namespace: n.Namespace,
+		},
+		Spec: hazelcastv1alpha1.HotBackupSpec{
+			HazelcastResourceName: "hazelcast",
+			Schedule:              "0 23 29 2 *",
+		},
+		// the status left by the previous operator instance
+		Status: hazelcastv1alpha1.HotBackupStatus{
+			State:              hazelcastv1alpha1.HotBackupSuccess,
+			ScheduleRegistered: true,
+		},
+	}
+	r := hotBackupReconcilerWithCRs(h, hb)
+	_, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: n})
+	Expect(err).Should(BeNil())
+	Expect(r.cron.Entries()).Should(HaveLen(1))
+
+	hb = &hazelcastv1alpha1.HotBackup{}
+	Expect(r.Get(context.TODO(), n, hb)).Should(Succeed())
+	Expect(hb.Status.ScheduleRegistered).Should(BeTrue())
+	Expect(hb.Status.NextScheduledRun).ShouldNot(BeNil())
+	Expect(hb.Status.NextScheduledRun.Time).Should(BeTemporally("==", r.cron.Entries()[0].Next))
+}
+
 func TestHotBackupReconciler_shouldRemoveScheduledBackup(t *testing.T) {
 	RegisterFailHandler(fail(t))
 	n := types.NamespacedName{
@@ -87,7 +144,7 @@ func TestHotBackupReconciler_shouldRemoveScheduledBackup(t *testing.T) {
 		},
 		Spec: hazelcastv1alpha1.HotBackupSpec{
 			HazelcastResourceName: "hazelcast",
-			Schedule:              "0 23 31 2 *",
+			Schedule:              "0 23 29 2 *",
 		},
 	}
 
@@ -154,6 +211,7 @@ func TestHotBackupReconciler_shouldSetStatusToFailedWhenHbCallFails(t *testing.T
 		_ = r.Client.Get(context.TODO(), n, hb)
 		return hb.Status.State
 	}, 2*time.Second, 100*time.Millisecond).Should(Equal(hazelcastv1alpha1.HotBackupFailure))
+	Expect(hb.Annotations).ShouldNot(HaveKey(naming.LastSuccessfulSpecAnnotation))
 }
 
 func TestHotBackupReconciler_shouldNotTriggerHotBackupTwice(t *testing.T) {
@@ -226,7 +284,7 @@ func TestHotBackupReconciler_shouldNotTriggerHotBackupTwice(t *testing.T) {
 	restCallWg.Done()
 	reconcileWg.Wait()
 
-	Expect(hotBackupTriggers).Should(Equal(int32(1)))
+	Expect(atomic.LoadInt32(&hotBackupTriggers)).Should(Equal(int32(1)))
 }
 
 func TestHotBackupReconciler_shouldUpdateWhenScheduledBackupChangedToInstantBackup(t *testing.T) {
@@ -249,7 +307,7 @@ func TestHotBackupReconciler_shouldUpdateWhenScheduledBackupChangedToInstantBack
 		},
 		Spec: hazelcastv1alpha1.HotBackupSpec{
 			HazelcastResourceName: "hazelcast",
-			Schedule:              "0 23 31 2 *",
+			Schedule:              "0 23 29 2 *",
 		},
 	}
 	ts, err := fakeHttpServer(hzconfig.HazelcastUrl(h), func(writer http.ResponseWriter, request *http.Request) {
@@ -297,7 +355,7 @@ func TestHotBackupReconciler_shouldSetStatusToFailedIfHazelcastCRNotFound(t *tes
 		},
 		Spec: hazelcastv1alpha1.HotBackupSpec{
 			HazelcastResourceName: "hazelcast",
-			Schedule:              "0 23 31 2 *",
+			Schedule:              "0 23 29 2 *",
 		},
 	}
 
@@ -312,17 +370,1192 @@ func TestHotBackupReconciler_shouldSetStatusToFailedIfHazelcastCRNotFound(t *tes
 	Expect(hb.Status.Message).Should(Not(BeEmpty()))
 }
 
-func fail(t *testing.T) func(message string, callerSkip ...int) {
-	return func(message string, callerSkip ...int) {
-		t.Errorf(message)
+func TestHotBackupReconciler_shouldFailOnInvalidSchedule(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	n := types.NamespacedName{
+		Name:      "hazelcast",
+		Namespace: "default",
+	}
+	h := &hazelcastv1alpha1.Hazelcast{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      n.Name,
+			Namespace: n.Namespace,
+		},
+		Status: hazelcastv1alpha1.HazelcastStatus{Phase: hazelcastv1alpha1.Running},
+	}
+	hb := &hazelcastv1alpha1.HotBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        n.Name,
+			Namespace:   n.Namespace,
+			Annotations: make(map[string]string),
+		},
+		Spec: hazelcastv1alpha1.HotBackupSpec{
+			HazelcastResourceName: "hazelcast",
+			Schedule:              "*/30 * * * * *",
+		},
 	}
+
+	r := hotBackupReconcilerWithCRs(h, hb)
+	_, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: n})
+	Expect(err).ShouldNot(BeNil())
+	Expect(r.cron.Entries()).Should(BeEmpty())
+	_ = r.Client.Get(context.TODO(), n, hb)
+	Expect(hb.Status.State).Should(Equal(hazelcastv1alpha1.HotBackupFailure))
+
+	Expect(NewScheduleParser(true).Parse(hb.Spec.Schedule)).ShouldNot(BeNil())
+	Expect(NewScheduleParser(false).Parse("@every 6h")).ShouldNot(BeNil())
 }
 
-func hotBackupReconcilerWithCRs(initObjs ...client.Object) HotBackupReconciler {
-	return HotBackupReconciler{
-		Client: fakeClient(initObjs...),
-		Log:    ctrl.Log.WithName("test").WithName("Hazelcast"),
-		cron:   cron.New(),
-		backup: make(map[types.NamespacedName]struct{}),
+func TestHotBackupReconciler_shouldValidateCompressionLevel(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	hb := &hazelcastv1alpha1.HotBackup{
+		Spec: hazelcastv1alpha1.HotBackupSpec{
+			HazelcastResourceName: "hazelcast",
+			CompressionLevel:      19,
+		},
+	}
+	Expect(validation.ValidateHotBackup(hb, NewScheduleParser(false))).ShouldNot(Succeed())
+
+	hb.Spec.Compression = hazelcastv1alpha1.CompressionZstd
+	Expect(validation.ValidateHotBackup(hb, NewScheduleParser(false))).Should(Succeed())
+
+	Expect(hbWithStatus(hazelcastv1alpha1.HotBackupSuccess).withCompression(19, 300, 100).compressionRatio).Should(Equal("3.00"))
+}
+
+func TestHotBackupReconciler_shouldRejectReservedRequestHeaders(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	hb := &hazelcastv1alpha1.HotBackup{
+		Spec: hazelcastv1alpha1.HotBackupSpec{
+			BucketURI:      "s3://backup",
+			RequestHeaders: map[string]string{"X-Proxy-Tenant": "backups"},
+		},
+	}
+	Expect(validation.ValidateHotBackup(hb, NewScheduleParser(false))).Should(Succeed())
+
+	for _, h := range []string{"authorization", "x-amz-acl", "Invalid Header"} {
+		hb.Spec.RequestHeaders = map[string]string{h: "value"}
+		Expect(validation.ValidateHotBackup(hb, NewScheduleParser(false))).ShouldNot(Succeed())
+	}
+}
+
+func TestHotBackupReconciler_shouldValidateRetention(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	hb := &hazelcastv1alpha1.HotBackup{
+		Spec: hazelcastv1alpha1.HotBackupSpec{
+			HazelcastResourceName: "hazelcast",
+			Retention:             &hazelcastv1alpha1.BackupRetention{},
+		},
+	}
+	Expect(validation.ValidateHotBackup(hb, NewScheduleParser(false))).ShouldNot(Succeed())
+
+	// the local backups are pruned without a bucket
+	hb.Spec.Retention.MaxCount = 3
+	Expect(validation.ValidateHotBackup(hb, NewScheduleParser(false))).Should(Succeed())
+
+	hb.Spec.Retention = &hazelcastv1alpha1.BackupRetention{MaxAge: &metav1.Duration{Duration: 24 * time.Hour}}
+	Expect(validation.ValidateHotBackup(hb, NewScheduleParser(false))).Should(Succeed())
+
+	hb.Spec.Retention.MaxAge.Duration = -time.Hour
+	Expect(validation.ValidateHotBackup(hb, NewScheduleParser(false))).ShouldNot(Succeed())
+}
+
+func TestHotBackupReconciler_shouldRejectScheduledCompact(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	hb := &hazelcastv1alpha1.HotBackup{
+		Spec: hazelcastv1alpha1.HotBackupSpec{
+			BucketURI: "s3://backup",
+			Compact:   &hazelcastv1alpha1.HotBackupCompactConfiguration{BackupFolder: "backup-1643184000"},
+		},
+	}
+	Expect(validation.ValidateHotBackup(hb, NewScheduleParser(false))).Should(Succeed())
+
+	hb.Spec.Schedule = "@daily"
+	Expect(validation.ValidateHotBackup(hb, NewScheduleParser(false))).ShouldNot(Succeed())
+}
+
+func TestHotBackupReconciler_shouldValidateObjectLockRetentionPeriod(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	hb := &hazelcastv1alpha1.HotBackup{
+		Spec: hazelcastv1alpha1.HotBackupSpec{
+			BucketURI:  "s3://backup",
+			ObjectLock: &hazelcastv1alpha1.ObjectLockConfiguration{RetentionPeriod: metav1.Duration{Duration: 30 * 24 * time.Hour}},
+		},
+	}
+	Expect(validation.ValidateHotBackup(hb, NewScheduleParser(false))).Should(Succeed())
+
+	for _, d := range []time.Duration{0, -time.Hour} {
+		hb.Spec.ObjectLock.RetentionPeriod.Duration = d
+		Expect(validation.ValidateHotBackup(hb, NewScheduleParser(false))).ShouldNot(Succeed())
+	}
+}
+
+func TestHotBackupReconciler_shouldSetLastSuccessMetricOnSuccess(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	n := types.NamespacedName{
+		Name:      "hazelcast-metrics",
+		Namespace: "default",
+	}
+	hb := &hazelcastv1alpha1.HotBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      n.Name,
+			Namespace: n.Namespace,
+		},
+		Spec: hazelcastv1alpha1.HotBackupSpec{
+			HazelcastResourceName: "hazelcast",
+		},
+	}
+
+	r := hotBackupReconcilerWithCRs(hb)
+	_, err := r.updateStatus(context.TODO(), n, hbWithStatus(hazelcastv1alpha1.HotBackupFailure))
+	Expect(err).Should(BeNil())
+	Expect(testutil.ToFloat64(hotBackupLastSuccess.WithLabelValues(n.Namespace, n.Name))).Should(BeZero())
+
+	_, err = r.updateStatus(context.TODO(), n, hbWithStatus(hazelcastv1alpha1.HotBackupSuccess))
+	Expect(err).Should(BeNil())
+	Expect(r.Client.Get(context.TODO(), n, hb)).Should(Succeed())
+	Expect(testutil.ToFloat64(hotBackupLastSuccess.WithLabelValues(n.Namespace, n.Name))).Should(Equal(float64(hb.Status.LastSuccessTime.Unix())))
+
+	deleteHotBackupMetrics(n)
+}
+
+func TestHotBackupReconciler_shouldSeedLastSuccessMetric(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	n := types.NamespacedName{Name: "hazelcast-seed", Namespace: "default"}
+	// the backup succeeded before the operator was restarted
+	lastSuccess := metav1.NewTime(time.Date(2022, 6, 2, 21, 57, 49, 0, time.UTC))
+	hb := &hazelcastv1alpha1.HotBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: n.Name, Namespace: n.Namespace},
+		Spec:       hazelcastv1alpha1.HotBackupSpec{HazelcastResourceName: "hazelcast"},
+		Status: hazelcastv1alpha1.HotBackupStatus{
+			State:           hazelcastv1alpha1.HotBackupSuccess,
+			LastSuccessTime: &lastSuccess,
+		},
+	}
+	r := hotBackupReconcilerWithCRs(hb)
+	defer deleteHotBackupMetrics(n)
+
+	_, _ = r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: n})
+	Expect(testutil.ToFloat64(hotBackupLastSuccess.WithLabelValues(n.Namespace, n.Name))).Should(Equal(float64(lastSuccess.Unix())))
+}
+
+func TestHotBackupReconciler_shouldReportSkippedMembers(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	n := types.NamespacedName{Name: "hazelcast", Namespace: "default"}
+	hb := &hazelcastv1alpha1.HotBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: n.Name, Namespace: n.Namespace},
+		Spec:       hazelcastv1alpha1.HotBackupSpec{HazelcastResourceName: "hazelcast"},
+	}
+	r := hotBackupReconcilerWithCRs(hb)
+	recorder := record.NewFakeRecorder(1)
+	r.recorder = recorder
+	defer deleteHotBackupMetrics(n)
+
+	_, err := r.updateStatus(context.TODO(), n, hbWithStatus(hazelcastv1alpha1.HotBackupPartialSuccess).
+		withBackupFolder("hazelcast/2022-06-02-21-57-49").
+		withMembers([]hazelcastv1alpha1.HotBackupMemberStatus{
+			{Address: "10.0.0.1:5701"},
+			{Address: "10.0.0.2:5701", Abandoned: true, Error: "connection refused"},
+		}))
+	Expect(err).Should(BeNil())
+
+	Expect(r.Client.Get(context.TODO(), n, hb)).Should(Succeed())
+	// the incomplete backup is not a successful one
+	Expect(hb.Status.State).Should(Equal(hazelcastv1alpha1.HotBackupPartialSuccess))
+	Expect(hb.Status.LastSuccessTime).Should(BeNil())
+	Expect(hb.Status.BackupFolder).Should(BeEmpty())
+	Expect(testutil.ToFloat64(hotBackupLastSuccess.WithLabelValues(n.Namespace, n.Name))).Should(BeZero())
+	Expect(hb.Status.SkippedMembers).Should(Equal([]hazelcastv1alpha1.HotBackupSkippedMember{
+		{Address: "10.0.0.2:5701", Reason: "Upload was abandoned within maxFailedUploads: connection refused"},
+	}))
+	Expect(<-recorder.Events).Should(HavePrefix("Warning MembersSkipped"))
+	Expect(testutil.ToFloat64(hotBackupMembersSkipped.WithLabelValues(n.Namespace, n.Name))).Should(Equal(1.0))
+
+	_, err = r.updateStatus(context.TODO(), n, hbWithStatus(hazelcastv1alpha1.HotBackupSuccess))
+	Expect(err).Should(BeNil())
+	hb = &hazelcastv1alpha1.HotBackup{}
+	Expect(r.Client.Get(context.TODO(), n, hb)).Should(Succeed())
+	Expect(hb.Status.SkippedMembers).Should(BeEmpty())
+}
+
+func TestHotBackupReconciler_shouldReportUploadingOnceAllMembersFlushed(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	n := types.NamespacedName{Name: "hazelcast", Namespace: "default"}
+	hb := &hazelcastv1alpha1.HotBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: n.Name, Namespace: n.Namespace},
+		Spec:       hazelcastv1alpha1.HotBackupSpec{HazelcastResourceName: "hazelcast", BucketURI: "s3://backup"},
+		Status:     hazelcastv1alpha1.HotBackupStatus{State: hazelcastv1alpha1.HotBackupInProgress},
+	}
+	r := hotBackupReconcilerWithCRs(hb)
+	recorder := record.NewFakeRecorder(1)
+	r.recorder = recorder
+	state := func() hazelcastv1alpha1.HotBackupState {
+		hb := &hazelcastv1alpha1.HotBackup{}
+		Expect(r.Client.Get(context.TODO(), n, hb)).Should(Succeed())
+		return hb.Status.State
+	}
+
+	g := newUploadingGate(2, func() {
+		r.reportUploading(context.TODO(), hb, "run-1", 2, hb.Spec.BucketURI, ctrl.Log)
+	})
+	// the first member uploads while the other one still flushes its local backup
+	g.flushed()
+	Expect(state()).Should(Equal(hazelcastv1alpha1.HotBackupInProgress))
+	Expect(recorder.Events).Should(BeEmpty())
+
+	g.flushed()
+	Expect(state()).Should(Equal(hazelcastv1alpha1.HotBackupUploading))
+	Expect(<-recorder.Events).Should(HavePrefix("Normal UploadStarted"))
+
+	_, err := r.updateStatus(context.TODO(), n, hbWithStatus(hazelcastv1alpha1.HotBackupSuccess))
+	Expect(err).Should(BeNil())
+	Expect(state()).Should(Equal(hazelcastv1alpha1.HotBackupSuccess))
+}
+
+func TestHotBackupReconciler_updateMemberStatus(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	n := types.NamespacedName{Name: "hazelcast", Namespace: "default"}
+	hb := &hazelcastv1alpha1.HotBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: n.Name, Namespace: n.Namespace},
+		Spec:       hazelcastv1alpha1.HotBackupSpec{HazelcastResourceName: "hazelcast"},
+	}
+	r := hotBackupReconcilerWithCRs(hb)
+
+	_, err := r.updateStatus(context.TODO(), n, hbWithStatus(hazelcastv1alpha1.HotBackupUploading).
+		withMembers([]hazelcastv1alpha1.HotBackupMemberStatus{
+			{Address: "10.0.0.1:5701", UUID: "a", Phase: hazelcastv1alpha1.HotBackupMemberUploading},
+			{Address: "10.0.0.2:5701", UUID: "b", Phase: hazelcastv1alpha1.HotBackupMemberUploading},
+		}))
+	Expect(err).Should(BeNil())
+
+	r.updateMemberStatus(context.TODO(), n, hazelcastv1alpha1.HotBackupMemberStatus{
+		Address: "10.0.0.2:5701", UUID: "b", Phase: hazelcastv1alpha1.HotBackupMemberUploaded, UploadedBytes: 1024,
+	}, ctrl.Log)
+	hb = &hazelcastv1alpha1.HotBackup{}
+	Expect(r.Client.Get(context.TODO(), n, hb)).Should(Succeed())
+	Expect(hb.Status.State).Should(Equal(hazelcastv1alpha1.HotBackupUploading))
+	Expect(hb.Status.Members).Should(Equal([]hazelcastv1alpha1.HotBackupMemberStatus{
+		{Address: "10.0.0.1:5701", UUID: "a", Phase: hazelcastv1alpha1.HotBackupMemberUploading},
+		{Address: "10.0.0.2:5701", UUID: "b", Phase: hazelcastv1alpha1.HotBackupMemberUploaded, UploadedBytes: 1024},
+	}))
+}
+
+func TestHotBackupReconciler_shouldObserveReconcileDuration(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	r := hotBackupReconcilerWithCRs()
+
+	_, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "missing", Namespace: "default"}})
+	Expect(err).Should(BeNil())
+	Expect(testutil.CollectAndCount(hotBackupReconcileDuration)).Should(BeNumerically(">", 0))
+}
+
+func TestHotBackupReconciler_shouldDetectOrphanedUploads(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	finished := &hazelcastv1alpha1.HotBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: "finished", Namespace: "default"},
+		Status:     hazelcastv1alpha1.HotBackupStatus{State: hazelcastv1alpha1.HotBackupSuccess},
+	}
+	running := &hazelcastv1alpha1.HotBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: "running", Namespace: "default"},
+		Status:     hazelcastv1alpha1.HotBackupStatus{State: hazelcastv1alpha1.HotBackupInProgress},
+	}
+
+	r := hotBackupReconcilerWithCRs(finished, running)
+	Expect(r.isUploadOrphaned(context.TODO(), types.NamespacedName{Name: "finished", Namespace: "default"})).Should(BeTrue())
+	Expect(r.isUploadOrphaned(context.TODO(), types.NamespacedName{Name: "deleted", Namespace: "default"})).Should(BeTrue())
+	Expect(r.isUploadOrphaned(context.TODO(), types.NamespacedName{Name: "running", Namespace: "default"})).Should(BeFalse())
+	Expect(r.isUploadOrphaned(context.TODO(), types.NamespacedName{Name: "", Namespace: "default"})).Should(BeFalse())
+}
+
+func TestHotBackupReconciler_shouldSkipBackupOfDisabledCluster(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	n := types.NamespacedName{
+		Name:      "hazelcast",
+		Namespace: "default",
+	}
+	h := &hazelcastv1alpha1.Hazelcast{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      n.Name,
+			Namespace: n.Namespace,
+			Labels:    map[string]string{naming.BackupLabel: naming.BackupLabelDisabled},
+		},
+		Status: hazelcastv1alpha1.HazelcastStatus{Phase: hazelcastv1alpha1.Running},
+	}
+	hb := &hazelcastv1alpha1.HotBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      n.Name,
+			Namespace: n.Namespace,
+		},
+		Spec: hazelcastv1alpha1.HotBackupSpec{
+			HazelcastResourceName: n.Name,
+		},
+	}
+
+	r := hotBackupReconcilerWithCRs(h, hb)
+	result, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: n})
+	Expect(err).Should(BeNil())
+	Expect(result.RequeueAfter).Should(Equal(backupDisabledRequeueInterval))
+	Expect(r.checkBackup(n)).Should(BeFalse())
+
+	Expect(r.Client.Get(context.TODO(), n, hb)).Should(Succeed())
+	Expect(hb.Status.State).Should(Equal(hazelcastv1alpha1.HotBackupSkipped))
+	Expect(hb.Status.Message).Should(ContainSubstring("backup=disabled"))
+}
+
+func TestHotBackupReconciler_shouldFailOnInvalidBucketSecret(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	n := types.NamespacedName{
+		Name:      "hazelcast",
+		Namespace: "default",
+	}
+	h := &hazelcastv1alpha1.Hazelcast{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      n.Name,
+			Namespace: n.Namespace,
+		},
+		Spec: hazelcastv1alpha1.HazelcastSpec{
+			Persistence: &hazelcastv1alpha1.HazelcastPersistenceConfiguration{
+				BaseDir:    "/data/hot-restart",
+				BackupType: hazelcastv1alpha1.External,
+			},
+		},
+		Status: hazelcastv1alpha1.HazelcastStatus{Phase: hazelcastv1alpha1.Running},
+	}
+	hb := &hazelcastv1alpha1.HotBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      n.Name,
+			Namespace: n.Namespace,
+		},
+		Spec: hazelcastv1alpha1.HotBackupSpec{
+			HazelcastResourceName: n.Name,
+			BucketURI:             "s3://backup",
+			Secret:                "br-secret-s3",
+		},
+	}
+	s := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "br-secret-s3",
+			Namespace: n.Namespace,
+		},
+		Data: map[string][]byte{
+			"region":        []byte("us-east-1"),
+			"access-key-id": []byte("key"),
+		},
+	}
+
+	r := hotBackupReconcilerWithCRs(h, hb, s)
+	_, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: n})
+	Expect(err).Should(MatchError("secret br-secret-s3 missing key secret-access-key"))
+
+	Expect(r.Client.Get(context.TODO(), n, hb)).Should(Succeed())
+	Expect(hb.Status.State).Should(Equal(hazelcastv1alpha1.HotBackupFailure))
+	Expect(r.checkBackup(n)).Should(BeFalse())
+}
+
+func TestHotBackupReconciler_shouldLockBackupOnce(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	n := types.NamespacedName{Name: "hazelcast", Namespace: "default"}
+	r := hotBackupReconcilerWithCRs()
+
+	var locked int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if r.lockBackup(n) {
+				atomic.AddInt32(&locked, 1)
+			}
+			_ = r.checkBackup(n)
+		}()
+	}
+	wg.Wait()
+
+	Expect(atomic.LoadInt32(&locked)).Should(Equal(int32(1)))
+	r.unlockBackup(n)
+	Expect(r.checkBackup(n)).Should(BeFalse())
+}
+
+func TestHotBackupReconciler_shouldFailScheduleThatNeverFires(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	n := types.NamespacedName{Name: "hazelcast", Namespace: "default"}
+	h := &hazelcastv1alpha1.Hazelcast{
+		ObjectMeta: metav1.ObjectMeta{Name: n.Name, Namespace: n.Namespace},
+		Spec: hazelcastv1alpha1.HazelcastSpec{
+			Persistence: &hazelcastv1alpha1.HazelcastPersistenceConfiguration{BaseDir: "/data/hot-restart"},
+		},
+		Status: hazelcastv1alpha1.HazelcastStatus{Phase: hazelcastv1alpha1.Running},
+	}
+	hb := &hazelcastv1alpha1.HotBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: n.Name, Namespace: n.Namespace},
+		Spec: hazelcastv1alpha1.HotBackupSpec{
+			HazelcastResourceName: n.Name,
+			Schedule:              "0 0 30 2 *",
+		},
+	}
+
+	r := hotBackupReconcilerWithCRs(h, hb)
+	recorder := record.NewFakeRecorder(1)
+	r.recorder = recorder
+	_, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: n})
+	Expect(errors.Is(err, errScheduleNeverFires)).Should(BeTrue())
+
+	Expect(r.Client.Get(context.TODO(), n, hb)).Should(Succeed())
+	Expect(hb.Status.State).Should(Equal(hazelcastv1alpha1.HotBackupFailure))
+	Expect(r.cron.Entries()).Should(BeEmpty())
+	Expect(<-recorder.Events).Should(HavePrefix("Warning ScheduleNeverFires"))
+}
+
+func TestHotBackupReconciler_shouldFailAfterPendingTimeout(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	n := types.NamespacedName{Name: "hazelcast", Namespace: "default"}
+	h := &hazelcastv1alpha1.Hazelcast{
+		ObjectMeta: metav1.ObjectMeta{Name: n.Name, Namespace: n.Namespace},
+		Status:     hazelcastv1alpha1.HazelcastStatus{Phase: hazelcastv1alpha1.Pending},
+	}
+	hb := &hazelcastv1alpha1.HotBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: n.Name, Namespace: n.Namespace},
+		Spec: hazelcastv1alpha1.HotBackupSpec{
+			HazelcastResourceName: n.Name,
+			PendingTimeout:        &metav1.Duration{Duration: time.Minute},
+		},
+	}
+
+	r := hotBackupReconcilerWithCRs(h, hb)
+	c := clock.NewFakeClock(time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC))
+	r.clock = c
+	result, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: n})
+	Expect(err).Should(BeNil())
+	Expect(result.RequeueAfter).Should(Equal(pendingRequeueInterval))
+	Expect(r.Client.Get(context.TODO(), n, hb)).Should(Succeed())
+	Expect(hb.Status.State).Should(Equal(hazelcastv1alpha1.HotBackupPending))
+	Expect(hb.Status.PendingSince.Time).Should(BeTemporally("==", c.Now()))
+
+	// still within the timeout
+	c.Step(30 * time.Second)
+	_, err = r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: n})
+	Expect(err).Should(BeNil())
+	Expect(r.Client.Get(context.TODO(), n, hb)).Should(Succeed())
+	Expect(hb.Status.State).Should(Equal(hazelcastv1alpha1.HotBackupPending))
+
+	c.Step(time.Minute)
+	_, err = r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: n})
+	Expect(errors.Is(err, errPendingTimeout)).Should(BeTrue())
+	Expect(r.Client.Get(context.TODO(), n, hb)).Should(Succeed())
+	Expect(hb.Status.State).Should(Equal(hazelcastv1alpha1.HotBackupFailure))
+	Expect(hb.Status.Message).Should(ContainSubstring("Waiting for Hazelcast CR to be ready"))
+}
+
+func TestHotBackupReconciler_shouldReportQueuedRunPendingAndTimeOut(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	hzName := types.NamespacedName{Name: "hazelcast", Namespace: "default"}
+	n := types.NamespacedName{Name: "daily", Namespace: "default"}
+	h := &hazelcastv1alpha1.Hazelcast{
+		ObjectMeta: metav1.ObjectMeta{Name: hzName.Name, Namespace: hzName.Namespace},
+		Status:     hazelcastv1alpha1.HazelcastStatus{Phase: hazelcastv1alpha1.Running},
+	}
+	hb := &hazelcastv1alpha1.HotBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: n.Name, Namespace: n.Namespace},
+		Spec: hazelcastv1alpha1.HotBackupSpec{
+			HazelcastResourceName: hzName.Name,
+			PendingTimeout:        &metav1.Duration{Duration: 50 * time.Millisecond},
+		},
+	}
+
+	r := hotBackupReconcilerWithCRs(h, hb)
+	release, err := r.acquireCluster(context.TODO(), hzName, types.NamespacedName{Name: "manual", Namespace: "default"}, func(types.NamespacedName) {})
+	Expect(err).Should(BeNil())
+	defer release()
+
+	_, err = r.startBackup(context.TODO(), n, hzName, hazelcastv1alpha1.HotBackupTriggerManual, r.Log)
+	Expect(errors.Is(err, errPendingTimeout)).Should(BeTrue())
+	Expect(r.Client.Get(context.TODO(), n, hb)).Should(Succeed())
+	Expect(hb.Status.State).Should(Equal(hazelcastv1alpha1.HotBackupFailure))
+	// the run was Pending while it waited
+	Expect(hb.Status.PendingSince).ShouldNot(BeNil())
+	Expect(hb.Status.Message).Should(ContainSubstring("Waiting for the backup of HotBackup manual of Hazelcast hazelcast to finish"))
+}
+
+func TestHotBackupReconciler_shouldKeepBackupsLocalWhenExternalBackupsDisabled(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	h := &hazelcastv1alpha1.Hazelcast{
+		Spec: hazelcastv1alpha1.HazelcastSpec{
+			Persistence: &hazelcastv1alpha1.HazelcastPersistenceConfiguration{
+				BaseDir:    "/data/hot-restart",
+				BackupType: hazelcastv1alpha1.External,
+			},
+		},
+	}
+	r := hotBackupReconcilerWithCRs()
+	Expect(r.isExternal(h)).Should(BeTrue())
+
+	r.disableExternalBackups = true
+	Expect(r.isExternal(h)).Should(BeFalse())
+}
+
+func TestHotBackupReconciler_shouldFailInterruptedBackupAfterTimeout(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	n := types.NamespacedName{Name: "interrupted", Namespace: "default"}
+	started := metav1.NewTime(time.Now().Add(-2 * interruptedBackupTimeout))
+	hb := &hazelcastv1alpha1.HotBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: n.Name, Namespace: n.Namespace},
+		Spec:       hazelcastv1alpha1.HotBackupSpec{HazelcastResourceName: "hazelcast"},
+		Status: hazelcastv1alpha1.HotBackupStatus{
+			State:        hazelcastv1alpha1.HotBackupInProgress,
+			PendingSince: &started,
+		},
+	}
+	finished := &hazelcastv1alpha1.HotBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: "finished", Namespace: n.Namespace},
+		Spec:       hazelcastv1alpha1.HotBackupSpec{HazelcastResourceName: "hazelcast"},
+		Status:     hazelcastv1alpha1.HotBackupStatus{State: hazelcastv1alpha1.HotBackupSuccess},
+	}
+	r := hotBackupReconcilerWithCRs(hb, finished)
+	r.recoverInterruptedBackups(context.TODO())
+
+	Eventually(func() hazelcastv1alpha1.HotBackupState {
+		_ = r.Client.Get(context.TODO(), n, hb)
+		return hb.Status.State
+	}, 2*time.Second, 100*time.Millisecond).Should(Equal(hazelcastv1alpha1.HotBackupFailure))
+	Expect(hb.Status.Message).Should(ContainSubstring(errBackupInterrupted.Error()))
+	Eventually(func() bool { return r.checkBackup(n) }, 2*time.Second, 100*time.Millisecond).Should(BeFalse())
+
+	Expect(r.Client.Get(context.TODO(), types.NamespacedName{Name: "finished", Namespace: n.Namespace}, finished)).Should(Succeed())
+	Expect(finished.Status.State).Should(Equal(hazelcastv1alpha1.HotBackupSuccess))
+}
+
+func TestHotBackupReconciler_shouldReportStaleBackup(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	n := types.NamespacedName{Name: "hazelcast", Namespace: "default"}
+	now := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	lastSuccess := metav1.NewTime(now.Add(-30 * time.Minute))
+	hb := &hazelcastv1alpha1.HotBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: n.Name, Namespace: n.Namespace},
+		Spec: hazelcastv1alpha1.HotBackupSpec{
+			HazelcastResourceName: n.Name,
+			FreshnessSLA:          &metav1.Duration{Duration: time.Hour},
+		},
+		Status: hazelcastv1alpha1.HotBackupStatus{
+			State:           hazelcastv1alpha1.HotBackupSuccess,
+			LastSuccessTime: &lastSuccess,
+		},
+	}
+	c := clock.NewFakeClock(now)
+	r := hotBackupReconcilerWithCRs(hb)
+	r.clock = c
+
+	fresh := func() metav1.Condition {
+		hb := &hazelcastv1alpha1.HotBackup{}
+		Expect(r.Get(context.TODO(), n, hb)).Should(Succeed())
+		Expect(hb.Status.Conditions).Should(HaveLen(1))
+		return hb.Status.Conditions[0]
+	}
+
+	r.checkFreshness(context.TODO())
+	Expect(fresh().Status).Should(Equal(metav1.ConditionTrue))
+
+	c.Step(time.Hour)
+	r.checkFreshness(context.TODO())
+	Expect(fresh().Status).Should(Equal(metav1.ConditionFalse))
+	Expect(fresh().Reason).Should(Equal("SLAExceeded"))
+}
+
+func TestValidateBucketSecret_acceptsS3Role(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	s := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "br-secret-s3"},
+		Data: map[string][]byte{
+			"region":   []byte("us-east-1"),
+			"role-arn": []byte("arn:aws:iam::123456789012:role/backup"),
+		},
+	}
+	Expect(validation.ValidateBucketSecret("s3://backup", s)).Should(Succeed())
+
+	delete(s.Data, "region")
+	Expect(validation.ValidateBucketSecret("s3://backup", s)).Should(MatchError("secret br-secret-s3 missing key region"))
+}
+
+func fail(t *testing.T) func(message string, callerSkip ...int) {
+	return func(message string, callerSkip ...int) {
+		t.Errorf(message)
+	}
+}
+
+func hotBackupReconcilerWithCRs(initObjs ...client.Object) HotBackupReconciler {
+	return HotBackupReconciler{
+		Client:   fakeClient(initObjs...),
+		Log:      ctrl.Log.WithName("test").WithName("Hazelcast"),
+		cron:     cron.New(),
+		clock:    clock.RealClock{},
+		parser:   NewScheduleParser(false),
+		recorder: &record.FakeRecorder{},
+		backup:   make(map[types.NamespacedName]struct{}),
+	}
+}
+
+func TestHotBackupReconciler_shouldReportBackupOnHazelcast(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	hzName := types.NamespacedName{Name: "hazelcast", Namespace: "default"}
+	h := &hazelcastv1alpha1.Hazelcast{
+		ObjectMeta: metav1.ObjectMeta{Name: hzName.Name, Namespace: hzName.Namespace},
+	}
+	hotBackup := func(name string) *hazelcastv1alpha1.HotBackup {
+		return &hazelcastv1alpha1.HotBackup{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: hzName.Namespace},
+			Spec:       hazelcastv1alpha1.HotBackupSpec{HazelcastResourceName: hzName.Name},
+		}
+	}
+	r := hotBackupReconcilerWithCRs(h, hotBackup("daily"), hotBackup("adhoc"))
+	backupStatus := func() *hazelcastv1alpha1.HazelcastBackupStatus {
+		h := &hazelcastv1alpha1.Hazelcast{}
+		Expect(r.Get(context.TODO(), hzName, h)).Should(Succeed())
+		return h.Status.Backup
+	}
+
+	_, err := r.updateStatus(context.TODO(), types.NamespacedName{Name: "daily", Namespace: hzName.Namespace},
+		hbWithStatus(hazelcastv1alpha1.HotBackupSuccess))
+	Expect(err).Should(BeNil())
+	s := backupStatus()
+	Expect(s.HotBackup).Should(Equal("daily"))
+	Expect(s.State).Should(Equal(hazelcastv1alpha1.HotBackupSuccess))
+	Expect(s.LastSuccessTime).ShouldNot(BeNil())
+
+	// the failure of another HotBackup keeps the last success of the cluster
+	_, _ = r.updateStatus(context.TODO(), types.NamespacedName{Name: "adhoc", Namespace: hzName.Namespace},
+		failedHbStatus(errors.New("upload failed")))
+	Expect(backupStatus()).Should(Equal(&hazelcastv1alpha1.HazelcastBackupStatus{
+		HotBackup:       "adhoc",
+		State:           hazelcastv1alpha1.HotBackupFailure,
+		Message:         "upload failed",
+		LastSuccessTime: s.LastSuccessTime,
+	}))
+}
+
+func TestFailoverConfig(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	primary := &upload.Config{MemberAddress: "10.0.0.1:5701", BucketURI: "s3://backup-eu", SecretName: "backup"}
+
+	c := failoverConfig(primary, &hazelcastv1alpha1.BucketFailover{BucketURI: "s3://backup-us"})
+	Expect(c.BucketURI).Should(Equal("s3://backup-us"))
+	Expect(c.SecretName).Should(Equal("backup"))
+	Expect(c.MemberAddress).Should(Equal(primary.MemberAddress))
+	Expect(primary.BucketURI).Should(Equal("s3://backup-eu"))
+
+	c = failoverConfig(primary, &hazelcastv1alpha1.BucketFailover{BucketURI: "s3://backup-us", Secret: "backup-us"})
+	Expect(c.SecretName).Should(Equal("backup-us"))
+
+	Expect(failedOverMembers([]hazelcastv1alpha1.HotBackupMemberStatus{
+		{Address: "10.0.0.1:5701", FailedOver: true},
+		{Address: "10.0.0.2:5701"},
+		{Address: "10.0.0.3:5701", FailedOver: true, Abandoned: true},
+	})).Should(Equal([]string{"10.0.0.1:5701"}))
+}
+
+func TestHotBackupUpdates(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	old := &hazelcastv1alpha1.HotBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: "hb", Namespace: "default", Generation: 1},
+		Spec:       hazelcastv1alpha1.HotBackupSpec{HazelcastResourceName: "hazelcast", Schedule: "@daily"},
+	}
+	p := hotBackupUpdates()
+	update := func(mutate func(hb *hazelcastv1alpha1.HotBackup)) bool {
+		hb := old.DeepCopy()
+		mutate(hb)
+		return p.Update(event.UpdateEvent{ObjectOld: old, ObjectNew: hb})
+	}
+
+	Expect(update(func(hb *hazelcastv1alpha1.HotBackup) {
+		hb.Status.State = hazelcastv1alpha1.HotBackupInProgress
+	})).Should(BeFalse())
+	Expect(update(func(hb *hazelcastv1alpha1.HotBackup) {
+		hb.Annotations = map[string]string{naming.LastSuccessfulSpecAnnotation: "{}"}
+	})).Should(BeFalse())
+	Expect(update(func(hb *hazelcastv1alpha1.HotBackup) {
+		hb.Spec.Schedule = "@hourly"
+		hb.Generation++
+	})).Should(BeTrue())
+	Expect(update(func(hb *hazelcastv1alpha1.HotBackup) {
+		hb.Annotations = map[string]string{"example.com/trigger": "now"}
+	})).Should(BeTrue())
+	Expect(update(func(hb *hazelcastv1alpha1.HotBackup) {
+		now := metav1.Now()
+		hb.DeletionTimestamp = &now
+	})).Should(BeTrue())
+	Expect(p.Create(event.CreateEvent{Object: old})).Should(BeTrue())
+}
+
+func TestDeltaBase(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	now := metav1.Now()
+	hb := &hazelcastv1alpha1.HotBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{naming.LastSuccessfulSpecAnnotation: `{"bucketURI":"s3://backup","delta":true}`},
+		},
+		Spec:   hazelcastv1alpha1.HotBackupSpec{BucketURI: "s3://backup", Delta: true},
+		Status: hazelcastv1alpha1.HotBackupStatus{BackupFolder: "hazelcast/2022-01-01-00-00-00", LastSuccessTime: &now},
+	}
+	Expect(deltaBase(hb)).Should(Equal("hazelcast/2022-01-01-00-00-00"))
+
+	// the base backup is in another bucket
+	hb.Spec.BucketURI = "s3://other"
+	Expect(deltaBase(hb)).Should(BeEmpty())
+
+	hb.Spec.BucketURI = "s3://backup"
+	hb.Spec.Delta = false
+	Expect(deltaBase(hb)).Should(BeEmpty())
+
+	// the first backup is a full one
+	hb.Spec.Delta = true
+	hb.Status = hazelcastv1alpha1.HotBackupStatus{}
+	Expect(deltaBase(hb)).Should(BeEmpty())
+}
+
+func TestNewRunID(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	hb := &hazelcastv1alpha1.HotBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{naming.RunIDAnnotation: "trace-1234"},
+		},
+	}
+	Expect(newRunID(hb)).Should(Equal("trace-1234"))
+
+	// each scheduled run gets its own ID
+	hb.Spec.Schedule = "@daily"
+	first := newRunID(hb)
+	Expect(first).ShouldNot(Equal("trace-1234"))
+	Expect(newRunID(hb)).ShouldNot(Equal(first))
+	Expect(runAnnotations(first)).Should(Equal(map[string]string{naming.RunIDAnnotation: first}))
+}
+
+func TestTriggerCause(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	hb := &hazelcastv1alpha1.HotBackup{Spec: hazelcastv1alpha1.HotBackupSpec{Schedule: "@daily"}}
+	Expect(appliedTriggerCause(hb)).Should(Equal(hazelcastv1alpha1.HotBackupTriggerManual))
+	Expect(triggerSource(hb, hazelcastv1alpha1.HotBackupTriggerManual)).Should(BeEmpty())
+	Expect(triggerSource(hb, hazelcastv1alpha1.HotBackupTriggerScheduled)).Should(Equal("@daily"))
+
+	hb = &hazelcastv1alpha1.HotBackup{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{naming.HotBackupTriggerLabel: "nightly"}},
+	}
+	Expect(appliedTriggerCause(hb)).Should(Equal(hazelcastv1alpha1.HotBackupTriggerTrigger))
+	Expect(triggerSource(hb, hazelcastv1alpha1.HotBackupTriggerTrigger)).Should(Equal("nightly"))
+
+	hb.Status.TriggerCause = hazelcastv1alpha1.HotBackupTriggerTrigger
+	Expect(auditRecord(hb).TriggeredBy).Should(Equal("trigger"))
+}
+
+func TestHotBackupReconciler_shouldNotifyOnceForEachRun(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	var delivered int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&delivered, 1)
+	}))
+	defer ts.Close()
+
+	n := types.NamespacedName{Name: "hb", Namespace: "default"}
+	hb := &hazelcastv1alpha1.HotBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: n.Name, Namespace: n.Namespace},
+		Spec: hazelcastv1alpha1.HotBackupSpec{
+			HazelcastResourceName: "hazelcast",
+			Notification:          &hazelcastv1alpha1.HotBackupNotification{FailureURL: ts.URL},
+		},
+	}
+	r := hotBackupReconcilerWithCRs(hb)
+	failRun := func(runID string) {
+		_, _ = r.updateStatus(context.Background(), n, hbWithStatus(hazelcastv1alpha1.HotBackupInProgress).withRunID(runID))
+		// the failure is requeued and updated again
+		for i := 0; i < 3; i++ {
+			_, _ = r.updateStatus(context.Background(), n, failedHbStatus(errors.New("upload failed")))
+		}
+	}
+
+	failRun("run-1")
+	Eventually(func() int32 { return atomic.LoadInt32(&delivered) }, 2*time.Second, 50*time.Millisecond).Should(Equal(int32(1)))
+	Consistently(func() int32 { return atomic.LoadInt32(&delivered) }, 300*time.Millisecond, 50*time.Millisecond).Should(Equal(int32(1)))
+
+	failRun("run-2")
+	Eventually(func() int32 { return atomic.LoadInt32(&delivered) }, 2*time.Second, 50*time.Millisecond).Should(Equal(int32(2)))
+	Consistently(func() int32 { return atomic.LoadInt32(&delivered) }, 300*time.Millisecond, 50*time.Millisecond).Should(Equal(int32(2)))
+}
+
+// countingSink counts the audit records of each run
+type countingSink struct {
+	mu   sync.Mutex
+	runs map[string]int
+}
+
+func (s *countingSink) Write(_ context.Context, r *audit.Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.runs[r.RunID]++
+	return nil
+}
+
+func TestHotBackupReconciler_shouldAuditOnceForEachRun(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	sink := &countingSink{runs: map[string]int{}}
+	audit.RegisterSink("counting", func(*url.URL) (audit.Sink, error) { return sink, nil })
+	Expect(audit.SetSink("counting://")).Should(Succeed())
+	defer audit.SetSink("") //nolint:errcheck
+
+	n := types.NamespacedName{Name: "hb", Namespace: "default"}
+	hb := &hazelcastv1alpha1.HotBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: n.Name, Namespace: n.Namespace},
+		Spec:       hazelcastv1alpha1.HotBackupSpec{HazelcastResourceName: "hazelcast"},
+	}
+	r := hotBackupReconcilerWithCRs(hb)
+	for _, runID := range []string{"run-1", "run-2"} {
+		_, _ = r.updateStatus(context.Background(), n, hbWithStatus(hazelcastv1alpha1.HotBackupInProgress).withRunID(runID))
+		for i := 0; i < 3; i++ {
+			_, _ = r.updateStatus(context.Background(), n, failedHbStatus(errors.New("upload failed")))
+		}
+	}
+	Expect(sink.runs).Should(Equal(map[string]int{"run-1": 1, "run-2": 1}))
+}
+
+// fakeCompletionStore keeps the completion records in memory, it fails the writes with err
+type fakeCompletionStore struct {
+	mu       sync.Mutex
+	entries  map[string]string
+	messages []string
+	err      error
+}
+
+func (s *fakeCompletionStore) Set(_ context.Context, mapName, key, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.entries[mapName+"/"+key] = value
+	return nil
+}
+
+func (s *fakeCompletionStore) Publish(_ context.Context, topicName, message string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.messages = append(s.messages, topicName+"/"+message)
+	return nil
+}
+
+func TestWriteCompletion(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	name := types.NamespacedName{Name: "not-connected", Namespace: "default"}
+	Expect(writeCompletion(context.Background(), name, "backups", "", "hb", "{}")).Should(MatchError(ContainSubstring("not connected")))
+
+	defer func(f func(types.NamespacedName) (completionStore, error)) { newCompletionStore = f }(newCompletionStore)
+	store := &fakeCompletionStore{entries: map[string]string{}}
+	newCompletionStore = func(types.NamespacedName) (completionStore, error) { return store, nil }
+
+	Expect(writeCompletion(context.Background(), name, "backups", "finished", "hb", "{}")).Should(Succeed())
+	Expect(store.entries).Should(Equal(map[string]string{"backups/hb": "{}"}))
+	Expect(store.messages).Should(Equal([]string{"finished/{}"}))
+
+	store.err = errors.New("cluster is passive")
+	Expect(writeCompletion(context.Background(), name, "", "finished", "hb", "{}")).Should(MatchError(ContainSubstring("topic finished")))
+}
+
+func TestHotBackupReconciler_shouldNotFailBackupWhenCompletionIsNotPublished(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	defer func(f func(types.NamespacedName) (completionStore, error)) { newCompletionStore = f }(newCompletionStore)
+	newCompletionStore = func(types.NamespacedName) (completionStore, error) {
+		return &fakeCompletionStore{err: errors.New("cluster is passive")}, nil
+	}
+
+	n := types.NamespacedName{Name: "hb", Namespace: "default"}
+	hb := &hazelcastv1alpha1.HotBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: n.Name, Namespace: n.Namespace},
+		Spec:       hazelcastv1alpha1.HotBackupSpec{HazelcastResourceName: "hazelcast", CompletionTopic: "finished"},
+	}
+	r := hotBackupReconcilerWithCRs(hb)
+	recorder := record.NewFakeRecorder(10)
+	r.recorder = recorder
+
+	_, err := r.updateStatus(context.Background(), n, hbWithStatus(hazelcastv1alpha1.HotBackupSuccess))
+	Expect(err).Should(BeNil())
+	Eventually(recorder.Events, 2*time.Second).Should(Receive(ContainSubstring("CompletionNotPublished")))
+
+	got := &hazelcastv1alpha1.HotBackup{}
+	Expect(r.Client.Get(context.Background(), n, got)).Should(Succeed())
+	Expect(got.Status.State).Should(Equal(hazelcastv1alpha1.HotBackupSuccess))
+}
+
+func TestHotBackupReconciler_effectiveConfig(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	r := &HotBackupReconciler{hazelcastFetchTimeout: 30 * time.Second}
+	hb := &hazelcastv1alpha1.HotBackup{Spec: hazelcastv1alpha1.HotBackupSpec{
+		BucketURI:        "s3://bucket",
+		CompressionLevel: 6,
+		UploadMode:       hazelcastv1alpha1.UploadSequential,
+	}}
+	hz := &hazelcastv1alpha1.Hazelcast{Spec: hazelcastv1alpha1.HazelcastSpec{
+		Persistence: &hazelcastv1alpha1.HazelcastPersistenceConfiguration{BaseDir: "/data/hot-restart", MaxConcurrentUploads: 3},
+	}}
+
+	Expect(r.effectiveConfig(hb, hz, true)).Should(Equal(&hazelcastv1alpha1.HotBackupEffectiveConfig{
+		BucketURI:                "s3://bucket",
+		BackupPath:               "/data/hot-restart",
+		Compression:              hazelcastv1alpha1.CompressionGzip,
+		CompressionLevel:         6,
+		UploadMode:               hazelcastv1alpha1.UploadSequential,
+		MaxConcurrentUploads:     1,
+		FlushHealthCheckInterval: &metav1.Duration{Duration: defaultFlushHealthCheckInterval},
+		HazelcastFetchTimeout:    &metav1.Duration{Duration: 30 * time.Second},
+	}))
+
+	hb.Spec.BackupPathOverride = "/mnt/backups"
+	Expect(r.effectiveConfig(hb, hz, true).BackupPath).Should(Equal("/mnt/backups"))
+
+	// the backups kept on the members have no upload settings
+	Expect(r.effectiveConfig(hb, hz, false)).Should(Equal(&hazelcastv1alpha1.HotBackupEffectiveConfig{
+		FlushHealthCheckInterval: &metav1.Duration{Duration: defaultFlushHealthCheckInterval},
+		HazelcastFetchTimeout:    &metav1.Duration{Duration: 30 * time.Second},
+	}))
+}
+
+func TestHotBackupReconciler_cancelCurrentRun(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	name := types.NamespacedName{Name: "daily", Namespace: "default"}
+	hb := &hazelcastv1alpha1.HotBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name.Name,
+			Namespace:   name.Namespace,
+			Annotations: map[string]string{naming.CancelCurrentAnnotation: ""},
+		},
+		Spec:   hazelcastv1alpha1.HotBackupSpec{HazelcastResourceName: "hazelcast", Schedule: "@daily"},
+		Status: hazelcastv1alpha1.HotBackupStatus{State: hazelcastv1alpha1.HotBackupInProgress},
+	}
+	r := hotBackupReconcilerWithCRs(hb)
+	runCtx, cancel := context.WithCancel(context.Background())
+	defer r.registerRun(name, cancel)()
+
+	_, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: name})
+	Expect(err).Should(BeNil())
+	Expect(runCanceled(context.Background(), runCtx)).Should(BeTrue())
+
+	got := &hazelcastv1alpha1.HotBackup{}
+	Expect(r.Client.Get(context.Background(), name, got)).Should(Succeed())
+	Expect(got.Annotations).ShouldNot(HaveKey(naming.CancelCurrentAnnotation))
+	Expect(failureReason(errRunCanceled)).Should(Equal(hazelcastv1alpha1.HotBackupReasonCanceled))
+}
+
+func TestHotBackupReconciler_cancelBackup(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	running := types.NamespacedName{Name: "running", Namespace: "default"}
+	pending := types.NamespacedName{Name: "pending", Namespace: "default"}
+	newHotBackup := func(name types.NamespacedName, state hazelcastv1alpha1.HotBackupState) *hazelcastv1alpha1.HotBackup {
+		return &hazelcastv1alpha1.HotBackup{
+			ObjectMeta: metav1.ObjectMeta{Name: name.Name, Namespace: name.Namespace},
+			Spec:       hazelcastv1alpha1.HotBackupSpec{HazelcastResourceName: "hazelcast", Cancel: true},
+			Status:     hazelcastv1alpha1.HotBackupStatus{State: state},
+		}
+	}
+	r := hotBackupReconcilerWithCRs(newHotBackup(running, hazelcastv1alpha1.HotBackupInProgress),
+		newHotBackup(pending, hazelcastv1alpha1.HotBackupPending))
+	runCtx, cancel := context.WithCancel(context.Background())
+	defer r.registerRun(running, cancel)()
+
+	_, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: running})
+	Expect(err).Should(BeNil())
+	Expect(runCanceled(context.Background(), runCtx)).Should(BeTrue())
+	Expect(failedHbStatus(errRunCanceled).status).Should(Equal(hazelcastv1alpha1.HotBackupCanceled))
+
+	// the HotBackup not started yet is canceled at once
+	_, err = r.Reconcile(context.Background(), reconcile.Request{NamespacedName: pending})
+	Expect(err).Should(BeNil())
+	got := &hazelcastv1alpha1.HotBackup{}
+	Expect(r.Client.Get(context.Background(), pending, got)).Should(Succeed())
+	Expect(got.Status.State).Should(Equal(hazelcastv1alpha1.HotBackupCanceled))
+	Expect(got.Status.Reason).Should(Equal(hazelcastv1alpha1.HotBackupReasonCanceled))
+}
+
+// flakyClient fails the first gets with the error
+type flakyClient struct {
+	client.Client
+	failures int
+	err      error
+}
+
+func (c *flakyClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	if c.failures > 0 {
+		c.failures--
+		return c.err
+	}
+	return c.Client.Get(ctx, key, obj)
+}
+
+func TestHotBackupReconciler_getHazelcastRetriesTransientErrors(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	defer func(b wait.Backoff) { hazelcastFetchBackoff = b }(hazelcastFetchBackoff)
+	hazelcastFetchBackoff = wait.Backoff{Duration: time.Millisecond, Factor: 1, Steps: 100}
+
+	n := types.NamespacedName{Name: "hazelcast", Namespace: "default"}
+	h := &hazelcastv1alpha1.Hazelcast{ObjectMeta: metav1.ObjectMeta{Name: n.Name, Namespace: n.Namespace}}
+	r := hotBackupReconcilerWithCRs(h)
+	c := &flakyClient{Client: r.Client, failures: 2, err: apiErrors.NewServiceUnavailable("etcd leader changed")}
+	r.Client = c
+	r.hazelcastFetchTimeout = time.Second
+
+	got, err := r.getHazelcast(context.Background(), n, ctrl.Log)
+	Expect(err).Should(BeNil())
+	Expect(got.Name).Should(Equal(n.Name))
+	Expect(c.failures).Should(Equal(0))
+
+	// the retries are bounded by the timeout
+	c.failures = 1000
+	r.hazelcastFetchTimeout = 20 * time.Millisecond
+	_, err = r.getHazelcast(context.Background(), n, ctrl.Log)
+	Expect(apiErrors.IsServiceUnavailable(err)).Should(BeTrue())
+
+	// a missing Hazelcast CR is not retried
+	c.failures = 0
+	_, err = r.getHazelcast(context.Background(), types.NamespacedName{Name: "missing", Namespace: n.Namespace}, ctrl.Log)
+	Expect(apiErrors.IsNotFound(err)).Should(BeTrue())
+}
+
+func TestHotBackupReconciler_checkConflictingSchedule(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	// the API server keeps the creation timestamps at second precision
+	created := metav1.NewTime(time.Now().Truncate(time.Second))
+	hotBackup := func(name, hazelcast, schedule string) *hazelcastv1alpha1.HotBackup {
+		return &hazelcastv1alpha1.HotBackup{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", CreationTimestamp: created},
+			Spec:       hazelcastv1alpha1.HotBackupSpec{HazelcastResourceName: hazelcast, Schedule: schedule},
+		}
+	}
+	daily := hotBackup("daily", "hazelcast", "@daily")
+	hourly := hotBackup("hourly", "hazelcast", "@hourly")
+	other := hotBackup("other", "other", "@hourly")
+	adhoc := hotBackup("adhoc", "hazelcast", "")
+	r := hotBackupReconcilerWithCRs(daily, hourly, other, adhoc)
+
+	// the oldest scheduled HotBackup of the cluster keeps its schedule, the names break the ties
+	Expect(r.checkConflictingSchedule(context.TODO(), daily)).Should(Succeed())
+	err := r.checkConflictingSchedule(context.TODO(), hourly)
+	Expect(errors.Is(err, errConflictingSchedule)).Should(BeTrue())
+	Expect(err.Error()).Should(ContainSubstring("HotBackup daily"))
+	Expect(r.checkConflictingSchedule(context.TODO(), other)).Should(Succeed())
+	Expect(r.checkConflictingSchedule(context.TODO(), adhoc)).Should(Succeed())
+
+	hourly.Annotations = map[string]string{naming.AllowConcurrentSchedulesAnnotation: "true"}
+	Expect(r.checkConflictingSchedule(context.TODO(), hourly)).Should(Succeed())
+
+	verify := hotBackup("verify", "hazelcast", "@hourly")
+	verify.Spec.Verify = &hazelcastv1alpha1.HotBackupVerifyConfiguration{BackupFolder: "hazelcast/2022-06-02"}
+	Expect(r.checkConflictingSchedule(context.TODO(), verify)).Should(Succeed())
+}
+
+func TestHotBackupReconciler_acquireCluster(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	r := hotBackupReconcilerWithCRs()
+	cluster := types.NamespacedName{Name: "hazelcast", Namespace: "default"}
+	manual := types.NamespacedName{Name: "manual", Namespace: "default"}
+	daily := types.NamespacedName{Name: "daily", Namespace: "default"}
+	notQueued := func(holder types.NamespacedName) { t.Errorf("backup was queued behind %v", holder) }
+
+	release, err := r.acquireCluster(context.TODO(), cluster, manual, notQueued)
+	Expect(err).Should(BeNil())
+
+	// the backups of the other clusters are not serialized with it
+	releaseOther, err := r.acquireCluster(context.TODO(), types.NamespacedName{Name: "other", Namespace: "default"}, daily, notQueued)
+	Expect(err).Should(BeNil())
+	releaseOther()
+
+	queuedBehind := make(chan types.NamespacedName, 1)
+	acquired := make(chan func())
+	go func() {
+		release, err := r.acquireCluster(context.TODO(), cluster, daily, func(holder types.NamespacedName) { queuedBehind <- holder })
+		if err == nil {
+			acquired <- release
+		}
+	}()
+	Expect(<-queuedBehind).Should(Equal(manual))
+	Consistently(acquired, 50*time.Millisecond).ShouldNot(Receive())
+
+	release()
+	var releaseDaily func()
+	Eventually(acquired).Should(Receive(&releaseDaily))
+	releaseDaily()
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	release, err = r.acquireCluster(ctx, cluster, manual, notQueued)
+	Expect(err).Should(BeNil())
+	cancel()
+	_, err = r.acquireCluster(ctx, cluster, daily, func(types.NamespacedName) {})
+	Expect(errors.Is(err, context.Canceled)).Should(BeTrue())
+	release()
+}
+
+func Test_memberUploadSlots(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	hb := &hazelcastv1alpha1.HotBackup{}
+	hz := &hazelcastv1alpha1.Hazelcast{}
+	Expect(memberUploadSlots(hb, hz)).Should(BeNil())
+
+	hz.Spec.Persistence = &hazelcastv1alpha1.HazelcastPersistenceConfiguration{BaseDir: "/data/hot-restart"}
+	Expect(memberUploadSlots(hb, hz)).Should(BeNil())
+
+	hz.Spec.Persistence.MaxConcurrentUploads = 3
+	Expect(cap(memberUploadSlots(hb, hz))).Should(Equal(3))
+
+	// the sequential uploads run one at a time regardless of the capacity of the cluster
+	hb.Spec.UploadMode = hazelcastv1alpha1.UploadSequential
+	Expect(cap(memberUploadSlots(hb, hz))).Should(Equal(1))
+}
+
+func TestHotBackupReconciler_shouldReportSourceMemberCount(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	n := types.NamespacedName{Name: "hb", Namespace: "default"}
+	hb := &hazelcastv1alpha1.HotBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: n.Name, Namespace: n.Namespace},
+		Spec:       hazelcastv1alpha1.HotBackupSpec{HazelcastResourceName: "hazelcast"},
+	}
+	r := hotBackupReconcilerWithCRs(hb)
+
+	_, err := r.updateStatus(context.TODO(), n, hbWithStatus(hazelcastv1alpha1.HotBackupInProgress).withSourceMemberCount(3))
+	Expect(err).Should(BeNil())
+	Expect(r.Client.Get(context.TODO(), n, hb)).Should(Succeed())
+	Expect(hb.Status.SourceMemberCount).Should(Equal(int32(3)))
+
+	// the later updates of the run keep the count of its start
+	_, err = r.updateStatus(context.TODO(), n, hbWithStatus(hazelcastv1alpha1.HotBackupSuccess))
+	Expect(err).Should(BeNil())
+	Expect(r.Client.Get(context.TODO(), n, hb)).Should(Succeed())
+	Expect(hb.Status.SourceMemberCount).Should(Equal(int32(3)))
+}
+
+func TestHotBackupReconciler_shouldReconcileConcurrently(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	var objs []client.Object
+	var names []types.NamespacedName
+	for i := 0; i < 8; i++ {
+		n := types.NamespacedName{Name: fmt.Sprintf("hb-%d", i), Namespace: "default"}
+		names = append(names, n)
+		objs = append(objs, &hazelcastv1alpha1.HotBackup{
+			ObjectMeta: metav1.ObjectMeta{Name: n.Name, Namespace: n.Namespace},
+			Spec:       hazelcastv1alpha1.HotBackupSpec{HazelcastResourceName: "missing"},
+		})
+	}
+	r := hotBackupReconcilerWithCRs(objs...)
+	r.maxConcurrentReconciles = len(names)
+
+	var wg sync.WaitGroup
+	for _, n := range names {
+		n := n
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: n})
+		}()
+	}
+	wg.Wait()
+
+	for _, n := range names {
+		hb := &hazelcastv1alpha1.HotBackup{}
+		Expect(r.Client.Get(context.TODO(), n, hb)).Should(Succeed())
+		Expect(hb.Status.State).Should(Equal(hazelcastv1alpha1.HotBackupFailure))
+		Expect(r.checkBackup(n)).Should(BeFalse())
 	}
 }
@@ -0,0 +1,85 @@
+package hazelcast
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	hazelcastv1alpha1 "github.com/hazelcast/hazelcast-platform-operator/api/v1alpha1"
+)
+
+// dependenciesRequeueInterval is the time after the HotBackup waiting for its dependencies is checked again
+const dependenciesRequeueInterval = 30 * time.Second
+
+var errDependencyCycle = errors.New("HotBackup dependency cycle")
+
+// checkDependencyCycle returns an error if the HotBackup depends on itself directly or through other HotBackups.
+func (r *HotBackupReconciler) checkDependencyCycle(ctx context.Context, hb *hazelcastv1alpha1.HotBackup) error {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[string]int)
+	var path []string
+
+	var visit func(name string, deps []string) error
+	visit = func(name string, deps []string) error {
+		state[name] = visiting
+		path = append(path, name)
+		for _, d := range deps {
+			switch state[d] {
+			case visiting:
+				return fmt.Errorf("%w: %s", errDependencyCycle, strings.Join(append(path, d), " -> "))
+			case visited:
+				continue
+			}
+			dep := &hazelcastv1alpha1.HotBackup{}
+			if err := r.Get(ctx, types.NamespacedName{Name: d, Namespace: hb.Namespace}, dep); err != nil {
+				if apiErrors.IsNotFound(err) {
+					continue
+				}
+				return err
+			}
+			if err := visit(d, dep.Spec.DependsOn); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = visited
+		return nil
+	}
+	return visit(hb.Name, hb.Spec.DependsOn)
+}
+
+// pendingDependencies returns the dependencies of the HotBackup without a recent enough successful backup.
+func (r *HotBackupReconciler) pendingDependencies(ctx context.Context, hb *hazelcastv1alpha1.HotBackup, now time.Time) ([]string, error) {
+	var pending []string
+	for _, d := range hb.Spec.DependsOn {
+		dep := &hazelcastv1alpha1.HotBackup{}
+		if err := r.Get(ctx, types.NamespacedName{Name: d, Namespace: hb.Namespace}, dep); err != nil {
+			if apiErrors.IsNotFound(err) {
+				pending = append(pending, d+" (not found)")
+				continue
+			}
+			return nil, err
+		}
+		last := dep.Status.LastSuccessTime
+		switch {
+		case last == nil:
+			pending = append(pending, d+" (no successful backup)")
+		case hb.Spec.DependencyFreshness != nil && now.Sub(last.Time) > hb.Spec.DependencyFreshness.Duration:
+			pending = append(pending, d+" (last successful backup is too old)")
+		}
+	}
+	return pending, nil
+}
+
+func waitingForDependenciesStatus(pending []string) hotBackupOptionsBuilder {
+	return hbWithStatus(hazelcastv1alpha1.HotBackupNotStarted).
+		withMessage("Waiting for dependencies: " + strings.Join(pending, ", "))
+}
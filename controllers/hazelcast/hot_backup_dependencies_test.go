@@ -0,0 +1,59 @@
+package hazelcast
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	hazelcastv1alpha1 "github.com/hazelcast/hazelcast-platform-operator/api/v1alpha1"
+)
+
+func hotBackupDependingOn(name string, deps ...string) *hazelcastv1alpha1.HotBackup {
+	return &hazelcastv1alpha1.HotBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:       hazelcastv1alpha1.HotBackupSpec{DependsOn: deps},
+	}
+}
+
+func TestHotBackupReconciler_shouldDetectDependencyCycle(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	a := hotBackupDependingOn("a", "b")
+	b := hotBackupDependingOn("b", "c", "missing")
+	c := hotBackupDependingOn("c", "a")
+	d := hotBackupDependingOn("d", "b")
+
+	r := hotBackupReconcilerWithCRs(a, b, c)
+	err := r.checkDependencyCycle(context.TODO(), a)
+	Expect(errors.Is(err, errDependencyCycle)).Should(BeTrue())
+	Expect(err.Error()).Should(HaveSuffix("a -> b -> c -> a"))
+
+	c.Spec.DependsOn = nil
+	r = hotBackupReconcilerWithCRs(a, b, c, d)
+	Expect(r.checkDependencyCycle(context.TODO(), a)).Should(Succeed())
+	Expect(r.checkDependencyCycle(context.TODO(), d)).Should(Succeed())
+}
+
+func TestHotBackupReconciler_shouldWaitForFreshDependencies(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	now := time.Now()
+	fresh := hotBackupDependingOn("fresh")
+	fresh.Status.LastSuccessTime = &metav1.Time{Time: now.Add(-time.Minute)}
+	stale := hotBackupDependingOn("stale")
+	stale.Status.LastSuccessTime = &metav1.Time{Time: now.Add(-2 * time.Hour)}
+	never := hotBackupDependingOn("never")
+	hb := hotBackupDependingOn("hb", "fresh", "stale", "never", "missing")
+
+	r := hotBackupReconcilerWithCRs(fresh, stale, never, hb)
+	pending, err := r.pendingDependencies(context.TODO(), hb, now)
+	Expect(err).Should(BeNil())
+	Expect(pending).Should(Equal([]string{"never (no successful backup)", "missing (not found)"}))
+
+	hb.Spec.DependencyFreshness = &metav1.Duration{Duration: time.Hour}
+	pending, err = r.pendingDependencies(context.TODO(), hb, now)
+	Expect(err).Should(BeNil())
+	Expect(pending).Should(Equal([]string{"stale (last successful backup is too old)", "never (no successful backup)", "missing (not found)"}))
+}
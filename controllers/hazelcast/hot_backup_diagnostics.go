@@ -0,0 +1,142 @@
+package hazelcast
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"path"
+	"sort"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	hazelcastv1alpha1 "github.com/hazelcast/hazelcast-platform-operator/api/v1alpha1"
+	n "github.com/hazelcast/hazelcast-platform-operator/internal/naming"
+	"github.com/hazelcast/hazelcast-platform-operator/internal/upload"
+)
+
+const (
+	// diagnosticLogLines is the number of the recent log lines of each HotBackup kept for the diagnostic bundle
+	diagnosticLogLines = 500
+	// diagnosticsTimeout is the time the diagnostic bundle of a failed backup is uploaded within
+	diagnosticsTimeout = 5 * time.Minute
+)
+
+// diagnosticBundle is the state of the failed backup uploaded to the bucket for the support cases.
+type diagnosticBundle struct {
+	Time         time.Time                         `json:"time"`
+	HotBackup    hazelcastv1alpha1.HotBackupStatus `json:"hotBackup"`
+	Hazelcast    hazelcastv1alpha1.HazelcastStatus `json:"hazelcast"`
+	Events       []diagnosticEvent                 `json:"events,omitempty"`
+	OperatorLogs []string                          `json:"operatorLogs,omitempty"`
+}
+
+type diagnosticEvent struct {
+	Time    time.Time `json:"time"`
+	Type    string    `json:"type"`
+	Reason  string    `json:"reason"`
+	Message string    `json:"message"`
+	Count   int32     `json:"count,omitempty"`
+}
+
+// collectDiagnostics uploads the diagnostic bundle of the failed backup to its bucket in the background,
+// the outcome of the upload is recorded in an event of the HotBackup. It is called once for each run.
+func (r *HotBackupReconciler) collectDiagnostics(hb *hazelcastv1alpha1.HotBackup) {
+	if !hb.Spec.CollectDiagnosticsOnFailure || hb.Status.State != hazelcastv1alpha1.HotBackupFailure ||
+		hb.Spec.BucketURI == "" || r.disableExternalBackups {
+		return
+	}
+	logs := r.logs.Lines(types.NamespacedName{Name: hb.Name, Namespace: hb.Namespace})
+	go func() {
+		ctx, cancel := context.WithTimeout(upload.WithNamespace(context.Background(), hb.Namespace), diagnosticsTimeout)
+		defer cancel()
+
+		folder, err := r.uploadDiagnostics(ctx, hb, logs)
+		if err != nil {
+			r.recorder.AnnotatedEventf(hb, runAnnotations(hb.Status.RunID), corev1.EventTypeWarning, "DiagnosticsFailed", "Could not upload the diagnostic bundle: %v", err)
+			return
+		}
+		r.recorder.AnnotatedEventf(hb, runAnnotations(hb.Status.RunID), corev1.EventTypeNormal, "DiagnosticsCollected", "Uploaded the diagnostic bundle to %s", folder)
+	}()
+}
+
+// uploadDiagnostics makes the agents of the members upload their logs and the bundle of the failed backup to the bucket.
+// The bundle is uploaded once, by the first agent reachable. It returns the folder of the bundle in the bucket.
+func (r *HotBackupReconciler) uploadDiagnostics(ctx context.Context, hb *hazelcastv1alpha1.HotBackup, logs []string) (string, error) {
+	h := &hazelcastv1alpha1.Hazelcast{}
+	if err := r.Get(ctx, types.NamespacedName{Name: hb.Spec.HazelcastResourceName, Namespace: hb.Namespace}, h); err != nil {
+		return "", err
+	}
+	events, err := hotBackupEvents(ctx, r.Client, hb)
+	if err != nil {
+		r.Log.Error(err, "Could not read the events of the HotBackup for the diagnostic bundle", "hotBackup", hb.Name)
+	}
+	bundle := diagnosticBundle{
+		Time:         r.clock.Now().UTC(),
+		HotBackup:    hb.Status,
+		Hazelcast:    h.Status,
+		Events:       events,
+		OperatorLogs: logs,
+	}
+	data, err := json.Marshal(bundle)
+	if err != nil {
+		return "", err
+	}
+
+	folder := diagnosticsFolder(hb, bundle.Time)
+	config := &upload.Config{BucketURI: hb.Spec.BucketURI, SecretName: hb.Spec.Secret}
+	var uploaded bool
+	var lastErr error
+	for _, m := range h.Status.Members {
+		b := data
+		if uploaded {
+			b = nil
+		}
+		addr := net.JoinHostPort(m.Ip, strconv.Itoa(n.DefaultHzPort))
+		if err := upload.UploadDiagnostics(ctx, addr, config, folder, m.Uid, b); err != nil {
+			lastErr = fmt.Errorf("agent of member %s: %w", m.Ip, err)
+			continue
+		}
+		uploaded = true
+	}
+	if !uploaded {
+		if lastErr == nil {
+			lastErr = fmt.Errorf("Hazelcast %s has no members to upload the diagnostic bundle", h.Name)
+		}
+		return "", lastErr
+	}
+	return folder, nil
+}
+
+// hotBackupEvents returns the events of the HotBackup, the oldest first.
+func hotBackupEvents(ctx context.Context, c client.Client, hb *hazelcastv1alpha1.HotBackup) ([]diagnosticEvent, error) {
+	list := &corev1.EventList{}
+	if err := c.List(ctx, list, client.InNamespace(hb.Namespace)); err != nil {
+		return nil, err
+	}
+	var events []diagnosticEvent
+	for _, e := range list.Items {
+		if e.InvolvedObject.UID != hb.UID {
+			continue
+		}
+		events = append(events, diagnosticEvent{
+			Time:    e.LastTimestamp.Time,
+			Type:    e.Type,
+			Reason:  e.Reason,
+			Message: e.Message,
+			Count:   e.Count,
+		})
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Time.Before(events[j].Time) })
+	return events, nil
+}
+
+// diagnosticsFolder returns the folder of the diagnostic bundle of the backup failed at the given time,
+// the name of the HotBackup is encoded with its key encoding.
+func diagnosticsFolder(hb *hazelcastv1alpha1.HotBackup, t time.Time) string {
+	return path.Join(n.DiagnosticsPrefix, upload.EncodeKeySegment(hb.Name, string(hb.Spec.KeyEncoding)), t.Format("20060102-150405"))
+}
@@ -0,0 +1,93 @@
+package hazelcast
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+
+	hazelcastv1alpha1 "github.com/hazelcast/hazelcast-platform-operator/api/v1alpha1"
+	n "github.com/hazelcast/hazelcast-platform-operator/internal/naming"
+	"github.com/hazelcast/hazelcast-platform-operator/internal/upload"
+)
+
+// sizeEstimateInterval is the time between two refreshes of the estimated backup sizes
+const sizeEstimateInterval = 15 * time.Minute
+
+// estimateSizes refreshes the estimated size of the next backup of the HotBackups from the disk usage
+// of the persistence directories reported by the backup agents of the members.
+func (r *HotBackupReconciler) estimateSizes(ctx context.Context) {
+	logger := r.Log.WithName("size-estimate")
+
+	hbList := &hazelcastv1alpha1.HotBackupList{}
+	if err := r.List(ctx, hbList); err != nil {
+		logger.Error(err, "Could not list HotBackup resources")
+		return
+	}
+
+	// the HotBackups of the same cluster share the estimate
+	estimates := make(map[types.NamespacedName]*resource.Quantity)
+	for _, hb := range hbList.Items {
+		if hb.GetDeletionTimestamp() != nil {
+			continue
+		}
+		hzName := types.NamespacedName{Name: hb.Spec.HazelcastResourceName, Namespace: hb.Namespace}
+		size, ok := estimates[hzName]
+		if !ok {
+			size = r.estimateSize(ctx, hzName)
+			estimates[hzName] = size
+		}
+		if size == nil {
+			continue
+		}
+
+		name := types.NamespacedName{Name: hb.Name, Namespace: hb.Namespace}
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			hb := &hazelcastv1alpha1.HotBackup{}
+			if err := r.Get(ctx, name, hb); err != nil {
+				return err
+			}
+			if hb.Status.EstimatedSize != nil && hb.Status.EstimatedSize.Cmp(*size) == 0 {
+				return nil
+			}
+			now := metav1.NewTime(r.clock.Now())
+			hb.Status.EstimatedSize = size
+			hb.Status.EstimatedSizeTime = &now
+			return r.Status().Update(ctx, hb)
+		})
+		if err != nil {
+			logger.Error(err, "Could not update the estimated size of the HotBackup", "hotBackup", name)
+		}
+	}
+}
+
+// estimateSize returns the sum of the disk usage of the persistence directories of the members
+// without their local backups or nil if it cannot be estimated.
+func (r *HotBackupReconciler) estimateSize(ctx context.Context, name types.NamespacedName) *resource.Quantity {
+	h := &hazelcastv1alpha1.Hazelcast{}
+	if err := r.Get(ctx, name, h); err != nil {
+		return nil
+	}
+	// only the backup agents can read the disks of the members, the older ones cannot measure the directories
+	if !h.Spec.Persistence.IsExternal() || !agentSupportsFeatures(h) || h.Status.Phase != hazelcastv1alpha1.Running {
+		return nil
+	}
+	addresses := memberAddresses(name)
+	if len(addresses) == 0 {
+		return nil
+	}
+	ctx = upload.WithNamespace(ctx, name.Namespace)
+	var total int64
+	for _, address := range addresses {
+		bytes, err := upload.Footprint(ctx, address, h.Spec.Persistence.BaseDir, n.LocalBackupDir)
+		if err != nil {
+			r.Log.Error(err, "Could not get the disk usage of the member", "hazelcast", name, "address", address)
+			return nil
+		}
+		total += bytes
+	}
+	return resource.NewQuantity(total, resource.BinarySI)
+}
@@ -0,0 +1,93 @@
+package hazelcast
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+
+	hazelcastv1alpha1 "github.com/hazelcast/hazelcast-platform-operator/api/v1alpha1"
+)
+
+// hotBackupFreshCondition is True while the last successful backup is within the freshness SLA of the HotBackup
+const hotBackupFreshCondition = "BackupFresh"
+
+// freshnessCheckInterval is the time between two checks of the freshness of the HotBackups
+const freshnessCheckInterval = time.Minute
+
+// freshnessCondition returns the BackupFresh condition of the HotBackup with a freshness SLA at the given time.
+func freshnessCondition(hb *hazelcastv1alpha1.HotBackup, now time.Time) metav1.Condition {
+	c := metav1.Condition{
+		Type:               hotBackupFreshCondition,
+		ObservedGeneration: hb.Generation,
+		LastTransitionTime: metav1.NewTime(now),
+	}
+	sla := hb.Spec.FreshnessSLA.Duration
+	switch last := hb.Status.LastSuccessTime; {
+	case last == nil:
+		c.Status = metav1.ConditionFalse
+		c.Reason = "NoSuccessfulBackup"
+		c.Message = "HotBackup has not succeeded yet"
+	case now.Sub(last.Time) > sla:
+		c.Status = metav1.ConditionFalse
+		c.Reason = "SLAExceeded"
+		c.Message = fmt.Sprintf("Last successful backup is older than %s", sla)
+	default:
+		c.Status = metav1.ConditionTrue
+		c.Reason = "WithinSLA"
+		c.Message = fmt.Sprintf("Last successful backup is within %s", sla)
+	}
+	return c
+}
+
+// setFreshnessCondition updates the BackupFresh condition of the HotBackup, it returns true if it changed.
+// The condition is removed if the HotBackup has no freshness SLA.
+func setFreshnessCondition(hb *hazelcastv1alpha1.HotBackup, now time.Time) bool {
+	old := meta.FindStatusCondition(hb.Status.Conditions, hotBackupFreshCondition)
+	if hb.Spec.FreshnessSLA == nil {
+		if old == nil {
+			return false
+		}
+		meta.RemoveStatusCondition(&hb.Status.Conditions, hotBackupFreshCondition)
+		return true
+	}
+	c := freshnessCondition(hb, now)
+	changed := old == nil || old.Status != c.Status || old.Reason != c.Reason ||
+		old.Message != c.Message || old.ObservedGeneration != c.ObservedGeneration
+	meta.SetStatusCondition(&hb.Status.Conditions, c)
+	return changed
+}
+
+// checkFreshness updates the BackupFresh condition of the HotBackups as their last successful backups get older.
+func (r *HotBackupReconciler) checkFreshness(ctx context.Context) {
+	logger := r.Log.WithName("freshness")
+
+	hbList := &hazelcastv1alpha1.HotBackupList{}
+	if err := r.List(ctx, hbList); err != nil {
+		logger.Error(err, "Could not list HotBackup resources")
+		return
+	}
+	for _, hb := range hbList.Items {
+		if hb.Spec.FreshnessSLA == nil && meta.FindStatusCondition(hb.Status.Conditions, hotBackupFreshCondition) == nil {
+			continue
+		}
+		name := types.NamespacedName{Name: hb.Name, Namespace: hb.Namespace}
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			hb := &hazelcastv1alpha1.HotBackup{}
+			if err := r.Get(ctx, name, hb); err != nil {
+				return err
+			}
+			if !setFreshnessCondition(hb, r.clock.Now()) {
+				return nil
+			}
+			return r.Status().Update(ctx, hb)
+		})
+		if err != nil {
+			logger.Error(err, "Could not update the freshness of the HotBackup", "hotBackup", name)
+		}
+	}
+}
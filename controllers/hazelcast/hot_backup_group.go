@@ -0,0 +1,59 @@
+package hazelcast
+
+import (
+	"context"
+	"sync"
+)
+
+// memberGroup runs the member monitors of a backup. Unlike errgroup.Group, a failed member upload can be abandoned
+// without canceling the other members as long as the tolerance of the backup allows it.
+// The first error not abandoned cancels the context of the group.
+type memberGroup struct {
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu        sync.Mutex
+	tolerance int32
+	abandoned int32
+	err       error
+}
+
+func newMemberGroup(ctx context.Context, tolerance int32) (*memberGroup, context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	return &memberGroup{cancel: cancel, tolerance: tolerance}, ctx
+}
+
+// Go runs f in a new goroutine, an error returned by f cancels the group.
+func (g *memberGroup) Go(f func() error) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		if err := f(); err != nil {
+			g.mu.Lock()
+			defer g.mu.Unlock()
+			if g.err == nil {
+				g.err = err
+				g.cancel()
+			}
+		}
+	}()
+}
+
+// abandon returns true if one more member can be abandoned. It returns false if the tolerance is used up
+// or the group already failed, the member should return its error then.
+func (g *memberGroup) abandon() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.err != nil || g.abandoned >= g.tolerance {
+		return false
+	}
+	g.abandoned++
+	return true
+}
+
+// Wait blocks until all the goroutines of the group returned and returns the first error not abandoned.
+func (g *memberGroup) Wait() error {
+	g.wg.Wait()
+	g.cancel()
+	return g.err
+}
@@ -0,0 +1,42 @@
+package hazelcast
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestMemberGroup(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	errUpload := errors.New("upload failed")
+
+	// an abandoned member does not cancel the others
+	g, ctx := newMemberGroup(context.Background(), 1)
+	abandoned := make(chan struct{})
+	g.Go(func() error {
+		defer close(abandoned)
+		if g.abandon() {
+			return nil
+		}
+		return errUpload
+	})
+	g.Go(func() error {
+		<-abandoned
+		return ctx.Err()
+	})
+	Expect(g.Wait()).Should(Succeed())
+
+	// the tolerance is used up by the first failed member
+	g, ctx = newMemberGroup(context.Background(), 1)
+	Expect(g.abandon()).Should(BeTrue())
+	g.Go(func() error {
+		if g.abandon() {
+			return nil
+		}
+		return errUpload
+	})
+	Expect(g.Wait()).Should(MatchError(errUpload))
+	Expect(ctx.Err()).Should(MatchError(context.Canceled))
+}
@@ -0,0 +1,100 @@
+package hazelcast
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	hazelcastv1alpha1 "github.com/hazelcast/hazelcast-platform-operator/api/v1alpha1"
+)
+
+// defaultFlushHealthCheckInterval is the interval the members are checked at during the local backup if it is not set
+const defaultFlushHealthCheckInterval = 5 * time.Second
+
+// clusterHealth is the cluster backup the flush monitor checks and interrupts.
+type clusterHealth interface {
+	CheckHealth() error
+	Cancel(ctx context.Context) error
+}
+
+// flushMonitor checks the health of the cluster while the members flush the local backup
+// and interrupts the backup once the cluster degrades.
+type flushMonitor struct {
+	cancel    context.CancelFunc
+	remaining int32
+
+	mu  sync.Mutex
+	err error
+}
+
+// flushHealthCheckInterval returns the interval of the health checks during the local backup, 0 if they are disabled.
+func flushHealthCheckInterval(hb *hazelcastv1alpha1.HotBackup) time.Duration {
+	if hb.Spec.FlushHealthCheckInterval == nil {
+		return defaultFlushHealthCheckInterval
+	}
+	return hb.Spec.FlushHealthCheckInterval.Duration
+}
+
+// monitorFlush starts checking the health of the cluster at the interval until the given number of members flushed
+// their local backup or the monitor is stopped. Nothing is checked if the interval is 0.
+func monitorFlush(ctx context.Context, c clusterHealth, members int, interval time.Duration, logger logr.Logger) *flushMonitor {
+	ctx, cancel := context.WithCancel(ctx)
+	f := &flushMonitor{cancel: cancel, remaining: int32(members)}
+	if interval <= 0 {
+		return f
+	}
+	go wait.UntilWithContext(ctx, func(ctx context.Context) {
+		err := c.CheckHealth()
+		if err == nil {
+			return
+		}
+		logger.Error(err, "Cluster degraded during the local backup, interrupting the backup")
+		f.mu.Lock()
+		f.err = err
+		f.mu.Unlock()
+		if cancelErr := c.Cancel(ctx); cancelErr != nil {
+			logger.Error(cancelErr, "Could not interrupt the backup")
+		}
+		cancel()
+	}, interval)
+	return f
+}
+
+// flushed is called once the local backup of a member finished, the checks stop after the last member.
+func (f *flushMonitor) flushed() {
+	if atomic.AddInt32(&f.remaining, -1) <= 0 {
+		f.cancel()
+	}
+}
+
+func (f *flushMonitor) stop() {
+	f.cancel()
+}
+
+// failure returns the degradation of the cluster which interrupted the backup, nil if it was not interrupted.
+func (f *flushMonitor) failure() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.err
+}
+
+// uploadingGate calls the function once the given number of members flushed their local backup.
+type uploadingGate struct {
+	remaining int32
+	uploading func()
+}
+
+func newUploadingGate(members int, uploading func()) *uploadingGate {
+	return &uploadingGate{remaining: int32(members), uploading: uploading}
+}
+
+// flushed is called once the local backup of a member finished successfully, the last one calls the function.
+func (g *uploadingGate) flushed() {
+	if atomic.AddInt32(&g.remaining, -1) == 0 {
+		g.uploading()
+	}
+}
@@ -0,0 +1,63 @@
+package hazelcast
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	hazelcastv1alpha1 "github.com/hazelcast/hazelcast-platform-operator/api/v1alpha1"
+	"github.com/hazelcast/hazelcast-platform-operator/internal/backup"
+)
+
+type fakeClusterHealth struct {
+	degraded int32
+	canceled int32
+}
+
+func (c *fakeClusterHealth) CheckHealth() error {
+	if atomic.LoadInt32(&c.degraded) == 1 {
+		return backup.ErrMemberLeft
+	}
+	return nil
+}
+
+func (c *fakeClusterHealth) Cancel(context.Context) error {
+	atomic.AddInt32(&c.canceled, 1)
+	return nil
+}
+
+func TestFlushMonitor(t *testing.T) {
+	RegisterFailHandler(fail(t))
+
+	c := &fakeClusterHealth{}
+	f := monitorFlush(context.Background(), c, 2, time.Millisecond, ctrl.Log)
+	atomic.StoreInt32(&c.degraded, 1)
+	Eventually(f.failure).Should(MatchError(backup.ErrMemberLeft))
+	Expect(atomic.LoadInt32(&c.canceled)).Should(Equal(int32(1)))
+	Expect(failureReason(f.failure())).Should(Equal(hazelcastv1alpha1.HotBackupReasonClusterDegraded))
+
+	// the checks stop once all the members flushed the local backup
+	c = &fakeClusterHealth{}
+	f = monitorFlush(context.Background(), c, 2, time.Millisecond, ctrl.Log)
+	f.flushed()
+	f.flushed()
+	time.Sleep(10 * time.Millisecond)
+	atomic.StoreInt32(&c.degraded, 1)
+	Consistently(f.failure, 50*time.Millisecond).Should(BeNil())
+}
+
+func TestUploadingGate(t *testing.T) {
+	RegisterFailHandler(fail(t))
+
+	var calls int32
+	g := newUploadingGate(2, func() { atomic.AddInt32(&calls, 1) })
+	g.flushed()
+	Expect(atomic.LoadInt32(&calls)).Should(Equal(int32(0)))
+	g.flushed()
+	Expect(atomic.LoadInt32(&calls)).Should(Equal(int32(1)))
+	Expect(hazelcastv1alpha1.HotBackupUploading.IsRunning()).Should(BeTrue())
+}
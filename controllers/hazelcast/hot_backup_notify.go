@@ -0,0 +1,76 @@
+package hazelcast
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	hazelcastv1alpha1 "github.com/hazelcast/hazelcast-platform-operator/api/v1alpha1"
+	n "github.com/hazelcast/hazelcast-platform-operator/internal/naming"
+	"github.com/hazelcast/hazelcast-platform-operator/internal/notify"
+)
+
+// notifyTimeout is the time the notification of a finished backup is delivered within, including the retries
+const notifyTimeout = 10 * time.Minute
+
+// notify delivers the notification of the finished backup to the webhook of its result in the background,
+// the outcome of the delivery is recorded in an event of the HotBackup.
+func (r *HotBackupReconciler) notify(hb *hazelcastv1alpha1.HotBackup) {
+	nc := hb.Spec.Notification
+	if nc == nil {
+		return
+	}
+	url, event := nc.SuccessURL, "backup.succeeded"
+	switch hb.Status.State {
+	case hazelcastv1alpha1.HotBackupFailure:
+		url, event = nc.FailureURL, "backup.failed"
+	case hazelcastv1alpha1.HotBackupCanceled:
+		url, event = nc.FailureURL, "backup.canceled"
+	case hazelcastv1alpha1.HotBackupPartialSuccess:
+		url, event = nc.FailureURL, "backup.partial"
+	}
+	if url == "" {
+		return
+	}
+	record := auditRecord(hb)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+		defer cancel()
+
+		w := &notify.Webhook{URL: url, Retries: int(nc.Retries)}
+		if nc.RetryInterval != nil {
+			w.RetryInterval = nc.RetryInterval.Duration
+		}
+		if nc.SigningSecret != "" {
+			key, err := r.signingKey(ctx, hb.Namespace, nc.SigningSecret)
+			if err != nil {
+				r.recorder.AnnotatedEventf(hb, runAnnotations(hb.Status.RunID), corev1.EventTypeWarning, "NotificationFailed", "Could not sign the %s notification: %v", event, err)
+				return
+			}
+			w.Key = key
+		}
+		attempts, err := w.Deliver(ctx, event, record)
+		if err != nil {
+			r.recorder.AnnotatedEventf(hb, runAnnotations(hb.Status.RunID), corev1.EventTypeWarning, "NotificationFailed",
+				"Delivery of the %s notification to %s failed after %d attempts: %v", event, url, attempts, err)
+			return
+		}
+		r.recorder.AnnotatedEventf(hb, runAnnotations(hb.Status.RunID), corev1.EventTypeNormal, "NotificationDelivered", "Delivered the %s notification to %s", event, url)
+	}()
+}
+
+// signingKey returns the key signing the notifications from the secret.
+func (r *HotBackupReconciler) signingKey(ctx context.Context, namespace, name string) ([]byte, error) {
+	s := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, s); err != nil {
+		return nil, err
+	}
+	key, ok := s.Data[n.SigningKeyDataKey]
+	if !ok || len(key) == 0 {
+		return nil, fmt.Errorf("secret %s has no %s", name, n.SigningKeyDataKey)
+	}
+	return key, nil
+}
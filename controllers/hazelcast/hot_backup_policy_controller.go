@@ -0,0 +1,246 @@
+package hazelcast
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/equality"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	hazelcastv1alpha1 "github.com/hazelcast/hazelcast-platform-operator/api/v1alpha1"
+	"github.com/hazelcast/hazelcast-platform-operator/controllers/hazelcast/validation"
+	n "github.com/hazelcast/hazelcast-platform-operator/internal/naming"
+	"github.com/hazelcast/hazelcast-platform-operator/internal/util"
+)
+
+// HotBackupPolicyReconciler reconciles a HotBackupPolicy object
+type HotBackupPolicyReconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+}
+
+func NewHotBackupPolicyReconciler(c client.Client, log logr.Logger, s *runtime.Scheme) *HotBackupPolicyReconciler {
+	return &HotBackupPolicyReconciler{
+		Client: c,
+		Log:    log,
+		Scheme: s,
+	}
+}
+
+//+kubebuilder:rbac:groups=hazelcast.com,resources=hotbackuppolicies,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=hazelcast.com,resources=hotbackuppolicies/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=hazelcast.com,resources=hotbackuppolicies/finalizers,verbs=update
+
+func (r *HotBackupPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := r.Log.WithValues("hazelcast-hot-backup-policy", req.NamespacedName)
+
+	p := &hazelcastv1alpha1.HotBackupPolicy{}
+	if err := r.Get(ctx, req.NamespacedName, p); err != nil {
+		if kerrors.IsNotFound(err) {
+			logger.V(util.DebugLevel).Info("Could not find HotBackupPolicy, it is probably already deleted")
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	if err := validation.ValidateHotBackupPolicy(p); err != nil {
+		return updateHotBackupPolicyStatus(ctx, r.Client, p, failedHbStatus(err))
+	}
+	selector, err := metav1.LabelSelectorAsSelector(&p.Spec.Selector)
+	if err != nil {
+		return updateHotBackupPolicyStatus(ctx, r.Client, p, failedHbStatus(fmt.Errorf("invalid selector: %w", err)))
+	}
+
+	hzList := &hazelcastv1alpha1.HazelcastList{}
+	if err := r.List(ctx, hzList, client.InNamespace(p.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return updateHotBackupPolicyStatus(ctx, r.Client, p, failedHbStatus(err))
+	}
+	hbList := &hazelcastv1alpha1.HotBackupList{}
+	if err := r.List(ctx, hbList, client.InNamespace(p.Namespace), client.MatchingLabels{n.HotBackupPolicyLabel: p.Name}); err != nil {
+		return updateHotBackupPolicyStatus(ctx, r.Client, p, failedHbStatus(err))
+	}
+
+	selected := make(map[string]*hazelcastv1alpha1.Hazelcast, len(hzList.Items))
+	for i := range hzList.Items {
+		h := &hzList.Items[i]
+		if h.Spec.Persistence.IsEnabled() && !isBackupDisabled(h) && h.GetDeletionTimestamp() == nil {
+			selected[h.Name] = h
+		}
+	}
+
+	// the HotBackups of the clusters not selected anymore are deleted
+	backups := make(map[string]*hazelcastv1alpha1.HotBackup, len(hbList.Items))
+	var running int32
+	for i := range hbList.Items {
+		hb := &hbList.Items[i]
+		if _, ok := selected[hb.Spec.HazelcastResourceName]; !ok {
+			logger.Info("Deleting HotBackup of a cluster not selected anymore", "name", hb.Name)
+			if err := r.Delete(ctx, hb); err != nil && !kerrors.IsNotFound(err) {
+				return updateHotBackupPolicyStatus(ctx, r.Client, p, failedHbStatus(err))
+			}
+			continue
+		}
+		backups[hb.Spec.HazelcastResourceName] = hb
+		if p.Spec.Template.Schedule == "" && !hb.Status.State.IsFinished() && hb.Status.State != hazelcastv1alpha1.HotBackupSkipped {
+			running++
+		}
+	}
+
+	names := make([]string, 0, len(selected))
+	for name := range selected {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	p.Status.Clusters = nil
+	p.Status.Succeeded = 0
+	p.Status.Failed = 0
+	var waiting int
+	for _, name := range names {
+		h := selected[name]
+		spec := policyHotBackupSpec(p, h)
+		hb, ok := backups[name]
+		if !ok || specDrifted(hb, spec) {
+			// a new or changed one-off HotBackup starts a backup
+			if p.Spec.MaxConcurrentBackups > 0 && running >= p.Spec.MaxConcurrentBackups {
+				waiting++
+				p.Status.Clusters = append(p.Status.Clusters, policyClusterStatus(name, hb))
+				continue
+			}
+			if hb, err = r.applyHotBackup(ctx, p, h, hb, spec); err != nil {
+				return updateHotBackupPolicyStatus(ctx, r.Client, p, failedHbStatus(err))
+			}
+			logger.Info("Applied HotBackup", "name", hb.Name, "hazelcast", name)
+			if p.Spec.Template.Schedule == "" {
+				running++
+			}
+		}
+		cs := policyClusterStatus(name, hb)
+		switch cs.State {
+		case hazelcastv1alpha1.HotBackupSuccess:
+			p.Status.Succeeded++
+		case hazelcastv1alpha1.HotBackupFailure, hazelcastv1alpha1.HotBackupCanceled, hazelcastv1alpha1.HotBackupPartialSuccess:
+			p.Status.Failed++
+		}
+		p.Status.Clusters = append(p.Status.Clusters, cs)
+	}
+
+	if running > 0 || waiting > 0 {
+		return updateHotBackupPolicyStatus(ctx, r.Client, p, hbWithStatus(hazelcastv1alpha1.HotBackupInProgress).
+			withMessage(fmt.Sprintf("%d HotBackups running, %d clusters waiting", running, waiting)))
+	}
+	if p.Status.Failed > 0 {
+		return updateHotBackupPolicyStatus(ctx, r.Client, p, hbWithStatus(hazelcastv1alpha1.HotBackupFailure).
+			withMessage(fmt.Sprintf("Backups of %d clusters failed", p.Status.Failed)))
+	}
+	return updateHotBackupPolicyStatus(ctx, r.Client, p, hbWithStatus(hazelcastv1alpha1.HotBackupSuccess))
+}
+
+// applyHotBackup creates the HotBackup of the cluster or updates the existing one to the given spec.
+func (r *HotBackupPolicyReconciler) applyHotBackup(ctx context.Context, p *hazelcastv1alpha1.HotBackupPolicy, h *hazelcastv1alpha1.Hazelcast, hb *hazelcastv1alpha1.HotBackup, spec hazelcastv1alpha1.HotBackupSpec) (*hazelcastv1alpha1.HotBackup, error) {
+	if hb != nil {
+		hb.Spec = spec
+		return hb, r.Update(ctx, hb)
+	}
+	hb = &hazelcastv1alpha1.HotBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      p.Name + "-" + h.Name,
+			Namespace: p.Namespace,
+			Labels: map[string]string{
+				n.HotBackupPolicyLabel: p.Name,
+			},
+		},
+		Spec: spec,
+	}
+	if err := controllerutil.SetControllerReference(p, hb, r.Scheme); err != nil {
+		return nil, fmt.Errorf("failed to set owner reference on HotBackup: %w", err)
+	}
+	if err := r.Create(ctx, hb); err != nil && !kerrors.IsAlreadyExists(err) {
+		return nil, err
+	}
+	return hb, nil
+}
+
+// specDrifted returns true if the spec of the HotBackup differs from the one of the policy. The cancel field is set
+// on the HotBackup by the user, it is not reverted.
+func specDrifted(hb *hazelcastv1alpha1.HotBackup, spec hazelcastv1alpha1.HotBackupSpec) bool {
+	spec.Cancel = hb.Spec.Cancel
+	return !equality.Semantic.DeepEqual(hb.Spec, spec)
+}
+
+// policyHotBackupSpec returns the spec of the HotBackup of the cluster created from the template of the policy.
+func policyHotBackupSpec(p *hazelcastv1alpha1.HotBackupPolicy, h *hazelcastv1alpha1.Hazelcast) hazelcastv1alpha1.HotBackupSpec {
+	t := p.Spec.Template
+	spec := hazelcastv1alpha1.HotBackupSpec{
+		HazelcastResourceName: h.Name,
+		Schedule:              t.Schedule,
+		Compression:           t.Compression,
+		CompressionLevel:      t.CompressionLevel,
+		Metadata:              t.Metadata,
+	}
+	if h.Spec.Persistence.IsExternal() {
+		spec.BucketURI = t.BucketURI
+		spec.Secret = t.Secret
+	}
+	return spec
+}
+
+func policyClusterStatus(name string, hb *hazelcastv1alpha1.HotBackup) hazelcastv1alpha1.HotBackupPolicyClusterStatus {
+	cs := hazelcastv1alpha1.HotBackupPolicyClusterStatus{Name: name}
+	if hb != nil {
+		cs.HotBackup = hb.Name
+		cs.State = hb.Status.State
+		cs.Message = hb.Status.Message
+		cs.LastSuccessTime = hb.Status.LastSuccessTime
+	}
+	return cs
+}
+
+func updateHotBackupPolicyStatus(ctx context.Context, c client.Client, p *hazelcastv1alpha1.HotBackupPolicy, options hotBackupOptionsBuilder) (ctrl.Result, error) {
+	p.Status.State = options.status
+	p.Status.Message = options.message
+	if err := c.Status().Update(ctx, p); err != nil {
+		if kerrors.IsConflict(err) {
+			return ctrl.Result{Requeue: true}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	if options.status == hazelcastv1alpha1.HotBackupFailure {
+		return ctrl.Result{}, options.err
+	}
+	return ctrl.Result{}, nil
+}
+
+// hazelcastUpdates enqueues the policies in the namespace of the Hazelcast resource as it may be selected by them.
+func (r *HotBackupPolicyReconciler) hazelcastUpdates(o client.Object) []reconcile.Request {
+	policies := &hazelcastv1alpha1.HotBackupPolicyList{}
+	if err := r.List(context.Background(), policies, client.InNamespace(o.GetNamespace())); err != nil {
+		r.Log.Error(err, "Could not list HotBackupPolicy resources", "namespace", o.GetNamespace())
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(policies.Items))
+	for _, p := range policies.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: p.Name, Namespace: p.Namespace}})
+	}
+	return requests
+}
+
+func (r *HotBackupPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&hazelcastv1alpha1.HotBackupPolicy{}).
+		Owns(&hazelcastv1alpha1.HotBackup{}).
+		Watches(&source.Kind{Type: &hazelcastv1alpha1.Hazelcast{}}, handler.EnqueueRequestsFromMapFunc(r.hazelcastUpdates)).
+		Complete(r)
+}
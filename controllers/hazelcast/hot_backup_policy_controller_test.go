@@ -0,0 +1,87 @@
+package hazelcast
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	hazelcastv1alpha1 "github.com/hazelcast/hazelcast-platform-operator/api/v1alpha1"
+	n "github.com/hazelcast/hazelcast-platform-operator/internal/naming"
+)
+
+func TestHotBackupPolicyReconciler_shouldBackupSelectedClustersRespectingConcurrency(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	pn := types.NamespacedName{Name: "fleet", Namespace: "default"}
+	policy := &hazelcastv1alpha1.HotBackupPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: pn.Name, Namespace: pn.Namespace},
+		Spec: hazelcastv1alpha1.HotBackupPolicySpec{
+			Selector:             metav1.LabelSelector{MatchLabels: map[string]string{"fleet": "true"}},
+			MaxConcurrentBackups: 1,
+		},
+	}
+	persistent := func(name string, labels map[string]string) *hazelcastv1alpha1.Hazelcast {
+		return &hazelcastv1alpha1.Hazelcast{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: pn.Namespace, Labels: labels},
+			Spec: hazelcastv1alpha1.HazelcastSpec{
+				Persistence: &hazelcastv1alpha1.HazelcastPersistenceConfiguration{BaseDir: "/data/hot-restart"},
+			},
+		}
+	}
+	fleet := map[string]string{"fleet": "true"}
+
+	c := fakeClient(policy, persistent("hz-1", fleet), persistent("hz-2", fleet), persistent("other", nil))
+	r := NewHotBackupPolicyReconciler(c, ctrl.Log.WithName("test").WithName("HotBackupPolicy"), c.Scheme())
+
+	reconcileAndFinishBackups := func(expectedBackups int) {
+		_, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: pn})
+		Expect(err).Should(BeNil())
+		hbs := &hazelcastv1alpha1.HotBackupList{}
+		Expect(c.List(context.TODO(), hbs, client.MatchingLabels{n.HotBackupPolicyLabel: pn.Name})).Should(Succeed())
+		Expect(hbs.Items).Should(HaveLen(expectedBackups))
+		for i := range hbs.Items {
+			hbs.Items[i].Status.State = hazelcastv1alpha1.HotBackupSuccess
+			Expect(c.Status().Update(context.TODO(), &hbs.Items[i])).Should(Succeed())
+		}
+	}
+
+	reconcileAndFinishBackups(1)
+	policy = &hazelcastv1alpha1.HotBackupPolicy{}
+	Expect(c.Get(context.TODO(), pn, policy)).Should(Succeed())
+	Expect(policy.Status.State).Should(Equal(hazelcastv1alpha1.HotBackupInProgress))
+	Expect(policy.Status.Clusters).Should(HaveLen(2))
+
+	reconcileAndFinishBackups(2)
+	reconcileAndFinishBackups(2)
+	policy = &hazelcastv1alpha1.HotBackupPolicy{}
+	Expect(c.Get(context.TODO(), pn, policy)).Should(Succeed())
+	Expect(policy.Status.State).Should(Equal(hazelcastv1alpha1.HotBackupSuccess))
+	Expect(policy.Status.Succeeded).Should(Equal(int32(2)))
+	Expect(policy.Status.Clusters).Should(ConsistOf(
+		hazelcastv1alpha1.HotBackupPolicyClusterStatus{Name: "hz-1", HotBackup: "fleet-hz-1", State: hazelcastv1alpha1.HotBackupSuccess},
+		hazelcastv1alpha1.HotBackupPolicyClusterStatus{Name: "hz-2", HotBackup: "fleet-hz-2", State: hazelcastv1alpha1.HotBackupSuccess},
+	))
+
+	// the cancel field set on a HotBackup of the policy is not reverted
+	hb := &hazelcastv1alpha1.HotBackup{}
+	hbn := types.NamespacedName{Name: "fleet-hz-1", Namespace: pn.Namespace}
+	Expect(c.Get(context.TODO(), hbn, hb)).Should(Succeed())
+	hb.Spec.Cancel = true
+	Expect(c.Update(context.TODO(), hb)).Should(Succeed())
+	reconcileAndFinishBackups(2)
+	hb = &hazelcastv1alpha1.HotBackup{}
+	Expect(c.Get(context.TODO(), hbn, hb)).Should(Succeed())
+	Expect(hb.Spec.Cancel).Should(BeTrue())
+
+	// the HotBackup of a cluster not selected anymore is deleted
+	h := &hazelcastv1alpha1.Hazelcast{}
+	Expect(c.Get(context.TODO(), types.NamespacedName{Name: "hz-2", Namespace: pn.Namespace}, h)).Should(Succeed())
+	h.Labels = nil
+	Expect(c.Update(context.TODO(), h)).Should(Succeed())
+	reconcileAndFinishBackups(1)
+}
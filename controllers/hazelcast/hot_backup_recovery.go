@@ -0,0 +1,179 @@
+package hazelcast
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"golang.org/x/sync/errgroup"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	hazelcastv1alpha1 "github.com/hazelcast/hazelcast-platform-operator/api/v1alpha1"
+	"github.com/hazelcast/hazelcast-platform-operator/internal/backup"
+	"github.com/hazelcast/hazelcast-platform-operator/internal/upload"
+)
+
+// interruptedBackupTimeout is the time after the start of an interrupted backup it is not waited for anymore
+const interruptedBackupTimeout = time.Hour
+
+// errBackupInterrupted is returned for a backup interrupted by the restart of the operator which could not be recovered.
+// The backup can be triggered again.
+var errBackupInterrupted = errors.New("HotBackup was interrupted by the restart of the operator")
+
+// recoverInterruptedBackups recovers the HotBackups left in progress by the previous operator instance.
+// It runs once when the operator starts, so none of these backups is monitored by this instance.
+func (r *HotBackupReconciler) recoverInterruptedBackups(ctx context.Context) {
+	logger := r.Log.WithName("interrupted-backups")
+
+	hbList := &hazelcastv1alpha1.HotBackupList{}
+	if err := r.List(ctx, hbList); err != nil {
+		logger.Error(err, "Could not list HotBackup resources")
+		return
+	}
+	for _, hb := range hbList.Items {
+		if (hb.Status.State != hazelcastv1alpha1.HotBackupInProgress && hb.Status.State != hazelcastv1alpha1.HotBackupUploading) ||
+			hb.GetDeletionTimestamp() != nil {
+			continue
+		}
+		name := types.NamespacedName{Name: hb.Name, Namespace: hb.Namespace}
+		if !r.lockBackup(name) {
+			continue
+		}
+		go r.recoverBackup(ctx, name, logger.WithValues("hazelcast-hot-backup", name, "runID", hb.Status.RunID)) //nolint:errcheck
+	}
+}
+
+// recoverBackup checks the state of the member backups and uploads of the interrupted HotBackup on the cluster.
+// It resumes monitoring the ones still running and marks the HotBackup successful if all of them finished,
+// otherwise the HotBackup fails with errBackupInterrupted.
+func (r *HotBackupReconciler) recoverBackup(ctx context.Context, backupName types.NamespacedName, logger logr.Logger) (ctrl.Result, error) {
+	defer r.unlockBackup(backupName)
+	logger.Info("Recovering interrupted backup")
+	ctx = upload.WithNamespace(ctx, backupName.Namespace)
+
+	hb := &hazelcastv1alpha1.HotBackup{}
+	if err := r.Get(ctx, backupName, hb); err != nil {
+		return r.updateStatus(ctx, backupName, failedHbStatus(err))
+	}
+	now := r.clock.Now()
+	deadline := interruptedBackupDeadline(hb, now)
+	if !deadline.After(now) {
+		return r.updateStatus(ctx, backupName, failedHbStatus(
+			fmt.Errorf("%w: it did not finish within %s", errBackupInterrupted, interruptedBackupTimeout)))
+	}
+
+	hz := &hazelcastv1alpha1.Hazelcast{}
+	if err := r.Get(ctx, types.NamespacedName{Name: hb.Spec.HazelcastResourceName, Namespace: hb.Namespace}, hz); err != nil {
+		return r.updateStatus(ctx, backupName, failedHbStatus(fmt.Errorf("%w: %v", errBackupInterrupted, err)))
+	}
+	b, err := backup.NewClusterBackup(hz)
+	if err != nil {
+		return r.updateStatus(ctx, backupName, failedHbStatus(fmt.Errorf("%w: %v", errBackupInterrupted, err)))
+	}
+	// the cluster stays passive if the operator stopped while the backup was being started
+	if err := b.Activate(ctx); err != nil {
+		logger.Error(err, "Could not activate the cluster")
+	}
+
+	// the recovered run is canceled by the cancel field or the annotation like the runs started by this instance
+	runCtx, cancelRun := context.WithCancel(ctx)
+	defer cancelRun()
+	defer r.registerRun(backupName, cancelRun)()
+
+	waitCtx, cancel := context.WithDeadline(runCtx, deadline)
+	defer cancel()
+
+	external := r.isExternal(hz)
+	members := b.Members()
+	results := newUploadResults(len(members))
+	memberStatuses := make([]hazelcastv1alpha1.HotBackupMemberStatus, len(members))
+	g, groupCtx := errgroup.WithContext(waitCtx)
+	for i, m := range members {
+		i, m := i, m
+		memberStatuses[i] = hazelcastv1alpha1.HotBackupMemberStatus{Address: m.Address, UUID: m.UUID.String()}
+		g.Go(func() error {
+			if err := m.Wait(groupCtx); err != nil {
+				if runCanceled(ctx, runCtx) {
+					return b.Cancel(ctx)
+				}
+				return fmt.Errorf("member %s: %w", m.Address, err)
+			}
+			if !external {
+				return nil
+			}
+			u, err := upload.Find(groupCtx, m.Address, hb.Name)
+			if err != nil {
+				return err
+			}
+			if u == nil {
+				return fmt.Errorf("member %s: upload was not started", m.Address)
+			}
+			err = u.Wait(groupCtx)
+			s := u.Status()
+			memberStatuses[i].AgentVersion = s.AgentVersion
+			if err != nil {
+				if runCanceled(ctx, runCtx) {
+					return u.Cancel(ctx)
+				}
+				return fmt.Errorf("member %s: %w", m.Address, err)
+			}
+			memberStatuses[i].UploadedBytes = uploadedBytes(s)
+			results.add(s)
+			return nil
+		})
+	}
+	err = g.Wait()
+	if runCanceled(ctx, runCtx) {
+		return r.updateStatus(ctx, backupName, failedHbStatus(errRunCanceled).withMembers(memberStatuses))
+	}
+	if err != nil {
+		return r.updateStatus(ctx, backupName, failedHbStatus(fmt.Errorf("%w: %v", errBackupInterrupted, err)).
+			withMembers(memberStatuses))
+	}
+
+	logger.Info("Interrupted backup finished on all members")
+	var digest string
+	if external && results.hasChecksums() && len(members) > 0 {
+		digest, err = storeDigest(ctx, hb, members[0].Address, results.backupFolder, results.checksums, logger)
+		if err != nil {
+			return r.updateStatus(ctx, backupName, failedHbStatus(err).withMembers(memberStatuses))
+		}
+	}
+	message := "Backup was recovered after the restart of the operator"
+	if hb.Spec.UpdateLatest && results.backupFolder != "" && len(members) > 0 {
+		if m := updateLatestPointer(ctx, hb, members[0].Address, results.backupFolder, logger); m != "" {
+			message = m
+		}
+	}
+	result, err := r.updateStatus(ctx, backupName, hbWithStatus(hazelcastv1alpha1.HotBackupSuccess).
+		withMessage(message).
+		withCompression(results.compressionLevel, results.originalSize, results.compressedSize).
+		withBackupFolder(results.backupFolder).
+		withDigest(digest).
+		withLocalOnly(hz.Spec.Persistence.IsExternal() && !external).
+		withJetSnapshots(hz.Spec.Persistence.IsJetLosslessRestartEnabled()).
+		withSourceMemberCount(int32(len(members))).
+		withMembers(memberStatuses))
+	if err != nil {
+		return result, err
+	}
+	if err := r.updateLastSuccessfulConfiguration(ctx, backupName, logger); err != nil {
+		logger.Error(err, "Could not save the current successful spec as annotation to the custom resource")
+	}
+	return result, nil
+}
+
+// interruptedBackupDeadline returns the time until the interrupted backup is waited for.
+// It is counted from the start of the backup if it is known.
+func interruptedBackupDeadline(hb *hazelcastv1alpha1.HotBackup, now time.Time) time.Time {
+	started := now
+	if hb.Spec.Schedule != "" && hb.Status.LastScheduledRun != nil {
+		started = hb.Status.LastScheduledRun.Time
+	} else if hb.Status.PendingSince != nil {
+		started = hb.Status.PendingSince.Time
+	}
+	return started.Add(interruptedBackupTimeout)
+}
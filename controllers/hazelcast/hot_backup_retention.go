@@ -0,0 +1,219 @@
+package hazelcast
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/attribute"
+
+	hazelcastv1alpha1 "github.com/hazelcast/hazelcast-platform-operator/api/v1alpha1"
+	n "github.com/hazelcast/hazelcast-platform-operator/internal/naming"
+	"github.com/hazelcast/hazelcast-platform-operator/internal/rest"
+	"github.com/hazelcast/hazelcast-platform-operator/internal/tracing"
+	"github.com/hazelcast/hazelcast-platform-operator/internal/upload"
+)
+
+// retentionTier keeps the newest backup of each of the most recent count periods.
+type retentionTier struct {
+	count int32
+	// period returns the start of the period the time belongs to
+	period func(t time.Time) time.Time
+}
+
+func retentionTiers(r *hazelcastv1alpha1.BackupRetention) []retentionTier {
+	return []retentionTier{
+		{count: r.Hourly, period: func(t time.Time) time.Time {
+			return t.Truncate(time.Hour)
+		}},
+		{count: r.Daily, period: startOfDay},
+		{count: r.Weekly, period: func(t time.Time) time.Time {
+			d := startOfDay(t)
+			// weeks start on Monday
+			return d.AddDate(0, 0, -(int(d.Weekday())+6)%7)
+		}},
+		{count: r.Monthly, period: func(t time.Time) time.Time {
+			return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+		}},
+	}
+}
+
+// tiered returns true if the tiers select the kept backups, i.e. a tier is set or neither maxCount nor maxAge is set.
+func tiered(r *hazelcastv1alpha1.BackupRetention) bool {
+	return r.Hourly > 0 || r.Daily > 0 || r.Weekly > 0 || r.Monthly > 0 || (r.MaxCount == 0 && r.MaxAge == nil)
+}
+
+// exceedsLimits returns true if the backup at the index of the backups sorted newest first is beyond maxCount or older than maxAge.
+func exceedsLimits(r *hazelcastv1alpha1.BackupRetention, i int, createdAt, now time.Time) bool {
+	return (r.MaxCount > 0 && int32(i) >= r.MaxCount) || (r.MaxAge != nil && now.Sub(createdAt) > r.MaxAge.Duration)
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// expiredBackups returns the backup folders not kept by any tier of the retention or beyond its limits at the given time, oldest first.
+// The newest backup and the current backup folder are always kept, so are the delta bases of all the kept backups
+// as the deltas cannot be restored without them. It also returns the backup folders kept only as delta bases.
+func expiredBackups(folders []rest.BackupFolder, r *hazelcastv1alpha1.BackupRetention, current string, now time.Time) ([]string, []string) {
+	sorted := make([]rest.BackupFolder, len(folders))
+	copy(sorted, folders)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CreatedAt.After(sorted[j].CreatedAt)
+	})
+
+	keep := make(map[string]bool, len(sorted))
+	if !tiered(r) {
+		for _, f := range sorted {
+			keep[f.Name] = true
+		}
+	}
+	for _, tier := range retentionTiers(r) {
+		periods := make(map[time.Time]struct{}, tier.count)
+		for _, f := range sorted {
+			p := tier.period(f.CreatedAt.UTC())
+			if _, ok := periods[p]; ok {
+				continue
+			}
+			if int32(len(periods)) == tier.count {
+				break
+			}
+			periods[p] = struct{}{}
+			keep[f.Name] = true
+		}
+	}
+	for i, f := range sorted {
+		if exceedsLimits(r, i, f.CreatedAt, now) {
+			keep[f.Name] = false
+		}
+	}
+	keep[current] = true
+	if len(sorted) > 0 {
+		keep[sorted[0].Name] = true
+	}
+	chained := keepDeltaBases(sorted, keep)
+
+	var expired []string
+	for i := len(sorted) - 1; i >= 0; i-- {
+		if !keep[sorted[i].Name] {
+			expired = append(expired, sorted[i].Name)
+		}
+	}
+	return expired, chained
+}
+
+// keepDeltaBases adds the delta bases of the kept backups to keep along the whole chain of each delta, so a chain
+// is deleted only once none of its backups is kept. It returns the backup folders kept only as delta bases.
+func keepDeltaBases(folders []rest.BackupFolder, keep map[string]bool) []string {
+	bases := make(map[string]string, len(folders))
+	for _, f := range folders {
+		if f.DeltaBase != "" {
+			bases[f.Name] = f.DeltaBase
+		}
+	}
+	var chained []string
+	for _, f := range folders {
+		if !keep[f.Name] {
+			continue
+		}
+		for base := bases[f.Name]; base != "" && !keep[base]; base = bases[base] {
+			keep[base] = true
+			chained = append(chained, base)
+		}
+	}
+	sort.Strings(chained)
+	return chained
+}
+
+// pruneBackups deletes the backups of the cluster from the bucket which are not kept by the retention of the HotBackup.
+// It is best-effort, it returns the message of the failure to show in the status.
+func pruneBackups(ctx context.Context, hb *hazelcastv1alpha1.HotBackup, memberAddress, backupFolder string, now time.Time, logger logr.Logger) string {
+	ctx, span := tracing.Start(ctx, "retention")
+	var err error
+	defer func() { tracing.End(span, err) }()
+
+	config := &upload.Config{
+		BucketURI:     hb.Spec.BucketURI,
+		HazelcastName: hb.Spec.HazelcastResourceName,
+		SecretName:    hb.Spec.Secret,
+	}
+	folders, err := upload.ListBackups(ctx, memberAddress, config)
+	if err != nil {
+		logger.Error(err, "Could not list the backups for the retention")
+		return fmt.Sprintf("Old backups could not be pruned: %v", err)
+	}
+	expired, chained := expiredBackups(ownBackups(folders, hb.Name), hb.Spec.Retention, backupFolder, now)
+	if len(chained) > 0 {
+		logger.Info("Keeping backups the kept delta backups depend on", "backupFolders", chained)
+	}
+	if len(expired) == 0 {
+		return ""
+	}
+	span.SetAttributes(attribute.Int("retention.deleted", len(expired)))
+	logger.Info("Deleting backups not kept by the retention", "backupFolders", expired)
+	if err = upload.DeleteBackups(ctx, memberAddress, config, expired); err != nil {
+		logger.Error(err, "Could not delete the backups not kept by the retention")
+		return fmt.Sprintf("Old backups could not be pruned: %v", err)
+	}
+	return ""
+}
+
+// ownBackups returns the backups taken by the HotBackup. The backups of the other HotBackups of the cluster and
+// the ones whose HotBackup is not known are not pruned by its retention.
+func ownBackups(folders []rest.BackupFolder, hotBackupName string) []rest.BackupFolder {
+	var own []rest.BackupFolder
+	for _, f := range folders {
+		if f.HotBackup == hotBackupName {
+			own = append(own, f)
+		}
+	}
+	return own
+}
+
+// localBackupDir returns the directory the members keep their local backups in.
+func localBackupDir(hb *hazelcastv1alpha1.HotBackup, hz *hazelcastv1alpha1.Hazelcast) string {
+	return path.Join(backupPath(hb, hz), n.LocalBackupDir)
+}
+
+// markLocalBackups makes the agents record the local backups just taken by the members as taken by the HotBackup,
+// so that its retention prunes only its own local backups. It is best-effort, unmarked local backups are kept.
+func markLocalBackups(ctx context.Context, hb *hazelcastv1alpha1.HotBackup, hz *hazelcastv1alpha1.Hazelcast, memberAddresses []string, logger logr.Logger) {
+	dir := localBackupDir(hb, hz)
+	for _, addr := range memberAddresses {
+		if err := upload.MarkLocalBackup(ctx, addr, dir, hb.Name); err != nil {
+			logger.Error(err, "Could not mark the local backup of the member, it is not pruned by the retention", "member", addr)
+		}
+	}
+}
+
+// pruneLocalBackups deletes the local backups of the members taken by the HotBackup which are not kept by its retention
+// from the local backup directory. It is best-effort, it returns the message of the failure to show in the status.
+func pruneLocalBackups(ctx context.Context, hb *hazelcastv1alpha1.HotBackup, hz *hazelcastv1alpha1.Hazelcast, memberAddresses []string, now time.Time, logger logr.Logger) string {
+	dir := localBackupDir(hb, hz)
+	var failed []string
+	var lastErr error
+	for _, addr := range memberAddresses {
+		backups, err := upload.ListLocalBackups(ctx, addr, dir)
+		if err == nil {
+			expired, _ := expiredBackups(ownBackups(backups, hb.Name), hb.Spec.Retention, "", now)
+			if len(expired) == 0 {
+				continue
+			}
+			logger.Info("Deleting local backups not kept by the retention", "member", addr, "backupDirs", expired)
+			err = upload.DeleteLocalBackups(ctx, addr, dir, expired)
+		}
+		if err != nil {
+			logger.Error(err, "Could not prune the local backups of the member", "member", addr)
+			failed = append(failed, addr)
+			lastErr = err
+		}
+	}
+	if lastErr != nil {
+		return fmt.Sprintf("Old local backups of members %s could not be pruned: %v", strings.Join(failed, ", "), lastErr)
+	}
+	return ""
+}
@@ -0,0 +1,147 @@
+package hazelcast
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	hazelcastv1alpha1 "github.com/hazelcast/hazelcast-platform-operator/api/v1alpha1"
+	"github.com/hazelcast/hazelcast-platform-operator/internal/rest"
+)
+
+func TestExpiredBackups(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	// Wednesday
+	now := time.Date(2022, 6, 15, 12, 30, 0, 0, time.UTC)
+	folders := []rest.BackupFolder{
+		{Name: "now", CreatedAt: now},
+		{Name: "same-hour", CreatedAt: now.Add(-10 * time.Minute)},
+		{Name: "hour-ago", CreatedAt: now.Add(-time.Hour)},
+		{Name: "yesterday", CreatedAt: now.AddDate(0, 0, -1)},
+		{Name: "two-days-ago", CreatedAt: now.AddDate(0, 0, -2)},
+		// Sunday of the previous week
+		{Name: "last-week", CreatedAt: now.AddDate(0, 0, -3)},
+		{Name: "last-month", CreatedAt: now.AddDate(0, -1, 0)},
+		{Name: "two-months-ago", CreatedAt: now.AddDate(0, -2, 0)},
+	}
+
+	tests := []struct {
+		name      string
+		retention hazelcastv1alpha1.BackupRetention
+		want      []string
+	}{
+		{
+			name:      "hourly",
+			retention: hazelcastv1alpha1.BackupRetention{Hourly: 2},
+			want:      []string{"two-months-ago", "last-month", "last-week", "two-days-ago", "yesterday", "same-hour"},
+		},
+		{
+			name:      "daily",
+			retention: hazelcastv1alpha1.BackupRetention{Daily: 2},
+			want:      []string{"two-months-ago", "last-month", "last-week", "two-days-ago", "hour-ago", "same-hour"},
+		},
+		{
+			name:      "weekly and monthly",
+			retention: hazelcastv1alpha1.BackupRetention{Weekly: 2, Monthly: 2},
+			want:      []string{"two-months-ago", "two-days-ago", "yesterday", "hour-ago", "same-hour"},
+		},
+		{
+			name:      "more periods than backups",
+			retention: hazelcastv1alpha1.BackupRetention{Monthly: 12},
+			want:      []string{"two-days-ago", "yesterday", "hour-ago", "same-hour", "last-week"},
+		},
+		{
+			name:      "max count",
+			retention: hazelcastv1alpha1.BackupRetention{MaxCount: 3},
+			want:      []string{"two-months-ago", "last-month", "last-week", "two-days-ago", "yesterday"},
+		},
+		{
+			name:      "max age",
+			retention: hazelcastv1alpha1.BackupRetention{MaxAge: &metav1.Duration{Duration: 36 * time.Hour}},
+			want:      []string{"two-months-ago", "last-month", "last-week", "two-days-ago"},
+		},
+		{
+			name:      "tiers limited by max count",
+			retention: hazelcastv1alpha1.BackupRetention{Daily: 2, MaxCount: 2},
+			want:      []string{"two-months-ago", "last-month", "last-week", "two-days-ago", "yesterday", "hour-ago", "same-hour"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			RegisterFailHandler(fail(t))
+			Expect(expiredBackups(folders, &tt.retention, "now", now)).Should(ConsistOf(tt.want))
+		})
+	}
+
+	// the current backup is kept even if it is not the newest one
+	Expect(expiredBackups(folders, &hazelcastv1alpha1.BackupRetention{Hourly: 1}, "last-month", now)).ShouldNot(ContainElement("last-month"))
+}
+
+func TestExpiredBackupsKeepDeltaChains(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	now := time.Date(2022, 6, 15, 12, 30, 0, 0, time.UTC)
+	folders := []rest.BackupFolder{
+		{Name: "delta-2", CreatedAt: now, DeltaBase: "delta-1"},
+		{Name: "delta-1", CreatedAt: now.AddDate(0, 0, -1), DeltaBase: "full"},
+		{Name: "full", CreatedAt: now.AddDate(0, 0, -2)},
+		{Name: "old-delta", CreatedAt: now.AddDate(0, -1, 0), DeltaBase: "old-full"},
+		{Name: "old-full", CreatedAt: now.AddDate(0, -1, -1)},
+	}
+
+	// the newest delta needs its whole chain, the old chain is not kept by any backup
+	expired, chained := expiredBackups(folders, &hazelcastv1alpha1.BackupRetention{Daily: 1}, "delta-2", now)
+	Expect(expired).Should(Equal([]string{"old-full", "old-delta"}))
+	Expect(chained).Should(Equal([]string{"delta-1", "full"}))
+
+	// a kept delta keeps its base even if the retention does not
+	expired, chained = expiredBackups(folders, &hazelcastv1alpha1.BackupRetention{Monthly: 2}, "delta-2", now)
+	Expect(expired).Should(BeEmpty())
+	Expect(chained).Should(ConsistOf("delta-1", "full", "old-full"))
+}
+
+func TestPrunableFolders(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	folders := []rest.BackupFolder{
+		{Name: "compacted"},
+		{Name: "full"},
+		{Name: "delta-1", DeltaBase: "full"},
+		{Name: "delta-2", DeltaBase: "delta-1"},
+		// another delta is still applied on the first delta of the merged chain
+		{Name: "branch", DeltaBase: "delta-1"},
+	}
+	Expect(prunableFolders(folders, []string{"full", "delta-1", "delta-2"})).Should(Equal([]string{"delta-2"}))
+	Expect(prunableFolders(folders[:4], []string{"full", "delta-1", "delta-2"})).Should(Equal([]string{"full", "delta-1", "delta-2"}))
+}
+
+func TestExpiredBackupsOfOtherHotBackups(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	now := time.Date(2022, 6, 15, 12, 30, 0, 0, time.UTC)
+	folders := []rest.BackupFolder{
+		{Name: "backup-3", CreatedAt: now, HotBackup: "hourly"},
+		{Name: "backup-2", CreatedAt: now.Add(-time.Hour), HotBackup: "nightly"},
+		{Name: "backup-1", CreatedAt: now.Add(-2 * time.Hour), HotBackup: "hourly"},
+		{Name: "backup-0", CreatedAt: now.Add(-3 * time.Hour)},
+	}
+
+	// the backups of the other HotBackup and the unmarked ones are kept
+	expired, _ := expiredBackups(ownBackups(folders, "hourly"), &hazelcastv1alpha1.BackupRetention{MaxCount: 1}, "", now)
+	Expect(expired).Should(Equal([]string{"backup-1"}))
+	expired, _ = expiredBackups(ownBackups(folders, "nightly"), &hazelcastv1alpha1.BackupRetention{MaxAge: &metav1.Duration{Duration: time.Minute}}, "", now)
+	Expect(expired).Should(BeEmpty())
+}
+
+func TestLocalBackupDir(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	hz := &hazelcastv1alpha1.Hazelcast{
+		Spec: hazelcastv1alpha1.HazelcastSpec{
+			Persistence: &hazelcastv1alpha1.HazelcastPersistenceConfiguration{BaseDir: "/data/hot-restart"},
+		},
+	}
+	hb := &hazelcastv1alpha1.HotBackup{}
+	Expect(localBackupDir(hb, hz)).Should(Equal("/data/hot-restart/hot-backup"))
+
+	hb.Spec.BackupPathOverride = "/mnt/backups"
+	Expect(localBackupDir(hb, hz)).Should(Equal("/mnt/backups/hot-backup"))
+}
@@ -0,0 +1,138 @@
+package hazelcast
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/robfig/cron/v3"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+
+	hazelcastv1alpha1 "github.com/hazelcast/hazelcast-platform-operator/api/v1alpha1"
+)
+
+// scheduler runs the jobs of the scheduled HotBackups. It is implemented by *cron.Cron,
+// the tests replace it to run the jobs without waiting for the wall-clock time.
+type scheduler interface {
+	Schedule(schedule cron.Schedule, job cron.Job) cron.EntryID
+	Remove(id cron.EntryID)
+	Entry(id cron.EntryID) cron.Entry
+	Entries() []cron.Entry
+	Start()
+}
+
+// recentDurationsLimit is the number of the successful backup durations kept in the status
+const recentDurationsLimit = 5
+
+// scheduleIntervalSamples is the number of consecutive runs checked for the shortest interval of a schedule
+const scheduleIntervalSamples = 10
+
+// appendRecentDuration adds the duration of the last successful backup keeping only the most recent ones.
+func appendRecentDuration(durations []metav1.Duration, d time.Duration) []metav1.Duration {
+	durations = append(durations, metav1.Duration{Duration: d})
+	if len(durations) > recentDurationsLimit {
+		durations = durations[len(durations)-recentDurationsLimit:]
+	}
+	return durations
+}
+
+// scheduleWarning returns a warning if the shortest interval between the scheduled runs is shorter than the
+// average duration of the recent successful backups, so the runs would overlap or be skipped.
+func scheduleWarning(p cron.Parser, hb *hazelcastv1alpha1.HotBackup, now time.Time) string {
+	if hb.Spec.Schedule == "" || len(hb.Status.RecentDurations) == 0 {
+		return ""
+	}
+	s, err := p.Parse(hb.Spec.Schedule)
+	if err != nil {
+		return ""
+	}
+	interval := shortestInterval(s, now)
+
+	var total time.Duration
+	for _, d := range hb.Status.RecentDurations {
+		total += d.Duration
+	}
+	average := total / time.Duration(len(hb.Status.RecentDurations))
+	if interval <= 0 || interval >= average {
+		return ""
+	}
+	return fmt.Sprintf("Schedule runs every %s but the recent backups took %s on average, runs will overlap",
+		interval, average.Round(time.Second))
+}
+
+func shortestInterval(s cron.Schedule, now time.Time) time.Duration {
+	var shortest time.Duration
+	prev := s.Next(now)
+	for i := 0; i < scheduleIntervalSamples; i++ {
+		next := s.Next(prev)
+		if next.IsZero() {
+			break
+		}
+		if d := next.Sub(prev); shortest == 0 || d < shortest {
+			shortest = d
+		}
+		prev = next
+	}
+	return shortest
+}
+
+// updateScheduleStatus shows whether the schedule of the HotBackup is registered and its next run.
+// The last run is kept if lastRun is zero.
+func (r *HotBackupReconciler) updateScheduleStatus(ctx context.Context, name types.NamespacedName, registered bool, nextRun, lastRun time.Time) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		hb := &hazelcastv1alpha1.HotBackup{}
+		if err := r.Get(ctx, name, hb); err != nil {
+			return err
+		}
+		hb.Status.ScheduleRegistered = registered
+		hb.Status.NextScheduledRun = nil
+		if registered && !nextRun.IsZero() {
+			next := metav1.NewTime(nextRun)
+			hb.Status.NextScheduledRun = &next
+		}
+		if !lastRun.IsZero() {
+			last := metav1.NewTime(lastRun)
+			hb.Status.LastScheduledRun = &last
+		}
+		return r.Status().Update(ctx, hb)
+	})
+}
+
+// missedScheduledRun returns the last scheduled run of the HotBackup missed before now, e.g. while the operator was down.
+// It is zero if no run was missed or the HotBackup has no starting deadline for the missed runs.
+func missedScheduledRun(hb *hazelcastv1alpha1.HotBackup, sched cron.Schedule, now time.Time) time.Time {
+	if hb.Spec.StartingDeadlineSeconds == nil || hb.Status.NextScheduledRun == nil {
+		return time.Time{}
+	}
+	missed := hb.Status.NextScheduledRun.Time
+	if missed.After(now) {
+		return time.Time{}
+	}
+	for next := sched.Next(missed); !next.IsZero() && !next.After(now); next = sched.Next(next) {
+		missed = next
+	}
+	return missed
+}
+
+// startMissedRun starts the missed scheduled run if the starting deadline of the HotBackup did not pass yet,
+// otherwise the run is skipped.
+func (r *HotBackupReconciler) startMissedRun(ctx context.Context, hb *hazelcastv1alpha1.HotBackup, sched cron.Schedule, missed time.Time, hazelcastName types.NamespacedName, logger logr.Logger) {
+	name := types.NamespacedName{Name: hb.Name, Namespace: hb.Namespace}
+	deadline := time.Duration(*hb.Spec.StartingDeadlineSeconds) * time.Second
+	if late := r.clock.Now().Sub(missed); late > deadline {
+		msg := fmt.Sprintf("Scheduled run at %s was skipped, it missed the starting deadline of %s",
+			missed.UTC().Format(time.RFC3339), deadline)
+		logger.Info(msg)
+		r.recorder.Event(hb, corev1.EventTypeWarning, "MissedDeadline", msg)
+		if _, err := r.updateStatus(ctx, name, hbWithStatus(hazelcastv1alpha1.HotBackupSkipped).withMessage(msg)); err != nil {
+			logger.Error(err, "Could not update the status of the missed scheduled run")
+		}
+		return
+	}
+	logger.Info("Starting the missed scheduled run", "scheduledTime", missed)
+	go r.runScheduledBackup(context.Background(), sched, name, hazelcastName, logger)
+}
@@ -0,0 +1,169 @@
+package hazelcast
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	hazelcastv1alpha1 "github.com/hazelcast/hazelcast-platform-operator/api/v1alpha1"
+)
+
+func TestScheduleWarning(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	now := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	hb := &hazelcastv1alpha1.HotBackup{
+		Spec: hazelcastv1alpha1.HotBackupSpec{Schedule: "*/30 * * * *"},
+	}
+	for _, d := range []time.Duration{80 * time.Minute, 100 * time.Minute} {
+		hb.Status.RecentDurations = appendRecentDuration(hb.Status.RecentDurations, d)
+	}
+
+	Expect(scheduleWarning(NewScheduleParser(false), hb, now)).Should(Equal(
+		"Schedule runs every 30m0s but the recent backups took 1h30m0s on average, runs will overlap"))
+
+	hb.Spec.Schedule = "@every 2h"
+	Expect(scheduleWarning(NewScheduleParser(false), hb, now)).Should(BeEmpty())
+
+	for i := 0; i < recentDurationsLimit; i++ {
+		hb.Status.RecentDurations = appendRecentDuration(hb.Status.RecentDurations, time.Minute)
+	}
+	Expect(hb.Status.RecentDurations).Should(HaveLen(recentDurationsLimit))
+	Expect(hb.Status.RecentDurations[0]).Should(Equal(metav1.Duration{Duration: time.Minute}))
+}
+
+func scheduledHotBackupReconciler(t *testing.T, schedule string) (*HotBackupReconciler, *fakeScheduler, types.NamespacedName) {
+	n := types.NamespacedName{Name: "hazelcast", Namespace: "default"}
+	h := &hazelcastv1alpha1.Hazelcast{
+		ObjectMeta: metav1.ObjectMeta{Name: n.Name, Namespace: n.Namespace},
+		Status:     hazelcastv1alpha1.HazelcastStatus{Phase: hazelcastv1alpha1.Running},
+	}
+	hb := &hazelcastv1alpha1.HotBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: n.Name, Namespace: n.Namespace},
+		Spec:       hazelcastv1alpha1.HotBackupSpec{HazelcastResourceName: n.Name, Schedule: schedule},
+	}
+	c := clock.NewFakeClock(time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC))
+	s := newFakeScheduler(c)
+	r := hotBackupReconcilerWithCRs(h, hb)
+	r.cron = s
+	r.clock = c
+	_, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: n})
+	if err != nil {
+		t.Fatalf("Error executing Reconcile: %v", err)
+	}
+	return &r, s, n
+}
+
+func TestHotBackupReconciler_shouldRunScheduledBackupOnSchedule(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	r, s, n := scheduledHotBackupReconciler(t, "0 * * * *")
+	hb := &hazelcastv1alpha1.HotBackup{}
+	Expect(r.Get(context.TODO(), n, hb)).Should(Succeed())
+	Expect(hb.Status.ScheduleRegistered).Should(BeTrue())
+	Expect(hb.Status.NextScheduledRun.Time).Should(BeTemporally("==", time.Date(2022, 6, 1, 1, 0, 0, 0, time.UTC)))
+
+	s.step(30 * time.Minute)
+	hb = &hazelcastv1alpha1.HotBackup{}
+	Expect(r.Get(context.TODO(), n, hb)).Should(Succeed())
+	Expect(hb.Status.LastScheduledRun).Should(BeNil())
+	Expect(hb.Status.State).Should(BeEmpty())
+
+	s.step(30 * time.Minute)
+	hb = &hazelcastv1alpha1.HotBackup{}
+	Expect(r.Get(context.TODO(), n, hb)).Should(Succeed())
+	Expect(hb.Status.LastScheduledRun.Time).Should(BeTemporally("==", time.Date(2022, 6, 1, 1, 0, 0, 0, time.UTC)))
+	Expect(hb.Status.NextScheduledRun.Time).Should(BeTemporally("==", time.Date(2022, 6, 1, 2, 0, 0, 0, time.UTC)))
+	// the backup was started, it fails as there is no client of the cluster
+	Expect(hb.Status.State).Should(Equal(hazelcastv1alpha1.HotBackupFailure))
+}
+
+func TestHotBackupReconciler_shouldNotRunRemovedSchedule(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	r, s, n := scheduledHotBackupReconciler(t, "0 * * * *")
+	Expect(s.Entries()).Should(HaveLen(1))
+
+	Expect(r.removeSchedule(n, ctrl.Log)).Should(BeTrue())
+	Expect(s.Entries()).Should(BeEmpty())
+
+	s.step(2 * time.Hour)
+	hb := &hazelcastv1alpha1.HotBackup{}
+	Expect(r.Get(context.TODO(), n, hb)).Should(Succeed())
+	Expect(hb.Status.LastScheduledRun).Should(BeNil())
+	Expect(hb.Status.State).Should(BeEmpty())
+}
+
+func TestHotBackupReconciler_shouldSkipScheduledBackupInMaintenanceMode(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	r, s, n := scheduledHotBackupReconciler(t, "0 * * * *")
+	r.maintenanceMode = true
+
+	s.step(time.Hour)
+	hb := &hazelcastv1alpha1.HotBackup{}
+	Expect(r.Get(context.TODO(), n, hb)).Should(Succeed())
+	Expect(hb.Status.LastScheduledRun.Time).Should(BeTemporally("==", time.Date(2022, 6, 1, 1, 0, 0, 0, time.UTC)))
+	Expect(hb.Status.State).Should(Equal(hazelcastv1alpha1.HotBackupSkipped))
+	Expect(hb.Status.Message).Should(ContainSubstring("maintenance mode"))
+
+	r.maintenanceMode = false
+	s.step(time.Hour)
+	hb = &hazelcastv1alpha1.HotBackup{}
+	Expect(r.Get(context.TODO(), n, hb)).Should(Succeed())
+	// the backup was started, it fails as there is no client of the cluster
+	Expect(hb.Status.State).Should(Equal(hazelcastv1alpha1.HotBackupFailure))
+}
+
+func TestMissedScheduledRun(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	sched, err := NewScheduleParser(false).Parse("0 * * * *")
+	Expect(err).Should(BeNil())
+	now := time.Date(2022, 6, 1, 3, 30, 0, 0, time.UTC)
+	next := metav1.NewTime(time.Date(2022, 6, 1, 1, 0, 0, 0, time.UTC))
+	hb := &hazelcastv1alpha1.HotBackup{
+		Spec:   hazelcastv1alpha1.HotBackupSpec{Schedule: "0 * * * *", StartingDeadlineSeconds: &[]int64{600}[0]},
+		Status: hazelcastv1alpha1.HotBackupStatus{NextScheduledRun: &next},
+	}
+	Expect(missedScheduledRun(hb, sched, now)).Should(BeTemporally("==", time.Date(2022, 6, 1, 3, 0, 0, 0, time.UTC)))
+
+	Expect(missedScheduledRun(hb, sched, next.Add(-time.Minute))).Should(BeZero())
+
+	hb.Spec.StartingDeadlineSeconds = nil
+	Expect(missedScheduledRun(hb, sched, now)).Should(BeZero())
+}
+
+func TestHotBackupReconciler_shouldSkipRunMissedItsDeadline(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	n := types.NamespacedName{Name: "hazelcast", Namespace: "default"}
+	// the operator was down when the run at 23:00 should have started
+	next := metav1.NewTime(time.Date(2022, 5, 31, 23, 0, 0, 0, time.UTC))
+	hb := &hazelcastv1alpha1.HotBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: n.Name, Namespace: n.Namespace},
+		Spec: hazelcastv1alpha1.HotBackupSpec{
+			HazelcastResourceName:   n.Name,
+			Schedule:                "0 23 * * *",
+			StartingDeadlineSeconds: &[]int64{600}[0],
+		},
+		Status: hazelcastv1alpha1.HotBackupStatus{NextScheduledRun: &next},
+	}
+	h := &hazelcastv1alpha1.Hazelcast{
+		ObjectMeta: metav1.ObjectMeta{Name: n.Name, Namespace: n.Namespace},
+		Status:     hazelcastv1alpha1.HazelcastStatus{Phase: hazelcastv1alpha1.Running},
+	}
+	c := clock.NewFakeClock(time.Date(2022, 6, 1, 2, 0, 0, 0, time.UTC))
+	r := hotBackupReconcilerWithCRs(h, hb)
+	r.cron = newFakeScheduler(c)
+	r.clock = c
+
+	_, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: n})
+	Expect(err).Should(BeNil())
+	hb = &hazelcastv1alpha1.HotBackup{}
+	Expect(r.Get(context.TODO(), n, hb)).Should(Succeed())
+	Expect(hb.Status.State).Should(Equal(hazelcastv1alpha1.HotBackupSkipped))
+	Expect(hb.Status.Message).Should(ContainSubstring("missed the starting deadline"))
+	Expect(hb.Status.NextScheduledRun.Time).Should(BeTemporally("==", time.Date(2022, 6, 1, 23, 0, 0, 0, time.UTC)))
+}
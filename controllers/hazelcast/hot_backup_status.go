@@ -1,13 +1,39 @@
 package hazelcast
 
 import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
 	hazelcastv1alpha1 "github.com/hazelcast/hazelcast-platform-operator/api/v1alpha1"
+	"github.com/hazelcast/hazelcast-platform-operator/internal/backup"
+	"github.com/hazelcast/hazelcast-platform-operator/internal/upload"
 )
 
 type hotBackupOptionsBuilder struct {
-	status  hazelcastv1alpha1.HotBackupState
-	err     error
-	message string
+	status           hazelcastv1alpha1.HotBackupState
+	err              error
+	reason           hazelcastv1alpha1.HotBackupFailureReason
+	message          string
+	compressionLevel int32
+	compressionRatio string
+	backupFolder     string
+	members          []hazelcastv1alpha1.HotBackupMemberStatus
+	memberCount      int32
+	jetSnapshots     bool
+	duration         time.Duration
+	digest           string
+	deltaBase        string
+	localOnly        bool
+	runID            string
+	triggerCause     hazelcastv1alpha1.HotBackupTriggerCause
+	triggerSource    string
+	// compactionProgress is set in the status of the compactions in progress and the finished ones
+	compactionProgress int32
+	effectiveConfig    *hazelcastv1alpha1.HotBackupEffectiveConfig
+	verification       *hazelcastv1alpha1.HotBackupVerificationStatus
 }
 
 func hbWithStatus(s hazelcastv1alpha1.HotBackupState) hotBackupOptionsBuilder {
@@ -16,10 +42,141 @@ func hbWithStatus(s hazelcastv1alpha1.HotBackupState) hotBackupOptionsBuilder {
 	}
 }
 
+// failedHbStatus returns the status of the backup failed with the error, the canceled runs end in the Canceled state.
 func failedHbStatus(err error) hotBackupOptionsBuilder {
+	status := hazelcastv1alpha1.HotBackupFailure
+	if errors.Is(err, errRunCanceled) {
+		status = hazelcastv1alpha1.HotBackupCanceled
+	}
 	return hotBackupOptionsBuilder{
-		status:  hazelcastv1alpha1.HotBackupFailure,
+		status:  status,
 		err:     err,
+		reason:  failureReason(err),
 		message: err.Error(),
 	}
 }
+
+// failureReason returns the reason of the bucket error or the cluster degradation failing the backup, empty for the other errors.
+func failureReason(err error) hazelcastv1alpha1.HotBackupFailureReason {
+	switch {
+	case errors.Is(err, upload.ErrBucketNotFound):
+		return hazelcastv1alpha1.HotBackupReasonBucketNotFound
+	case errors.Is(err, upload.ErrAccessDenied):
+		return hazelcastv1alpha1.HotBackupReasonAccessDenied
+	case errors.Is(err, upload.ErrBucketThrottled):
+		return hazelcastv1alpha1.HotBackupReasonThrottled
+	case errors.Is(err, upload.ErrBucketUnreachable):
+		return hazelcastv1alpha1.HotBackupReasonNetwork
+	case errors.Is(err, upload.ErrDeltaUnsupported):
+		return hazelcastv1alpha1.HotBackupReasonDeltaUnsupported
+	case errors.Is(err, backup.ErrMemberLeft):
+		return hazelcastv1alpha1.HotBackupReasonClusterDegraded
+	case errors.Is(err, upload.ErrAgentUnreachable):
+		return hazelcastv1alpha1.HotBackupReasonAgentUnreachable
+	case errors.Is(err, errRunCanceled):
+		return hazelcastv1alpha1.HotBackupReasonCanceled
+	}
+	return ""
+}
+
+func (o hotBackupOptionsBuilder) withMessage(m string) hotBackupOptionsBuilder {
+	o.message = m
+	return o
+}
+
+func (o hotBackupOptionsBuilder) withCompression(level int32, originalSize, compressedSize int64) hotBackupOptionsBuilder {
+	o.compressionLevel = level
+	if compressedSize > 0 {
+		o.compressionRatio = fmt.Sprintf("%.2f", float64(originalSize)/float64(compressedSize))
+	}
+	return o
+}
+
+func (o hotBackupOptionsBuilder) withBackupFolder(f string) hotBackupOptionsBuilder {
+	o.backupFolder = f
+	return o
+}
+
+func (o hotBackupOptionsBuilder) withMembers(m []hazelcastv1alpha1.HotBackupMemberStatus) hotBackupOptionsBuilder {
+	o.members = m
+	return o
+}
+
+func (o hotBackupOptionsBuilder) withSourceMemberCount(c int32) hotBackupOptionsBuilder {
+	o.memberCount = c
+	return o
+}
+
+func (o hotBackupOptionsBuilder) withJetSnapshots(included bool) hotBackupOptionsBuilder {
+	o.jetSnapshots = included
+	return o
+}
+
+func (o hotBackupOptionsBuilder) withDuration(d time.Duration) hotBackupOptionsBuilder {
+	o.duration = d
+	return o
+}
+
+func (o hotBackupOptionsBuilder) withDigest(d string) hotBackupOptionsBuilder {
+	o.digest = d
+	return o
+}
+
+func (o hotBackupOptionsBuilder) withDeltaBase(b string) hotBackupOptionsBuilder {
+	o.deltaBase = b
+	return o
+}
+
+func (o hotBackupOptionsBuilder) withLocalOnly(l bool) hotBackupOptionsBuilder {
+	o.localOnly = l
+	return o
+}
+
+func (o hotBackupOptionsBuilder) withRunID(id string) hotBackupOptionsBuilder {
+	o.runID = id
+	return o
+}
+
+// withTrigger sets the cause of the run, it is set in the status together with the run ID.
+func (o hotBackupOptionsBuilder) withTrigger(cause hazelcastv1alpha1.HotBackupTriggerCause, source string) hotBackupOptionsBuilder {
+	o.triggerCause = cause
+	o.triggerSource = source
+	return o
+}
+
+func (o hotBackupOptionsBuilder) withCompactionProgress(p int32) hotBackupOptionsBuilder {
+	o.compactionProgress = p
+	return o
+}
+
+func (o hotBackupOptionsBuilder) withEffectiveConfig(c *hazelcastv1alpha1.HotBackupEffectiveConfig) hotBackupOptionsBuilder {
+	o.effectiveConfig = c
+	return o
+}
+
+func (o hotBackupOptionsBuilder) withVerification(v *hazelcastv1alpha1.HotBackupVerificationStatus) hotBackupOptionsBuilder {
+	o.verification = v
+	return o
+}
+
+// agentVersionMismatch returns a message listing the versions of the backup agents if the members run different ones,
+// e.g. in the middle of a rolling upgrade.
+func agentVersionMismatch(members []hazelcastv1alpha1.HotBackupMemberStatus) string {
+	var versions []string
+	seen := make(map[string]struct{})
+	for _, m := range members {
+		if m.AgentVersion == "" {
+			continue
+		}
+		if _, ok := seen[m.AgentVersion]; ok {
+			continue
+		}
+		seen[m.AgentVersion] = struct{}{}
+		versions = append(versions, m.AgentVersion)
+	}
+	if len(versions) < 2 {
+		return ""
+	}
+	sort.Strings(versions)
+	return fmt.Sprintf("backup agents of the members run different versions: %s", strings.Join(versions, ", "))
+}
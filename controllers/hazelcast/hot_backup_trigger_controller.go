@@ -0,0 +1,235 @@
+package hazelcast
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	hazelcastv1alpha1 "github.com/hazelcast/hazelcast-platform-operator/api/v1alpha1"
+	n "github.com/hazelcast/hazelcast-platform-operator/internal/naming"
+	"github.com/hazelcast/hazelcast-platform-operator/internal/util"
+)
+
+// HotBackupTriggerReconciler reconciles a HotBackupTrigger object
+type HotBackupTriggerReconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+}
+
+func NewHotBackupTriggerReconciler(c client.Client, log logr.Logger, s *runtime.Scheme) *HotBackupTriggerReconciler {
+	return &HotBackupTriggerReconciler{
+		Client: c,
+		Log:    log,
+		Scheme: s,
+	}
+}
+
+//+kubebuilder:rbac:groups=hazelcast.com,resources=hotbackuptriggers,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=hazelcast.com,resources=hotbackuptriggers/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=hazelcast.com,resources=hotbackuptriggers/finalizers,verbs=update
+
+func (r *HotBackupTriggerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := r.Log.WithValues("hazelcast-hot-backup-trigger", req.Name)
+
+	t := &hazelcastv1alpha1.HotBackupTrigger{}
+	if err := r.Get(ctx, req.NamespacedName, t); err != nil {
+		if kerrors.IsNotFound(err) {
+			logger.V(util.DebugLevel).Info("Could not find HotBackupTrigger, it is probably already deleted")
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	if t.Status.State.IsFinished() {
+		return ctrl.Result{}, nil
+	}
+
+	hzs, hbs, err := r.listTriggerTargets(ctx, t)
+	if err != nil {
+		return updateHotBackupTriggerStatus(ctx, r.Client, t, failedHbStatus(err))
+	}
+
+	backedUp := make(map[string]struct{}, len(hbs))
+	var running int32
+	t.Status.HotBackups = nil
+	t.Status.Succeeded = 0
+	t.Status.Failed = 0
+	for _, hb := range hbs {
+		backedUp[hb.Namespace+"/"+hb.Spec.HazelcastResourceName] = struct{}{}
+		t.Status.HotBackups = append(t.Status.HotBackups, hb.Namespace+"/"+hb.Name)
+		switch hb.Status.State {
+		case hazelcastv1alpha1.HotBackupSuccess:
+			t.Status.Succeeded++
+		case hazelcastv1alpha1.HotBackupFailure, hazelcastv1alpha1.HotBackupCanceled, hazelcastv1alpha1.HotBackupPartialSuccess:
+			t.Status.Failed++
+		case hazelcastv1alpha1.HotBackupSkipped:
+			// the backups of the cluster were disabled after the HotBackup was created
+		default:
+			running++
+		}
+	}
+
+	if err := validateTriggerBucket(t, hzs, backedUp); err != nil {
+		return updateHotBackupTriggerStatus(ctx, r.Client, t, failedHbStatus(err))
+	}
+
+	var waiting int
+	for i := range hzs {
+		h := &hzs[i]
+		if !h.Spec.Persistence.IsEnabled() || isBackupDisabled(h) {
+			continue
+		}
+		if _, ok := backedUp[h.Namespace+"/"+h.Name]; ok {
+			continue
+		}
+		if t.Spec.MaxConcurrentBackups > 0 && running >= t.Spec.MaxConcurrentBackups {
+			waiting++
+			continue
+		}
+		hb, err := r.createHotBackup(ctx, t, h)
+		if err != nil {
+			return updateHotBackupTriggerStatus(ctx, r.Client, t, failedHbStatus(err))
+		}
+		logger.Info("Created HotBackup", "namespace", hb.Namespace, "name", hb.Name)
+		t.Status.HotBackups = append(t.Status.HotBackups, hb.Namespace+"/"+hb.Name)
+		running++
+	}
+
+	if running > 0 || waiting > 0 {
+		return updateHotBackupTriggerStatus(ctx, r.Client, t, hbWithStatus(hazelcastv1alpha1.HotBackupInProgress).
+			withMessage(fmt.Sprintf("%d HotBackups running, %d clusters waiting", running, waiting)))
+	}
+	if t.Status.Failed > 0 {
+		return updateHotBackupTriggerStatus(ctx, r.Client, t, hbWithStatus(hazelcastv1alpha1.HotBackupFailure).
+			withMessage(fmt.Sprintf("%d HotBackups failed", t.Status.Failed)))
+	}
+	return updateHotBackupTriggerStatus(ctx, r.Client, t, hbWithStatus(hazelcastv1alpha1.HotBackupSuccess))
+}
+
+// listTriggerTargets returns the Hazelcast resources in the namespaces of the trigger and the HotBackups the trigger created for them.
+func (r *HotBackupTriggerReconciler) listTriggerTargets(ctx context.Context, t *hazelcastv1alpha1.HotBackupTrigger) ([]hazelcastv1alpha1.Hazelcast, []hazelcastv1alpha1.HotBackup, error) {
+	namespaces := t.Spec.Namespaces
+	if len(namespaces) == 0 {
+		// all the namespaces watched by the operator
+		namespaces = []string{""}
+	}
+	var hzs []hazelcastv1alpha1.Hazelcast
+	var hbs []hazelcastv1alpha1.HotBackup
+	for _, ns := range namespaces {
+		hzList := &hazelcastv1alpha1.HazelcastList{}
+		if err := r.List(ctx, hzList, client.InNamespace(ns)); err != nil {
+			return nil, nil, err
+		}
+		hzs = append(hzs, hzList.Items...)
+
+		hbList := &hazelcastv1alpha1.HotBackupList{}
+		if err := r.List(ctx, hbList, client.InNamespace(ns), client.MatchingLabels{n.HotBackupTriggerLabel: t.Name}); err != nil {
+			return nil, nil, err
+		}
+		hbs = append(hbs, hbList.Items...)
+	}
+	return hzs, hbs, nil
+}
+
+// validateTriggerBucket returns an error if a cluster with external backups is still to be backed up by the trigger
+// but the trigger has no bucket, so no HotBackup is created for any of the clusters.
+func validateTriggerBucket(t *hazelcastv1alpha1.HotBackupTrigger, hzs []hazelcastv1alpha1.Hazelcast, backedUp map[string]struct{}) error {
+	if t.Spec.BucketURI != "" && t.Spec.Secret != "" {
+		return nil
+	}
+	for i := range hzs {
+		h := &hzs[i]
+		if !h.Spec.Persistence.IsExternal() || isBackupDisabled(h) {
+			continue
+		}
+		if _, ok := backedUp[h.Namespace+"/"+h.Name]; ok {
+			continue
+		}
+		return fmt.Errorf("Hazelcast %s/%s uses external backups, bucketURI and secret of the trigger must be set", h.Namespace, h.Name)
+	}
+	return nil
+}
+
+// triggerHotBackupName returns the name of the HotBackup of the cluster created by the trigger. The hash of both names
+// keeps the names of different clusters and triggers apart, the rest is truncated to fit the names of the resources.
+func triggerHotBackupName(t *hazelcastv1alpha1.HotBackupTrigger, h *hazelcastv1alpha1.Hazelcast) string {
+	sum := sha256.Sum256([]byte(t.Name + "/" + h.Namespace + "/" + h.Name))
+	hash := hex.EncodeToString(sum[:])[:8]
+	name := t.Name + "-" + h.Name
+	if max := validation.DNS1123LabelMaxLength - len(hash) - 1; len(name) > max {
+		name = strings.TrimRight(name[:max], "-.")
+	}
+	return name + "-" + hash
+}
+
+func (r *HotBackupTriggerReconciler) createHotBackup(ctx context.Context, t *hazelcastv1alpha1.HotBackupTrigger, h *hazelcastv1alpha1.Hazelcast) (*hazelcastv1alpha1.HotBackup, error) {
+	hb := &hazelcastv1alpha1.HotBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      triggerHotBackupName(t, h),
+			Namespace: h.Namespace,
+			Labels: map[string]string{
+				n.HotBackupTriggerLabel: t.Name,
+			},
+		},
+		Spec: hazelcastv1alpha1.HotBackupSpec{
+			HazelcastResourceName: h.Name,
+		},
+	}
+	if h.Spec.Persistence.IsExternal() {
+		hb.Spec.BucketURI = t.Spec.BucketURI
+		hb.Spec.Secret = t.Spec.Secret
+	}
+	if err := controllerutil.SetControllerReference(t, hb, r.Scheme); err != nil {
+		return nil, fmt.Errorf("failed to set owner reference on HotBackup: %w", err)
+	}
+	err := r.Create(ctx, hb)
+	if err == nil {
+		return hb, nil
+	}
+	if !kerrors.IsAlreadyExists(err) {
+		return nil, err
+	}
+	// the HotBackup created by an earlier reconcile is not listed yet, any other one is not backing up the cluster for the trigger
+	existing := &hazelcastv1alpha1.HotBackup{}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(hb), existing); err != nil {
+		return nil, err
+	}
+	if existing.Labels[n.HotBackupTriggerLabel] != t.Name || !metav1.IsControlledBy(existing, t) {
+		return nil, fmt.Errorf("HotBackup %s/%s already exists and was not created by the trigger", existing.Namespace, existing.Name)
+	}
+	return existing, nil
+}
+
+func updateHotBackupTriggerStatus(ctx context.Context, c client.Client, t *hazelcastv1alpha1.HotBackupTrigger, options hotBackupOptionsBuilder) (ctrl.Result, error) {
+	t.Status.State = options.status
+	t.Status.Message = options.message
+	if err := c.Status().Update(ctx, t); err != nil {
+		if kerrors.IsConflict(err) {
+			return ctrl.Result{Requeue: true}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	if options.status == hazelcastv1alpha1.HotBackupFailure {
+		return ctrl.Result{}, options.err
+	}
+	return ctrl.Result{}, nil
+}
+
+func (r *HotBackupTriggerReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&hazelcastv1alpha1.HotBackupTrigger{}).
+		Owns(&hazelcastv1alpha1.HotBackup{}).
+		Complete(r)
+}
@@ -0,0 +1,173 @@
+package hazelcast
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	hazelcastv1alpha1 "github.com/hazelcast/hazelcast-platform-operator/api/v1alpha1"
+	n "github.com/hazelcast/hazelcast-platform-operator/internal/naming"
+)
+
+func TestHotBackupTriggerReconciler_shouldBackupClustersRespectingConcurrency(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	tn := types.NamespacedName{Name: "backup-all"}
+	trigger := &hazelcastv1alpha1.HotBackupTrigger{
+		ObjectMeta: metav1.ObjectMeta{Name: tn.Name},
+		Spec:       hazelcastv1alpha1.HotBackupTriggerSpec{MaxConcurrentBackups: 1},
+	}
+	persistent := func(name string) *hazelcastv1alpha1.Hazelcast {
+		return &hazelcastv1alpha1.Hazelcast{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: hazelcastv1alpha1.HazelcastSpec{
+				Persistence: &hazelcastv1alpha1.HazelcastPersistenceConfiguration{BaseDir: "/data/hot-restart"},
+			},
+		}
+	}
+	inMemory := &hazelcastv1alpha1.Hazelcast{
+		ObjectMeta: metav1.ObjectMeta{Name: "in-memory", Namespace: "default"},
+	}
+
+	c := fakeClient(trigger, persistent("hz-1"), persistent("hz-2"), inMemory)
+	r := NewHotBackupTriggerReconciler(c, ctrl.Log.WithName("test").WithName("HotBackupTrigger"), c.Scheme())
+
+	reconcileAndFinishBackups := func(expectedBackups int) {
+		_, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: tn})
+		Expect(err).Should(BeNil())
+		hbs := &hazelcastv1alpha1.HotBackupList{}
+		Expect(c.List(context.TODO(), hbs, client.MatchingLabels{n.HotBackupTriggerLabel: tn.Name})).Should(Succeed())
+		Expect(hbs.Items).Should(HaveLen(expectedBackups))
+		for i := range hbs.Items {
+			hbs.Items[i].Status.State = hazelcastv1alpha1.HotBackupSuccess
+			Expect(c.Status().Update(context.TODO(), &hbs.Items[i])).Should(Succeed())
+		}
+	}
+
+	reconcileAndFinishBackups(1)
+	Expect(c.Get(context.TODO(), tn, trigger)).Should(Succeed())
+	Expect(trigger.Status.State).Should(Equal(hazelcastv1alpha1.HotBackupInProgress))
+
+	reconcileAndFinishBackups(2)
+	reconcileAndFinishBackups(2)
+	Expect(c.Get(context.TODO(), tn, trigger)).Should(Succeed())
+	Expect(trigger.Status.State).Should(Equal(hazelcastv1alpha1.HotBackupSuccess))
+	Expect(trigger.Status.Succeeded).Should(Equal(int32(2)))
+	Expect(trigger.Status.HotBackups).Should(ConsistOf(
+		"default/"+triggerHotBackupName(trigger, persistent("hz-1")),
+		"default/"+triggerHotBackupName(trigger, persistent("hz-2"))))
+}
+
+func TestHotBackupTriggerReconciler_shouldBackupClustersInTriggerNamespaces(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	tn := types.NamespacedName{Name: "backup-prod"}
+	trigger := &hazelcastv1alpha1.HotBackupTrigger{
+		ObjectMeta: metav1.ObjectMeta{Name: tn.Name},
+		Spec:       hazelcastv1alpha1.HotBackupTriggerSpec{Namespaces: []string{"prod"}},
+	}
+	persistent := func(namespace string) *hazelcastv1alpha1.Hazelcast {
+		return &hazelcastv1alpha1.Hazelcast{
+			ObjectMeta: metav1.ObjectMeta{Name: "hazelcast", Namespace: namespace},
+			Spec: hazelcastv1alpha1.HazelcastSpec{
+				Persistence: &hazelcastv1alpha1.HazelcastPersistenceConfiguration{BaseDir: "/data/hot-restart"},
+			},
+		}
+	}
+
+	c := fakeClient(trigger, persistent("prod"), persistent("dev"))
+	r := NewHotBackupTriggerReconciler(c, ctrl.Log.WithName("test").WithName("HotBackupTrigger"), c.Scheme())
+
+	_, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: tn})
+	Expect(err).Should(BeNil())
+	hbs := &hazelcastv1alpha1.HotBackupList{}
+	Expect(c.List(context.TODO(), hbs, client.MatchingLabels{n.HotBackupTriggerLabel: tn.Name})).Should(Succeed())
+	Expect(hbs.Items).Should(HaveLen(1))
+	Expect(hbs.Items[0].Namespace).Should(Equal("prod"))
+}
+
+func TestHotBackupTriggerReconciler_shouldFailOnForeignHotBackup(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	tn := types.NamespacedName{Name: "backup-all"}
+	trigger := &hazelcastv1alpha1.HotBackupTrigger{ObjectMeta: metav1.ObjectMeta{Name: tn.Name}}
+	h := &hazelcastv1alpha1.Hazelcast{
+		ObjectMeta: metav1.ObjectMeta{Name: "hazelcast", Namespace: "default"},
+		Spec: hazelcastv1alpha1.HazelcastSpec{
+			Persistence: &hazelcastv1alpha1.HazelcastPersistenceConfiguration{BaseDir: "/data/hot-restart"},
+		},
+	}
+	// created by the user with the name the trigger would use
+	foreign := &hazelcastv1alpha1.HotBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: triggerHotBackupName(trigger, h), Namespace: "default"},
+		Spec:       hazelcastv1alpha1.HotBackupSpec{HazelcastResourceName: "other"},
+	}
+
+	c := fakeClient(trigger, h, foreign)
+	r := NewHotBackupTriggerReconciler(c, ctrl.Log.WithName("test").WithName("HotBackupTrigger"), c.Scheme())
+
+	_, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: tn})
+	Expect(err).ShouldNot(BeNil())
+	Expect(c.Get(context.TODO(), tn, trigger)).Should(Succeed())
+	Expect(trigger.Status.State).Should(Equal(hazelcastv1alpha1.HotBackupFailure))
+	Expect(trigger.Status.Message).Should(ContainSubstring("was not created by the trigger"))
+}
+
+func TestHotBackupTriggerReconciler_shouldRequireBucketForExternalBackups(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	tn := types.NamespacedName{Name: "backup-all"}
+	trigger := &hazelcastv1alpha1.HotBackupTrigger{ObjectMeta: metav1.ObjectMeta{Name: tn.Name}}
+	local := &hazelcastv1alpha1.Hazelcast{
+		ObjectMeta: metav1.ObjectMeta{Name: "local", Namespace: "default"},
+		Spec: hazelcastv1alpha1.HazelcastSpec{
+			Persistence: &hazelcastv1alpha1.HazelcastPersistenceConfiguration{BaseDir: "/data/hot-restart"},
+		},
+	}
+	external := &hazelcastv1alpha1.Hazelcast{
+		ObjectMeta: metav1.ObjectMeta{Name: "external", Namespace: "default"},
+		Spec: hazelcastv1alpha1.HazelcastSpec{
+			Persistence: &hazelcastv1alpha1.HazelcastPersistenceConfiguration{
+				BaseDir:    "/data/hot-restart",
+				BackupType: hazelcastv1alpha1.External,
+			},
+		},
+	}
+
+	c := fakeClient(trigger, local, external)
+	r := NewHotBackupTriggerReconciler(c, ctrl.Log.WithName("test").WithName("HotBackupTrigger"), c.Scheme())
+
+	_, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: tn})
+	Expect(err).ShouldNot(BeNil())
+	Expect(c.Get(context.TODO(), tn, trigger)).Should(Succeed())
+	Expect(trigger.Status.State).Should(Equal(hazelcastv1alpha1.HotBackupFailure))
+	// none of the clusters is backed up
+	hbs := &hazelcastv1alpha1.HotBackupList{}
+	Expect(c.List(context.TODO(), hbs)).Should(Succeed())
+	Expect(hbs.Items).Should(BeEmpty())
+}
+
+func Test_triggerHotBackupName(t *testing.T) {
+	trigger := &hazelcastv1alpha1.HotBackupTrigger{ObjectMeta: metav1.ObjectMeta{Name: "a-b"}}
+	other := &hazelcastv1alpha1.HotBackupTrigger{ObjectMeta: metav1.ObjectMeta{Name: "a"}}
+	hz := func(name string) *hazelcastv1alpha1.Hazelcast {
+		return &hazelcastv1alpha1.Hazelcast{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}
+	}
+
+	// a-b + c and a + b-c would both be a-b-c without the hash
+	if triggerHotBackupName(trigger, hz("c")) == triggerHotBackupName(other, hz("b-c")) {
+		t.Error("triggerHotBackupName() is the same for different triggers and clusters")
+	}
+	long := hz(strings.Repeat("hazelcast", 10))
+	name := triggerHotBackupName(trigger, long)
+	if len(name) > validation.DNS1123LabelMaxLength {
+		t.Errorf("triggerHotBackupName() = %q is %d characters long", name, len(name))
+	}
+	if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+		t.Errorf("triggerHotBackupName() = %q is not a valid name: %v", name, errs)
+	}
+}
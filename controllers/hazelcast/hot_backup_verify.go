@@ -0,0 +1,74 @@
+package hazelcast
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"go.opentelemetry.io/otel/attribute"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	hazelcastv1alpha1 "github.com/hazelcast/hazelcast-platform-operator/api/v1alpha1"
+	"github.com/hazelcast/hazelcast-platform-operator/internal/tracing"
+	"github.com/hazelcast/hazelcast-platform-operator/internal/upload"
+)
+
+// verifyBackup verifies the uploaded backup referenced by the HotBackup using the backup agent of a member
+// instead of taking a new backup.
+func (r *HotBackupReconciler) verifyBackup(ctx context.Context, hb *hazelcastv1alpha1.HotBackup, hz *hazelcastv1alpha1.Hazelcast, logger logr.Logger) (ctrl.Result, error) {
+	started := r.clock.Now()
+	backupName := types.NamespacedName{Name: hb.Name, Namespace: hb.Namespace}
+	folder := hb.Spec.Verify.BackupFolder
+
+	addresses := memberAddresses(types.NamespacedName{Name: hz.Name, Namespace: hz.Namespace})
+	if len(addresses) == 0 {
+		return r.updateStatus(ctx, backupName, failedHbStatus(fmt.Errorf("no member of Hazelcast %s is available to verify the backup", hz.Name)))
+	}
+
+	sampleRate := int32(100)
+	if hb.Spec.VerifySampleRate != nil {
+		sampleRate = *hb.Spec.VerifySampleRate
+	}
+	logger.Info("Verifying backup", "backupFolder", folder, "restoreTest", hb.Spec.Verify.RestoreTest, "sampleRate", sampleRate)
+	config := &upload.Config{
+		BucketURI:  hb.Spec.BucketURI,
+		SecretName: hb.Spec.Secret,
+	}
+	_, span := tracing.Start(ctx, "verify", attribute.String("backup.folder", folder),
+		attribute.Bool("verify.restore_test", hb.Spec.Verify.RestoreTest), attribute.Int("verify.sample_rate", int(sampleRate)))
+	v, err := upload.Verify(ctx, addresses[0], config, folder, hb.Spec.Verify.RestoreTest, sampleRate)
+	tracing.End(span, err)
+	if err != nil {
+		return r.updateStatus(ctx, backupName, failedHbStatus(fmt.Errorf("verification of backup %s failed: %w", folder, err)).
+			withVerification(verificationStatus(v, sampleRate, hazelcastv1alpha1.HotBackupVerificationFailed)))
+	}
+
+	message := fmt.Sprintf("Backup %s verified", folder)
+	if sampleRate < 100 {
+		message = fmt.Sprintf("Backup %s verified, %d of %d objects sampled", folder, v.SampledObjects, v.TotalObjects)
+	}
+	result, err := r.updateStatus(ctx, backupName, hbWithStatus(hazelcastv1alpha1.HotBackupSuccess).
+		withMessage(message).
+		withBackupFolder(folder).
+		withDigest(v.Digest).
+		withVerification(verificationStatus(v, sampleRate, hazelcastv1alpha1.HotBackupVerificationPassed)).
+		withDuration(r.clock.Since(started)))
+	if err != nil {
+		return result, err
+	}
+	if err := r.updateLastSuccessfulConfiguration(ctx, backupName, logger); err != nil {
+		logger.Error(err, "Could not save the current successful spec as annotation to the custom resource")
+	}
+	return result, nil
+}
+
+// verificationStatus returns the status of the verification, the sample is not known if the agent did not report it.
+func verificationStatus(v *upload.Verification, sampleRate int32, result hazelcastv1alpha1.HotBackupVerificationResult) *hazelcastv1alpha1.HotBackupVerificationStatus {
+	s := &hazelcastv1alpha1.HotBackupVerificationStatus{SampleRate: sampleRate, Result: result}
+	if v != nil {
+		s.SampledObjects = v.SampledObjects
+		s.TotalObjects = v.TotalObjects
+	}
+	return s
+}
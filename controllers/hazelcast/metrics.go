@@ -0,0 +1,74 @@
+package hazelcast
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	hazelcastv1alpha1 "github.com/hazelcast/hazelcast-platform-operator/api/v1alpha1"
+)
+
+var (
+	hotBackupLastSuccess = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "hazelcast_hotbackup_last_success_timestamp_seconds",
+			Help: "Unix time of the last successful backup of the HotBackup resource.",
+		},
+		[]string{"namespace", "name"},
+	)
+
+	hotBackupMembersSkipped = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "hazelcast_hotbackup_members_skipped_total",
+			Help: "Number of members left out of the successful backups of the HotBackup resource.",
+		},
+		[]string{"namespace", "name"},
+	)
+
+	// hotBackupReconcileDuration complements the workqueue and controller metrics of controller-runtime
+	// which are registered in the same registry.
+	hotBackupReconcileDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "hazelcast_hotbackup_reconcile_duration_seconds",
+			Help:    "Duration of the reconciliations of the HotBackup resources.",
+			Buckets: prometheus.ExponentialBuckets(0.005, 2, 12),
+		},
+		[]string{"result"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(hotBackupLastSuccess, hotBackupMembersSkipped, hotBackupReconcileDuration)
+}
+
+func observeHotBackupReconcile(duration time.Duration, result ctrl.Result, err error) {
+	label := "success"
+	switch {
+	case err != nil:
+		label = "error"
+	case result.Requeue || result.RequeueAfter > 0:
+		label = "requeue"
+	}
+	hotBackupReconcileDuration.WithLabelValues(label).Observe(duration.Seconds())
+}
+
+// setHotBackupLastSuccess sets the time of the last successful backup of the HotBackup from its status, so the
+// gauge keeps the time across the restarts of the operator.
+func setHotBackupLastSuccess(hb *hazelcastv1alpha1.HotBackup) {
+	if hb.Status.LastSuccessTime == nil {
+		return
+	}
+	hotBackupLastSuccess.WithLabelValues(hb.Namespace, hb.Name).Set(float64(hb.Status.LastSuccessTime.Unix()))
+}
+
+func addHotBackupMembersSkipped(name types.NamespacedName, n int) {
+	hotBackupMembersSkipped.WithLabelValues(name.Namespace, name.Name).Add(float64(n))
+}
+
+func deleteHotBackupMetrics(name types.NamespacedName) {
+	hotBackupLastSuccess.DeleteLabelValues(name.Namespace, name.Name)
+	hotBackupMembersSkipped.DeleteLabelValues(name.Namespace, name.Name)
+}
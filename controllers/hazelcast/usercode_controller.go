@@ -0,0 +1,284 @@
+package hazelcast
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	hazelcastv1alpha1 "github.com/hazelcast/hazelcast-platform-operator/api/v1alpha1"
+	n "github.com/hazelcast/hazelcast-platform-operator/internal/naming"
+	"github.com/hazelcast/hazelcast-platform-operator/internal/upload"
+	"github.com/hazelcast/hazelcast-platform-operator/internal/util"
+)
+
+// userCodeCheckInterval is the time between two checks of the objects in the bucket of a UserCode
+const userCodeCheckInterval = 5 * time.Minute
+
+// UserCodeReconciler reconciles a UserCode object
+type UserCodeReconciler struct {
+	client.Client
+	Log    logr.Logger
+	Scheme *runtime.Scheme
+}
+
+func NewUserCodeReconciler(c client.Client, log logr.Logger, s *runtime.Scheme) *UserCodeReconciler {
+	return &UserCodeReconciler{
+		Client: c,
+		Log:    log,
+		Scheme: s,
+	}
+}
+
+//+kubebuilder:rbac:groups=hazelcast.com,resources=usercodes,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=hazelcast.com,resources=usercodes/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=hazelcast.com,resources=usercodes/finalizers,verbs=update
+
+func (r *UserCodeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := r.Log.WithValues("hazelcast-user-code", req.NamespacedName)
+	ctx = upload.WithNamespace(ctx, req.Namespace)
+
+	uc := &hazelcastv1alpha1.UserCode{}
+	if err := r.Get(ctx, req.NamespacedName, uc); err != nil {
+		if kerrors.IsNotFound(err) {
+			logger.V(util.DebugLevel).Info("Could not find UserCode, it is probably already deleted")
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	if uc.GetDeletionTimestamp() != nil {
+		if err := r.executeFinalizer(ctx, uc); err != nil {
+			return updateUserCodeStatus(ctx, r.Client, uc, userCodeFailedStatus(err))
+		}
+		return ctrl.Result{}, nil
+	}
+
+	if !controllerutil.ContainsFinalizer(uc, n.Finalizer) {
+		controllerutil.AddFinalizer(uc, n.Finalizer)
+		if err := r.Update(ctx, uc); err != nil {
+			return updateUserCodeStatus(ctx, r.Client, uc, userCodeFailedStatus(err))
+		}
+	}
+
+	if uc.Spec.BucketConfiguration == nil && len(uc.Spec.ConfigMaps) == 0 {
+		return updateUserCodeStatus(ctx, r.Client, uc, userCodeFailedStatus(errors.New("either bucketConfig or configMaps must be set")))
+	}
+
+	hzName := types.NamespacedName{Name: uc.Spec.HazelcastResourceName, Namespace: uc.Namespace}
+	digest, err := r.userCodeDigest(ctx, uc, hzName, logger)
+	if err != nil {
+		return updateUserCodeStatus(ctx, r.Client, uc, userCodeFailedStatus(err))
+	}
+
+	if uc.Status.State == hazelcastv1alpha1.UserCodeSuccess && uc.Status.ObservedGeneration == uc.Generation &&
+		uc.Status.Digest == digest {
+		return userCodeResult(uc), nil
+	}
+
+	var restarted bool
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		h := &hazelcastv1alpha1.Hazelcast{}
+		if err := r.Get(ctx, hzName, h); err != nil {
+			if kerrors.IsNotFound(err) {
+				return fmt.Errorf("could not deploy user code: Hazelcast resource %s not found", hzName.Name)
+			}
+			return err
+		}
+		if err := checkUserCodeOwner(h, uc); err != nil {
+			return err
+		}
+		cc := userCodeCustomClass(uc, h.Spec.CustomClass)
+		if reflect.DeepEqual(cc, h.Spec.CustomClass) && h.Annotations[n.UserCodeDigestAnnotation] == digest {
+			restarted = false
+			return nil
+		}
+		if h.Annotations == nil {
+			h.Annotations = make(map[string]string)
+		}
+		h.Annotations[n.UserCodeAnnotation] = uc.Name
+		h.Annotations[n.UserCodeDigestAnnotation] = digest
+		h.Spec.CustomClass = cc
+		restarted = true
+		return r.Update(ctx, h)
+	})
+	if err != nil {
+		return updateUserCodeStatus(ctx, r.Client, uc, userCodeFailedStatus(err))
+	}
+
+	if restarted {
+		logger.Info("User code deployed, restarting the Hazelcast members", "hazelcast", hzName.Name, "digest", digest)
+		return updateUserCodeStatus(ctx, r.Client, uc, userCodeSuccessStatus().
+			withDigest(digest).
+			withMessage("Hazelcast members are restarted to load the user code"))
+	}
+	return updateUserCodeStatus(ctx, r.Client, uc, userCodeSuccessStatus().withDigest(digest))
+}
+
+// userCodeResult requeues the UserCode with a bucket to check its objects again, the ConfigMaps are watched.
+func userCodeResult(uc *hazelcastv1alpha1.UserCode) ctrl.Result {
+	if uc.Spec.BucketConfiguration != nil {
+		return ctrl.Result{RequeueAfter: userCodeCheckInterval}
+	}
+	return ctrl.Result{}
+}
+
+// userCodeDigest returns the digest of the contents of the ConfigMaps and the objects in the bucket of the UserCode.
+// The objects are read by the backup agent of a member. The last deployed digest is kept while they cannot be read,
+// so the members are not restarted while the cluster or the agent is unavailable.
+func (r *UserCodeReconciler) userCodeDigest(ctx context.Context, uc *hazelcastv1alpha1.UserCode, hzName types.NamespacedName, logger logr.Logger) (string, error) {
+	hash := sha256.New()
+	for _, name := range uc.Spec.ConfigMaps {
+		cm := &corev1.ConfigMap{}
+		if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: uc.Namespace}, cm); err != nil {
+			if kerrors.IsNotFound(err) {
+				// the digest changes once the ConfigMap is created
+				fmt.Fprintf(hash, "configmap:%s\x00", name)
+				continue
+			}
+			return "", err
+		}
+		fmt.Fprintf(hash, "configmap:%s\x00", name)
+		files := make(map[string]string, len(cm.Data)+len(cm.BinaryData))
+		keys := make([]string, 0, len(cm.Data)+len(cm.BinaryData))
+		for k, v := range cm.Data {
+			files[k] = v
+			keys = append(keys, k)
+		}
+		for k, v := range cm.BinaryData {
+			files[k] = string(v)
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Fprintf(hash, "%s\x00%s\x00", k, files[k])
+		}
+	}
+	if b := uc.Spec.BucketConfiguration; b != nil {
+		d, err := r.bucketDigest(ctx, b, hzName)
+		if err != nil {
+			logger.Info("Could not check the objects in the bucket of the user code", "reason", err.Error())
+			return uc.Status.Digest, nil
+		}
+		fmt.Fprintf(hash, "bucket:%s\x00%s\x00", b.BucketURI, d)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// bucketDigest returns the digest of the objects in the bucket computed by the backup agent of a member of the cluster.
+func (r *UserCodeReconciler) bucketDigest(ctx context.Context, b *hazelcastv1alpha1.BucketConfiguration, hzName types.NamespacedName) (string, error) {
+	h := &hazelcastv1alpha1.Hazelcast{}
+	if err := r.Get(ctx, hzName, h); err != nil {
+		return "", err
+	}
+	if !agentSupportsFeatures(h) {
+		return "", fmt.Errorf("agent %s cannot check the bucket, use agent version %s or later", h.Spec.Agent.Version, n.MinAgentVersion)
+	}
+	addresses := memberAddresses(hzName)
+	if len(addresses) == 0 {
+		return "", fmt.Errorf("no members of Hazelcast %s are connected", hzName.Name)
+	}
+	return upload.ObjectsDigest(ctx, addresses[0], &upload.Config{BucketURI: b.BucketURI, SecretName: b.Secret})
+}
+
+// configMapUpdates returns the UserCodes deploying the ConfigMap.
+func (r *UserCodeReconciler) configMapUpdates(cm client.Object) []reconcile.Request {
+	ucList := &hazelcastv1alpha1.UserCodeList{}
+	if err := r.List(context.Background(), ucList, client.InNamespace(cm.GetNamespace())); err != nil {
+		return []reconcile.Request{}
+	}
+	var requests []reconcile.Request
+	for _, uc := range ucList.Items {
+		for _, name := range uc.Spec.ConfigMaps {
+			if name == cm.GetName() {
+				requests = append(requests, reconcile.Request{
+					NamespacedName: types.NamespacedName{Name: uc.Name, Namespace: uc.Namespace},
+				})
+				break
+			}
+		}
+	}
+	return requests
+}
+
+// checkUserCodeOwner fails if the custom classes of the Hazelcast CR are configured
+// in the Hazelcast CR itself or by another UserCode.
+func checkUserCodeOwner(h *hazelcastv1alpha1.Hazelcast, uc *hazelcastv1alpha1.UserCode) error {
+	owner, ok := h.Annotations[n.UserCodeAnnotation]
+	switch {
+	case ok && owner != uc.Name:
+		return fmt.Errorf("custom classes of Hazelcast %s are managed by UserCode %s", h.Name, owner)
+	case !ok && h.Spec.CustomClass != nil:
+		return fmt.Errorf("custom classes of Hazelcast %s are configured in the Hazelcast resource", h.Name)
+	}
+	return nil
+}
+
+// userCodeCustomClass returns the custom class configuration of the UserCode. The trigger sequence
+// changes with the generation of the UserCode so the members are restarted when the user code changes.
+func userCodeCustomClass(uc *hazelcastv1alpha1.UserCode, current *hazelcastv1alpha1.CustomClassConfiguration) *hazelcastv1alpha1.CustomClassConfiguration {
+	cc := &hazelcastv1alpha1.CustomClassConfiguration{
+		BucketConfiguration: uc.Spec.BucketConfiguration,
+		ConfigMaps:          uc.Spec.ConfigMaps,
+	}
+	if current != nil && reflect.DeepEqual(current.BucketConfiguration, cc.BucketConfiguration) &&
+		reflect.DeepEqual(current.ConfigMaps, cc.ConfigMaps) {
+		// nothing changed, the members are not restarted
+		cc.TriggerSequence = current.TriggerSequence
+		return cc
+	}
+	cc.TriggerSequence = strconv.FormatInt(uc.Generation, 10)
+	return cc
+}
+
+// executeFinalizer removes the custom classes of the UserCode from the Hazelcast CR.
+func (r *UserCodeReconciler) executeFinalizer(ctx context.Context, uc *hazelcastv1alpha1.UserCode) error {
+	if !controllerutil.ContainsFinalizer(uc, n.Finalizer) {
+		return nil
+	}
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		h := &hazelcastv1alpha1.Hazelcast{}
+		if err := r.Get(ctx, types.NamespacedName{Name: uc.Spec.HazelcastResourceName, Namespace: uc.Namespace}, h); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		if h.Annotations[n.UserCodeAnnotation] != uc.Name {
+			return nil
+		}
+		delete(h.Annotations, n.UserCodeAnnotation)
+		h.Spec.CustomClass = nil
+		return r.Update(ctx, h)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove user code from Hazelcast resource: %w", err)
+	}
+	controllerutil.RemoveFinalizer(uc, n.Finalizer)
+	if err := r.Update(ctx, uc); err != nil {
+		return fmt.Errorf("failed to remove finalizer from custom resource: %w", err)
+	}
+	return nil
+}
+
+func (r *UserCodeReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&hazelcastv1alpha1.UserCode{}).
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}}, handler.EnqueueRequestsFromMapFunc(r.configMapUpdates)).
+		Complete(r)
+}
@@ -0,0 +1,134 @@
+package hazelcast
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	hazelcastv1alpha1 "github.com/hazelcast/hazelcast-platform-operator/api/v1alpha1"
+	n "github.com/hazelcast/hazelcast-platform-operator/internal/naming"
+)
+
+func TestUserCodeReconciler_shouldDeployUserCodeToHazelcast(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	hzName := types.NamespacedName{Name: "hazelcast", Namespace: "default"}
+	ucName := types.NamespacedName{Name: "user-code", Namespace: "default"}
+	h := &hazelcastv1alpha1.Hazelcast{
+		ObjectMeta: metav1.ObjectMeta{Name: hzName.Name, Namespace: hzName.Namespace},
+	}
+	uc := &hazelcastv1alpha1.UserCode{
+		ObjectMeta: metav1.ObjectMeta{Name: ucName.Name, Namespace: ucName.Namespace, Generation: 1},
+		Spec: hazelcastv1alpha1.UserCodeSpec{
+			HazelcastResourceName: hzName.Name,
+			ConfigMaps:            []string{"jars"},
+		},
+	}
+
+	c := fakeClient(h, uc)
+	r := NewUserCodeReconciler(c, ctrl.Log.WithName("test").WithName("UserCode"), c.Scheme())
+	_, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: ucName})
+	Expect(err).Should(BeNil())
+
+	Expect(c.Get(context.TODO(), hzName, h)).Should(Succeed())
+	Expect(h.Annotations).Should(HaveKeyWithValue(n.UserCodeAnnotation, ucName.Name))
+	Expect(h.Spec.CustomClass).Should(Equal(&hazelcastv1alpha1.CustomClassConfiguration{
+		ConfigMaps:      []string{"jars"},
+		TriggerSequence: "1",
+	}))
+	Expect(c.Get(context.TODO(), ucName, uc)).Should(Succeed())
+	Expect(uc.Status.State).Should(Equal(hazelcastv1alpha1.UserCodeSuccess))
+	Expect(uc.Finalizers).Should(ContainElement(n.Finalizer))
+
+	// the fake client does not wait for the finalizers on delete
+	now := metav1.Now()
+	uc.DeletionTimestamp = &now
+	Expect(c.Update(context.TODO(), uc)).Should(Succeed())
+	_, err = r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: ucName})
+	Expect(err).Should(BeNil())
+	h = &hazelcastv1alpha1.Hazelcast{}
+	Expect(c.Get(context.TODO(), hzName, h)).Should(Succeed())
+	Expect(h.Spec.CustomClass).Should(BeNil())
+	Expect(h.Annotations).ShouldNot(HaveKey(n.UserCodeAnnotation))
+}
+
+func TestUserCodeReconciler_shouldNotOverrideCustomClassOfHazelcast(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	ucName := types.NamespacedName{Name: "user-code", Namespace: "default"}
+	h := &hazelcastv1alpha1.Hazelcast{
+		ObjectMeta: metav1.ObjectMeta{Name: "hazelcast", Namespace: "default"},
+		Spec: hazelcastv1alpha1.HazelcastSpec{
+			CustomClass: &hazelcastv1alpha1.CustomClassConfiguration{ConfigMaps: []string{"other"}},
+		},
+	}
+	uc := &hazelcastv1alpha1.UserCode{
+		ObjectMeta: metav1.ObjectMeta{Name: ucName.Name, Namespace: ucName.Namespace},
+		Spec: hazelcastv1alpha1.UserCodeSpec{
+			HazelcastResourceName: h.Name,
+			ConfigMaps:            []string{"jars"},
+		},
+	}
+
+	c := fakeClient(h, uc)
+	r := NewUserCodeReconciler(c, ctrl.Log.WithName("test").WithName("UserCode"), c.Scheme())
+	_, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: ucName})
+	Expect(err).Should(MatchError("custom classes of Hazelcast hazelcast are configured in the Hazelcast resource"))
+
+	Expect(c.Get(context.TODO(), ucName, uc)).Should(Succeed())
+	Expect(uc.Status.State).Should(Equal(hazelcastv1alpha1.UserCodeFailed))
+}
+
+func TestUserCodeReconciler_shouldRestartMembersWhenConfigMapChanges(t *testing.T) {
+	RegisterFailHandler(fail(t))
+	hzName := types.NamespacedName{Name: "hazelcast", Namespace: "default"}
+	ucName := types.NamespacedName{Name: "user-code", Namespace: "default"}
+	h := &hazelcastv1alpha1.Hazelcast{
+		ObjectMeta: metav1.ObjectMeta{Name: hzName.Name, Namespace: hzName.Namespace},
+	}
+	uc := &hazelcastv1alpha1.UserCode{
+		ObjectMeta: metav1.ObjectMeta{Name: ucName.Name, Namespace: ucName.Namespace, Generation: 1},
+		Spec: hazelcastv1alpha1.UserCodeSpec{
+			HazelcastResourceName: hzName.Name,
+			ConfigMaps:            []string{"jars"},
+		},
+	}
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "jars", Namespace: "default"},
+		BinaryData: map[string][]byte{"pipeline.jar": []byte("v1")},
+	}
+
+	c := fakeClient(h, uc, cm)
+	r := NewUserCodeReconciler(c, ctrl.Log.WithName("test").WithName("UserCode"), c.Scheme())
+	digest := func() string {
+		_, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: ucName})
+		Expect(err).Should(BeNil())
+		h := &hazelcastv1alpha1.Hazelcast{}
+		Expect(c.Get(context.TODO(), hzName, h)).Should(Succeed())
+		uc := &hazelcastv1alpha1.UserCode{}
+		Expect(c.Get(context.TODO(), ucName, uc)).Should(Succeed())
+		Expect(uc.Status.Digest).Should(Equal(h.Annotations[n.UserCodeDigestAnnotation]))
+		return uc.Status.Digest
+	}
+
+	deployed := digest()
+	Expect(deployed).ShouldNot(BeEmpty())
+	Expect(digest()).Should(Equal(deployed))
+
+	// the jar is replaced without changing the UserCode
+	Expect(c.Get(context.TODO(), types.NamespacedName{Name: "jars", Namespace: "default"}, cm)).Should(Succeed())
+	cm.BinaryData["pipeline.jar"] = []byte("v2")
+	Expect(c.Update(context.TODO(), cm)).Should(Succeed())
+	Expect(r.configMapUpdates(cm)).Should(ConsistOf(reconcile.Request{NamespacedName: ucName}))
+	changed := digest()
+	Expect(changed).ShouldNot(Equal(deployed))
+
+	Expect(c.Get(context.TODO(), hzName, h)).Should(Succeed())
+	annotations, err := podAnnotations(nil, h)
+	Expect(err).Should(BeNil())
+	Expect(annotations).Should(HaveKeyWithValue(n.UserCodeDigestAnnotation, changed))
+}
@@ -0,0 +1,62 @@
+package hazelcast
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	hazelcastv1alpha1 "github.com/hazelcast/hazelcast-platform-operator/api/v1alpha1"
+)
+
+type userCodeOptionsBuilder struct {
+	status  hazelcastv1alpha1.UserCodeState
+	err     error
+	message string
+	digest  string
+}
+
+func userCodeFailedStatus(err error) userCodeOptionsBuilder {
+	return userCodeOptionsBuilder{
+		status:  hazelcastv1alpha1.UserCodeFailed,
+		err:     err,
+		message: err.Error(),
+	}
+}
+
+func userCodeSuccessStatus() userCodeOptionsBuilder {
+	return userCodeOptionsBuilder{
+		status: hazelcastv1alpha1.UserCodeSuccess,
+	}
+}
+
+func (o userCodeOptionsBuilder) withMessage(m string) userCodeOptionsBuilder {
+	o.message = m
+	return o
+}
+
+func (o userCodeOptionsBuilder) withDigest(d string) userCodeOptionsBuilder {
+	o.digest = d
+	return o
+}
+
+func updateUserCodeStatus(ctx context.Context, c client.Client, uc *hazelcastv1alpha1.UserCode, options userCodeOptionsBuilder) (ctrl.Result, error) {
+	uc.Status.State = options.status
+	uc.Status.Message = options.message
+	if options.status == hazelcastv1alpha1.UserCodeSuccess {
+		uc.Status.ObservedGeneration = uc.Generation
+		uc.Status.Digest = options.digest
+	}
+	if err := c.Status().Update(ctx, uc); err != nil {
+		// Conflicts are expected and will be handled on the next reconcile loop, no need to error out here
+		if errors.IsConflict(err) {
+			return ctrl.Result{Requeue: true}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	if options.status == hazelcastv1alpha1.UserCodeFailed {
+		return ctrl.Result{}, options.err
+	}
+	return userCodeResult(uc), nil
+}
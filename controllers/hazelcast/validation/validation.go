@@ -2,8 +2,21 @@ package validation
 
 import (
 	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"golang.org/x/net/http/httpguts"
+	v1 "k8s.io/api/core/v1"
+	kvalidation "k8s.io/apimachinery/pkg/util/validation"
 
 	hazelcastv1alpha1 "github.com/hazelcast/hazelcast-platform-operator/api/v1alpha1"
+	n "github.com/hazelcast/hazelcast-platform-operator/internal/naming"
+	"github.com/hazelcast/hazelcast-platform-operator/internal/upload"
 	"github.com/hazelcast/hazelcast-platform-operator/internal/util"
 )
 
@@ -16,6 +29,10 @@ func ValidateSpec(h *hazelcastv1alpha1.Hazelcast) error {
 		return err
 	}
 
+	if err := validateRestore(h); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -39,9 +56,286 @@ func validateLicense(h *hazelcastv1alpha1.Hazelcast) error {
 	return nil
 }
 
+func validateRestore(h *hazelcastv1alpha1.Hazelcast) error {
+	if !h.Spec.Persistence.IsRestoreEnabled() {
+		return nil
+	}
+	r := h.Spec.Persistence.Restore
+	if r.HotBackupResourceName != "" && (r.BucketURI != "" || r.Secret != "") {
+		return errors.New("when persistence.restore.hotBackupResourceName is set, bucketURI and secret must not be set")
+	}
+	if r.HotBackupResourceName == "" && r.BucketURI == "" && !r.Latest {
+		return errors.New("either persistence.restore.bucketURI, persistence.restore.hotBackupResourceName or persistence.restore.latest must be set")
+	}
+	if r.Latest && r.HotBackupResourceName != "" {
+		return errors.New("persistence.restore.latest cannot be used with persistence.restore.hotBackupResourceName")
+	}
+	if s := r.SourceClusterName; s != "" {
+		if errs := kvalidation.IsDNS1123Subdomain(s); len(errs) > 0 {
+			return fmt.Errorf("invalid persistence.restore.sourceClusterName %q: %s", s, strings.Join(errs, ", "))
+		}
+	}
+	if err := validateRestoreExcludeStructures(h); err != nil {
+		return err
+	}
+	return validateRestoreHooks(h)
+}
+
+func validateRestoreExcludeStructures(h *hazelcastv1alpha1.Hazelcast) error {
+	names := make(map[string]struct{})
+	for _, s := range h.Spec.Persistence.Restore.ExcludeStructures {
+		if strings.TrimSpace(s) != s || s == "" {
+			return fmt.Errorf("invalid structure name %q in persistence.restore.excludeStructures", s)
+		}
+		if _, ok := names[s]; ok {
+			return fmt.Errorf("structure name %q is not unique in persistence.restore.excludeStructures", s)
+		}
+		names[s] = struct{}{}
+	}
+	return nil
+}
+
+func validateRestoreHooks(h *hazelcastv1alpha1.Hazelcast) error {
+	names := make(map[string]struct{})
+	for _, hook := range h.Spec.Persistence.Restore.Hooks {
+		if errs := kvalidation.IsDNS1123Label(n.RestoreHookPrefix + hook.Name); len(errs) > 0 {
+			return fmt.Errorf("invalid restore hook name %q: %s", hook.Name, strings.Join(errs, ", "))
+		}
+		if _, ok := names[hook.Name]; ok {
+			return fmt.Errorf("restore hook name %q is not unique", hook.Name)
+		}
+		names[hook.Name] = struct{}{}
+	}
+	return nil
+}
+
 func ValidateHotBackupSpec(hb *hazelcastv1alpha1.HotBackup) error {
 	if hb.Spec.Secret == "" {
 		return errors.New("when using external Backup, Secret must be set")
 	}
 	return nil
 }
+
+func ValidateHotBackup(hb *hazelcastv1alpha1.HotBackup, p cron.Parser) error {
+	if err := validateHotBackupSchedule(hb, p); err != nil {
+		return err
+	}
+
+	if err := validateHotBackupCompression(hb); err != nil {
+		return err
+	}
+
+	if err := validateHotBackupRequestHeaders(hb); err != nil {
+		return err
+	}
+
+	partSize, maxObjectSize := hb.Spec.MultipartSizes()
+	if err := upload.ValidateMultipart(hb.Spec.BucketURI, partSize, maxObjectSize); err != nil {
+		return err
+	}
+
+	if hb.Spec.MaxBackupSize != nil && hb.Spec.MaxBackupSize.Sign() <= 0 {
+		return fmt.Errorf("maxBackupSize must be positive, got %s", hb.Spec.MaxBackupSize.String())
+	}
+
+	if hb.Spec.Verify != nil && hb.Spec.BucketURI == "" {
+		return errors.New("verify requires the bucketURI of the backup")
+	}
+
+	if p := hb.Spec.BackupPathOverride; p != "" && (hb.Spec.BucketURI == "" || !path.IsAbs(p) || path.Clean(p) != p) {
+		return errors.New("backupPathOverride requires the bucketURI of the backup and must be a clean absolute path")
+	}
+
+	if hb.Spec.VerifySampleRate != nil && hb.Spec.Verify == nil {
+		return errors.New("verifySampleRate can only be used with verify")
+	}
+
+	if hb.Spec.Compact != nil && (hb.Spec.BucketURI == "" || hb.Spec.Verify != nil || hb.Spec.Schedule != "") {
+		return errors.New("compact requires the bucketURI of the backup and cannot be used with verify or schedule")
+	}
+
+	if f := hb.Spec.Failover; f != nil && (hb.Spec.BucketURI == "" || f.BucketURI == hb.Spec.BucketURI) {
+		return errors.New("failover requires the bucketURI of the backup and a different bucketURI for the replica")
+	}
+
+	if hb.Spec.Delta && (hb.Spec.BucketURI == "" || hb.Spec.Failover != nil) {
+		return errors.New("delta requires the bucketURI of the backup and cannot be used with failover, the base backup would be missing in the replica bucket")
+	}
+
+	if hb.Spec.IncludeConfig && hb.Spec.BucketURI == "" {
+		return errors.New("includeConfig requires the bucketURI of the backup, the configuration is written into its manifest")
+	}
+
+	if hb.Spec.CollectDiagnosticsOnFailure && hb.Spec.BucketURI == "" {
+		return errors.New("collectDiagnosticsOnFailure requires the bucketURI of the backup, the diagnostic bundle is uploaded to it")
+	}
+
+	if err := validateHotBackupRetention(hb); err != nil {
+		return err
+	}
+
+	if hb.Spec.ChunkedTransfer && !isHTTPEndpoint(hb.Spec.BucketURI) {
+		return errors.New("chunkedTransfer can only be set for http:// and https:// bucketURIs")
+	}
+
+	if err := validateHotBackupObjectACL(hb); err != nil {
+		return err
+	}
+
+	if l := hb.Spec.ObjectLock; l != nil && l.RetentionPeriod.Duration <= 0 {
+		return fmt.Errorf("objectLock retentionPeriod must be positive, got %s", l.RetentionPeriod.Duration)
+	}
+
+	if err := validateHotBackupNotification(hb); err != nil {
+		return err
+	}
+
+	if i := hb.Spec.CredentialsRefreshInterval; i != nil && i.Duration < time.Second {
+		return fmt.Errorf("credentialsRefreshInterval must be at least 1s, got %s", i.Duration)
+	}
+
+	if t := hb.Spec.AgentConnectTimeout; t != nil && t.Duration < time.Second {
+		return fmt.Errorf("agentConnectTimeout must be at least 1s, got %s", t.Duration)
+	}
+
+	return nil
+}
+
+func ValidateHotBackupPolicy(p *hazelcastv1alpha1.HotBackupPolicy) error {
+	if p.Spec.MaxConcurrentBackups > 0 && p.Spec.Template.Schedule != "" {
+		return errors.New("maxConcurrentBackups cannot be set for scheduled HotBackups")
+	}
+	return nil
+}
+
+func validateHotBackupRetention(hb *hazelcastv1alpha1.HotBackup) error {
+	r := hb.Spec.Retention
+	if r == nil {
+		return nil
+	}
+	if r.Hourly == 0 && r.Daily == 0 && r.Weekly == 0 && r.Monthly == 0 && r.MaxCount == 0 && r.MaxAge == nil {
+		return errors.New("retention must set maxCount, maxAge or at least one of the hourly, daily, weekly or monthly tiers")
+	}
+	if r.MaxAge != nil && r.MaxAge.Duration <= 0 {
+		return fmt.Errorf("retention maxAge must be positive, got %s", r.MaxAge.Duration)
+	}
+	return nil
+}
+
+func isHTTPEndpoint(bucketURI string) bool {
+	u, err := url.Parse(bucketURI)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https")
+}
+
+func validateHotBackupNotification(hb *hazelcastv1alpha1.HotBackup) error {
+	nc := hb.Spec.Notification
+	if nc == nil {
+		return nil
+	}
+	if nc.SuccessURL == "" && nc.FailureURL == "" {
+		return errors.New("notification requires successURL or failureURL")
+	}
+	for _, u := range []string{nc.SuccessURL, nc.FailureURL} {
+		if u != "" && !isHTTPEndpoint(u) {
+			return fmt.Errorf("invalid notification URL %q, only http:// and https:// URLs are supported", u)
+		}
+	}
+	return nil
+}
+
+func validateHotBackupObjectACL(hb *hazelcastv1alpha1.HotBackup) error {
+	acl := hb.Spec.ObjectACL
+	if acl == nil || hb.Spec.BucketURI == "" {
+		return nil
+	}
+	permissions := make([]string, 0, len(acl.Grants))
+	for _, g := range acl.Grants {
+		permissions = append(permissions, string(g.Permission))
+	}
+	return upload.ValidateObjectACL(hb.Spec.BucketURI, acl.Canned, permissions)
+}
+
+func validateHotBackupSchedule(hb *hazelcastv1alpha1.HotBackup, p cron.Parser) error {
+	if hb.Spec.Schedule == "" {
+		return nil
+	}
+	if _, err := p.Parse(hb.Spec.Schedule); err != nil {
+		return fmt.Errorf("invalid schedule %q: %w", hb.Spec.Schedule, err)
+	}
+	return nil
+}
+
+func validateHotBackupCompression(hb *hazelcastv1alpha1.HotBackup) error {
+	level := hb.Spec.CompressionLevel
+	if level == 0 {
+		return nil
+	}
+	algorithm := hb.Spec.Compression
+	if algorithm == "" {
+		algorithm = hazelcastv1alpha1.CompressionGzip
+	}
+	if min, max := algorithm.LevelRange(); level < min || level > max {
+		return fmt.Errorf("compressionLevel %d is out of the range %d-%d of %s", level, min, max, algorithm)
+	}
+	return nil
+}
+
+// reservedRequestHeaders are set by the storage clients of the agent and cannot be overridden
+var reservedRequestHeaders = map[string]struct{}{
+	"Authorization":  {},
+	"Content-Length": {},
+	"Content-Md5":    {},
+	"Content-Type":   {},
+	"Date":           {},
+	"Host":           {},
+	"Range":          {},
+}
+
+// reservedRequestHeaderPrefixes are the headers of the storage APIs
+var reservedRequestHeaderPrefixes = []string{"X-Amz-", "X-Goog-", "X-Ms-"}
+
+func validateHotBackupRequestHeaders(hb *hazelcastv1alpha1.HotBackup) error {
+	for h := range hb.Spec.RequestHeaders {
+		if !httpguts.ValidHeaderFieldName(h) {
+			return fmt.Errorf("invalid request header name %q", h)
+		}
+		canonical := http.CanonicalHeaderKey(h)
+		if _, ok := reservedRequestHeaders[canonical]; ok {
+			return fmt.Errorf("request header %s is reserved", h)
+		}
+		for _, p := range reservedRequestHeaderPrefixes {
+			if strings.HasPrefix(canonical, p) {
+				return fmt.Errorf("request header %s is reserved", h)
+			}
+		}
+	}
+	return nil
+}
+
+// bucketSecretKeys are the keys the backup agent needs in the secret of the bucket, by the scheme of the bucket URI
+var bucketSecretKeys = map[string][]string{
+	"s3":     {"region", "access-key-id", "secret-access-key"},
+	"gs":     {"google-credentials-path"},
+	"azblob": {"storage-account", "storage-key"},
+}
+
+// s3RoleSecretKeys are the keys of the S3 secret if the agent assumes a role for short-lived credentials
+var s3RoleSecretKeys = []string{"region", "role-arn"}
+
+// ValidateBucketSecret checks that the secret contains the credentials required by the backend of the bucket.
+func ValidateBucketSecret(bucketURI string, s *v1.Secret) error {
+	u, err := url.Parse(bucketURI)
+	if err != nil {
+		return fmt.Errorf("invalid bucketURI %q: %w", bucketURI, err)
+	}
+	keys := bucketSecretKeys[u.Scheme]
+	if u.Scheme == "s3" && (len(s.Data["role-arn"]) > 0 || s.StringData["role-arn"] != "") {
+		keys = s3RoleSecretKeys
+	}
+	for _, key := range keys {
+		if len(s.Data[key]) == 0 && s.StringData[key] == "" {
+			return fmt.Errorf("secret %s missing key %s", s.Name, key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/syslog"
+	"net/url"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	hzclient "github.com/hazelcast/hazelcast-platform-operator/controllers/hazelcast/client"
+)
+
+// Record is the audit record of a finished backup.
+type Record struct {
+	Time         time.Time `json:"time"`
+	Namespace    string    `json:"namespace"`
+	HotBackup    string    `json:"hotBackup"`
+	Hazelcast    string    `json:"hazelcast"`
+	TriggeredBy  string    `json:"triggeredBy"`
+	Result       string    `json:"result"`
+	Message      string    `json:"message,omitempty"`
+	BucketURI    string    `json:"bucketURI,omitempty"`
+	BackupFolder string    `json:"backupFolder,omitempty"`
+	RunID        string    `json:"runID,omitempty"`
+}
+
+// Sink is the external system the audit records are appended to.
+type Sink interface {
+	Write(ctx context.Context, r *Record) error
+}
+
+// SinkFactory creates the sink configured by the URI.
+type SinkFactory func(u *url.URL) (Sink, error)
+
+var (
+	mu        sync.RWMutex
+	factories = map[string]SinkFactory{
+		"syslog":          newSyslogSink("udp"),
+		"syslog+tcp":      newSyslogSink("tcp"),
+		"hazelcast-topic": newTopicSink,
+	}
+	// sink is nil if auditing is disabled
+	sink Sink
+)
+
+// RegisterSink makes the sink created by f available for the URIs with the given scheme.
+func RegisterSink(scheme string, f SinkFactory) {
+	mu.Lock()
+	defer mu.Unlock()
+	factories[scheme] = f
+}
+
+// SetSink configures the sink the records are written to by its URI. An empty URI disables auditing.
+//
+//	syslog://host:514          JSON records sent to a syslog server over UDP
+//	syslog+tcp://host:514      JSON records sent to a syslog server over TCP
+//	hazelcast-topic://<topic>  JSON records published to the topic of the backed up Hazelcast cluster
+func SetSink(uri string) error {
+	var s Sink
+	if uri != "" {
+		u, err := url.Parse(uri)
+		if err != nil {
+			return fmt.Errorf("invalid audit sink URI: %w", err)
+		}
+		mu.RLock()
+		f, ok := factories[u.Scheme]
+		mu.RUnlock()
+		if !ok {
+			return fmt.Errorf("unknown audit sink %q", u.Scheme)
+		}
+		if s, err = f(u); err != nil {
+			return err
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	sink = s
+	return nil
+}
+
+// Write appends the record to the configured sink, it does nothing if auditing is disabled.
+func Write(ctx context.Context, r *Record) error {
+	mu.RLock()
+	s := sink
+	mu.RUnlock()
+	if s == nil {
+		return nil
+	}
+	return s.Write(ctx, r)
+}
+
+type syslogSink struct {
+	w *syslog.Writer
+}
+
+func newSyslogSink(network string) SinkFactory {
+	return func(u *url.URL) (Sink, error) {
+		w, err := syslog.Dial(network, u.Host, syslog.LOG_INFO|syslog.LOG_AUTH, "hazelcast-platform-operator")
+		if err != nil {
+			return nil, err
+		}
+		return &syslogSink{w: w}, nil
+	}
+}
+
+func (s *syslogSink) Write(_ context.Context, r *Record) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return s.w.Info(string(b))
+}
+
+type topicSink struct {
+	topic string
+}
+
+func newTopicSink(u *url.URL) (Sink, error) {
+	if u.Host == "" {
+		return nil, fmt.Errorf("topic name is missing from the audit sink URI %q", u.String())
+	}
+	return &topicSink{topic: u.Host}, nil
+}
+
+func (s *topicSink) Write(ctx context.Context, r *Record) error {
+	c, ok := hzclient.GetClient(types.NamespacedName{Name: r.Hazelcast, Namespace: r.Namespace})
+	if !ok || c.Client == nil {
+		return fmt.Errorf("no client connected to Hazelcast %s/%s", r.Namespace, r.Hazelcast)
+	}
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	t, err := c.Client.GetTopic(ctx, s.topic)
+	if err != nil {
+		return err
+	}
+	return t.Publish(ctx, string(b))
+}
@@ -0,0 +1,38 @@
+package audit
+
+import (
+	"context"
+	"net/url"
+	"testing"
+)
+
+type recordingSink struct {
+	records []*Record
+}
+
+func (s *recordingSink) Write(_ context.Context, r *Record) error {
+	s.records = append(s.records, r)
+	return nil
+}
+
+func TestWrite(t *testing.T) {
+	s := &recordingSink{}
+	RegisterSink("test", func(u *url.URL) (Sink, error) { return s, nil })
+	defer func() { _ = SetSink("") }()
+
+	if err := Write(context.Background(), &Record{HotBackup: "disabled"}); err != nil {
+		t.Fatalf("Write() with no sink error = %v", err)
+	}
+	if err := SetSink("unknown://sink"); err == nil {
+		t.Errorf("SetSink() with unknown scheme should fail")
+	}
+	if err := SetSink("test://sink"); err != nil {
+		t.Fatalf("SetSink() error = %v", err)
+	}
+	if err := Write(context.Background(), &Record{HotBackup: "hot-backup"}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if len(s.records) != 1 || s.records[0].HotBackup != "hot-backup" {
+		t.Errorf("Write() records = %v, want the record of hot-backup", s.records)
+	}
+}
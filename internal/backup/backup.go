@@ -3,6 +3,7 @@ package backup
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync"
 	"time"
 
@@ -38,6 +39,9 @@ type ClusterBackup struct {
 var (
 	errBackupClientNotFound  = errors.New("client not found for hot backup CR")
 	errBackupClientNoMembers = errors.New("client couldnt connect to members")
+
+	// ErrMemberLeft means a member taking part in the backup left the cluster during the backup.
+	ErrMemberLeft = errors.New("member of the backup left the cluster")
 )
 
 func NewClusterBackup(h *hazelcastv1alpha1.Hazelcast) (*ClusterBackup, error) {
@@ -90,6 +94,14 @@ func (b *ClusterBackup) Start(ctx context.Context) error {
 	})
 }
 
+// Activate switches the cluster back to the active state, e.g. if the backup was interrupted while it was passive.
+func (b *ClusterBackup) Activate(ctx context.Context) error {
+	return retryOnError(retry.DefaultRetry, func() error {
+		_, _, err := b.service.ChangeState(ctx, b.clusterName, "ACTIVE")
+		return err
+	})
+}
+
 func (b *ClusterBackup) Cancel(ctx context.Context) error {
 	var err error
 	b.cancelOnce.Do(func() {
@@ -98,6 +110,21 @@ func (b *ClusterBackup) Cancel(ctx context.Context) error {
 	return err
 }
 
+// CheckHealth returns ErrMemberLeft if a member taking part in the backup is no longer a member of the cluster.
+// The health is unknown while the client is disconnected, no error is returned then.
+func (b *ClusterBackup) CheckHealth() error {
+	current := b.client.Members()
+	if current == nil {
+		return nil
+	}
+	for uuid, m := range b.members {
+		if _, ok := current[uuid]; !ok {
+			return fmt.Errorf("%w: %s", ErrMemberLeft, m.Address)
+		}
+	}
+	return nil
+}
+
 func (b *ClusterBackup) Members() []*MemberBackup {
 	var mb []*MemberBackup
 	for uuid, m := range b.members {
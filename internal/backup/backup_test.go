@@ -0,0 +1,62 @@
+package backup
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hazelcast/hazelcast-platform-operator/internal/rest"
+)
+
+func TestClusterBackup_ActivateAfterCanceledFlush(t *testing.T) {
+	ctx := context.Background()
+	runCtx, cancelRun := context.WithCancel(ctx)
+	defer cancelRun()
+
+	var mu sync.Mutex
+	state := "ACTIVE"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/changeState"):
+			mu.Lock()
+			state = string(body[strings.LastIndex(string(body), "&")+1:])
+			mu.Unlock()
+		case strings.HasSuffix(r.URL.Path, "/hotBackup"):
+			// the run is canceled while the members flush the backup
+			cancelRun()
+			<-r.Context().Done()
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"success"}`))
+	}))
+	defer ts.Close()
+
+	s, err := rest.NewHazelcastService(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &ClusterBackup{service: s, clusterName: "dev"}
+
+	if err := b.Start(runCtx); err == nil {
+		t.Fatal("Start() of a canceled run succeeded")
+	}
+	mu.Lock()
+	if state != "PASSIVE" {
+		t.Errorf("state after the canceled flush = %s, want the deferred activation with the canceled context to fail", state)
+	}
+	mu.Unlock()
+
+	if err := b.Activate(ctx); err != nil {
+		t.Fatalf("Activate() error = %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if state != "ACTIVE" {
+		t.Errorf("state after Activate() = %s, want ACTIVE", state)
+	}
+}
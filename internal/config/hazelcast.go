@@ -10,10 +10,16 @@ type Hazelcast struct {
 	ClusterName string         `yaml:"cluster-name,omitempty"`
 	Persistence Persistence    `yaml:"persistence,omitempty"`
 	Map         map[string]Map `yaml:"map,omitempty"`
+	Import      []string       `yaml:"import,omitempty"`
 }
 
 type Jet struct {
-	Enabled *bool `yaml:"enabled,omitempty"`
+	Enabled  *bool       `yaml:"enabled,omitempty"`
+	Instance JetInstance `yaml:"instance,omitempty"`
+}
+
+type JetInstance struct {
+	LosslessRestartEnabled *bool `yaml:"lossless-restart-enabled,omitempty"`
 }
 
 type Network struct {
@@ -0,0 +1,157 @@
+package diagnostics
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Logs keeps the recent log lines of each resource in memory for the diagnostic bundles.
+// A nil Logs records nothing.
+type Logs struct {
+	size int
+
+	mu    sync.Mutex
+	lines map[types.NamespacedName]*ring
+}
+
+// NewLogs returns the Logs keeping at most size lines of each resource.
+func NewLogs(size int) *Logs {
+	return &Logs{size: size, lines: make(map[types.NamespacedName]*ring)}
+}
+
+// Logger returns the logger writing to logger and recording the lines of the resource.
+func (l *Logs) Logger(name types.NamespacedName, logger logr.Logger) logr.Logger {
+	if l == nil {
+		return logger
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	r, ok := l.lines[name]
+	if !ok {
+		r = &ring{lines: make([]string, 0, l.size)}
+		l.lines[name] = r
+	}
+	return &recorder{delegate: logger, ring: r}
+}
+
+// Lines returns the recorded lines of the resource, the oldest first.
+func (l *Logs) Lines(name types.NamespacedName) []string {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	r, ok := l.lines[name]
+	l.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	return r.snapshot()
+}
+
+// Forget drops the lines of the deleted resource.
+func (l *Logs) Forget(name types.NamespacedName) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.lines, name)
+}
+
+// ring is the bounded buffer of the lines of a resource, the oldest line is overwritten once it is full.
+type ring struct {
+	mu    sync.Mutex
+	lines []string
+	next  int
+}
+
+func (r *ring) add(line string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if cap(r.lines) == 0 {
+		return
+	}
+	if len(r.lines) < cap(r.lines) {
+		r.lines = append(r.lines, line)
+		return
+	}
+	r.lines[r.next] = line
+	r.next = (r.next + 1) % len(r.lines)
+}
+
+func (r *ring) snapshot() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	lines := make([]string, 0, len(r.lines))
+	lines = append(lines, r.lines[r.next:]...)
+	return append(lines, r.lines[:r.next]...)
+}
+
+// recorder is the logger recording the enabled lines in the ring besides writing them to the delegate.
+type recorder struct {
+	delegate logr.Logger
+	ring     *ring
+	name     string
+	values   []interface{}
+}
+
+func (r *recorder) Enabled() bool {
+	return r.delegate.Enabled()
+}
+
+func (r *recorder) Info(msg string, keysAndValues ...interface{}) {
+	r.delegate.Info(msg, keysAndValues...)
+	if r.delegate.Enabled() {
+		r.record("INFO", msg, nil, keysAndValues)
+	}
+}
+
+func (r *recorder) Error(err error, msg string, keysAndValues ...interface{}) {
+	r.delegate.Error(err, msg, keysAndValues...)
+	r.record("ERROR", msg, err, keysAndValues)
+}
+
+func (r *recorder) V(level int) logr.Logger {
+	c := *r
+	c.delegate = r.delegate.V(level)
+	return &c
+}
+
+func (r *recorder) WithValues(keysAndValues ...interface{}) logr.Logger {
+	c := *r
+	c.delegate = r.delegate.WithValues(keysAndValues...)
+	c.values = append(append([]interface{}{}, r.values...), keysAndValues...)
+	return &c
+}
+
+func (r *recorder) WithName(name string) logr.Logger {
+	c := *r
+	c.delegate = r.delegate.WithName(name)
+	if c.name != "" {
+		name = c.name + "." + name
+	}
+	c.name = name
+	return &c
+}
+
+func (r *recorder) record(level, msg string, err error, keysAndValues []interface{}) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\t%s\t", time.Now().UTC().Format(time.RFC3339Nano), level)
+	if r.name != "" {
+		fmt.Fprintf(&b, "%s\t", r.name)
+	}
+	b.WriteString(msg)
+	if err != nil {
+		fmt.Fprintf(&b, "\terror=%q", err.Error())
+	}
+	kvs := append(append([]interface{}{}, r.values...), keysAndValues...)
+	for i := 0; i+1 < len(kvs); i += 2 {
+		fmt.Fprintf(&b, "\t%v=%v", kvs[i], kvs[i+1])
+	}
+	r.ring.add(b.String())
+}
@@ -0,0 +1,42 @@
+package diagnostics
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+func TestLogs(t *testing.T) {
+	name := types.NamespacedName{Name: "hot-backup", Namespace: "default"}
+	l := NewLogs(2)
+	logger := l.Logger(name, zap.New()).WithValues("hazelcast-hot-backup", name.Name)
+
+	logger.Info("Starting backup")
+	logger.V(1).Info("Debug line")
+	logger.Error(errors.New("connection refused"), "Upload failed", "uuid", "member-1")
+	logger.Info("Finished backup")
+
+	lines := l.Lines(name)
+	if len(lines) != 2 {
+		t.Fatalf("lines = %q, want the last 2 lines", lines)
+	}
+	if !strings.Contains(lines[0], "ERROR\tUpload failed\terror=\"connection refused\"\thazelcast-hot-backup=hot-backup\tuuid=member-1") {
+		t.Errorf("lines[0] = %q, want the error with the values", lines[0])
+	}
+	if !strings.Contains(lines[1], "INFO\tFinished backup") {
+		t.Errorf("lines[1] = %q, want the last line", lines[1])
+	}
+
+	l.Forget(name)
+	if lines := l.Lines(name); lines != nil {
+		t.Errorf("lines = %q, want none after Forget", lines)
+	}
+	var nilLogs *Logs
+	nilLogs.Logger(name, zap.New()).Info("not recorded")
+	if lines := nilLogs.Lines(name); lines != nil {
+		t.Errorf("lines = %q, want none for nil Logs", lines)
+	}
+}
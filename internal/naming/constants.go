@@ -10,6 +10,8 @@ const (
 	Finalizer = "hazelcast.com/finalizer"
 	// LicenseDataKey is a key used in k8s secret that holds the Hazelcast license
 	LicenseDataKey = "license-key"
+	// SigningKeyDataKey is the key of the secret holding the key signing the webhook notifications
+	SigningKeyDataKey = "signing-key"
 	// LicenseKeySecret default license key secret
 	LicenseKeySecret = "hazelcast-license-key"
 	// ServicePerPodLabelName set to true when the service is a Service per pod
@@ -19,6 +21,28 @@ const (
 	LastAppliedSpecAnnotation                    = "hazelcast.com/last-applied-spec"
 	LastSuccessfulSpecAnnotation                 = "hazelcast.com/last-successful-spec"
 	CurrentHazelcastConfigForcingRestartChecksum = "hazelcast.com/current-hazelcast-config-forcing-restart-checksum"
+	// HotBackupTriggerLabel is the name of the HotBackupTrigger which created the HotBackup
+	HotBackupTriggerLabel = "hazelcast.com/hot-backup-trigger"
+	// HotBackupPolicyLabel is the name of the HotBackupPolicy which manages the HotBackup
+	HotBackupPolicyLabel = "hazelcast.com/hot-backup-policy"
+	// UserCodeAnnotation is the name of the UserCode managing the custom classes of the Hazelcast CR
+	UserCodeAnnotation = "hazelcast.com/user-code"
+	// UserCodeDigestAnnotation is the digest of the contents of the user code, set on the Hazelcast CR by the UserCode
+	// and copied to the member pods so that they are restarted when the contents change
+	UserCodeDigestAnnotation = "hazelcast.com/user-code-digest"
+	// BackupLabel set to BackupLabelDisabled on a Hazelcast CR disables its backups
+	BackupLabel         = "backup"
+	BackupLabelDisabled = "disabled"
+	// RestoreDownloadAnnotation set on a member pod to RestoreDownloadAllowed lets its restore agent start the download,
+	// RestoreDownloadCanceled makes it cancel the download
+	RestoreDownloadAnnotation = "hazelcast.com/restore-download"
+	RestoreDownloadAllowed    = "allowed"
+	RestoreDownloadCanceled   = "canceled"
+
+	// RestoreZoneAnnotation is set on a member pod to the zone of its node for the zone affinity of the restore,
+	// RestoreZoneUnknown if the node has no zone
+	RestoreZoneAnnotation = "hazelcast.com/restore-zone"
+	RestoreZoneUnknown    = "unknown"
 
 	// PodNameLabel label that represents the name of the pod in the StatefulSet
 	PodNameLabel = "statefulset.kubernetes.io/pod-name"
@@ -53,11 +77,39 @@ const (
 	BackupAgent              = "backup-agent"
 	BackupAgentPortName      = "backup-agent-port"
 	RestoreAgent             = "restore-agent"
+	RestoreHookPrefix        = "restore-hook-"
 	BucketSecret             = "br-secret"
 	CustomClassDownloadAgent = "ccd-agent"
 
 	CustomClassBucketPath    = "/opt/hazelcast/customClass/bucket"
 	CustomClassConfigMapPath = "/opt/hazelcast/customClass/cm"
+
+	// RestoredConfigFile is the file in the persistence directory the restore agent writes the restored dynamic configuration to.
+	RestoredConfigFile = "restored-config.yaml"
+
+	// RestoreDownloadVolumeName is the name of the downward API volume the restore agent reads RestoreDownloadAnnotation from.
+	RestoreDownloadVolumeName = "restore-download"
+	RestoreDownloadPath       = "/etc/restore-download"
+	RestoreDownloadFile       = "gate"
+
+	// RunIDAnnotation is the ID of the backup run on the HotBackup and on the events of the run
+	RunIDAnnotation = "hazelcast.com/run-id"
+
+	// CancelCurrentAnnotation on the HotBackup cancels its run in progress, the schedule of the HotBackup is kept
+	CancelCurrentAnnotation = "hazelcast.com/cancel-current"
+
+	// AllowConcurrentSchedulesAnnotation set to true on a scheduled HotBackup allows it to back up a cluster
+	// another scheduled HotBackup already backs up
+	AllowConcurrentSchedulesAnnotation = "hazelcast.com/allow-concurrent-schedules"
+
+	// DiagnosticsPrefix is the prefix of the diagnostic bundles of the failed backups in the bucket.
+	DiagnosticsPrefix = "diagnostics"
+
+	// LocalBackupDir is the directory under the base directory of the persistence the members keep their local backups in.
+	LocalBackupDir = "hot-backup"
+
+	// RestoreZoneFile is the file of the download volume the restore agent reads RestoreZoneAnnotation from.
+	RestoreZoneFile = "zone"
 )
 
 // Hazelcast default configurations
@@ -68,6 +120,9 @@ const (
 	DefaultClusterSize = 3
 	// DefaultClusterName default name of Hazelcast cluster
 	DefaultClusterName = "dev"
+	// MinAgentVersion is the first version of the agent supporting the options of the HotBackups and the restore added after 0.1.5,
+	// the default agent version 0.1.5 does not support them
+	MinAgentVersion = "0.2.0"
 	// HazelcastRepo image repository for Hazelcast
 	HazelcastRepo = "docker.io/hazelcast/hazelcast"
 	// HazelcastEERepo image repository for Hazelcast EE
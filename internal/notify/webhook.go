@@ -0,0 +1,90 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	// SignatureHeader is the header of the HMAC-SHA256 signature of the request body, e.g. sha256=<hex digest>.
+	SignatureHeader = "X-Hazelcast-Signature"
+	// EventHeader is the header of the event the webhook is called for.
+	EventHeader = "X-Hazelcast-Event"
+
+	// DefaultRetryInterval is the time between the delivery attempts if it is not configured.
+	DefaultRetryInterval = 10 * time.Second
+)
+
+// Webhook is the endpoint the events are delivered to.
+type Webhook struct {
+	URL string
+	// Key signs the requests if it is not empty.
+	Key []byte
+	// Retries is the number of times a failed delivery is retried.
+	Retries int
+	// RetryInterval is the time between the delivery attempts, DefaultRetryInterval is used if it is zero.
+	RetryInterval time.Duration
+}
+
+var client = &http.Client{Timeout: 10 * time.Second}
+
+// Deliver posts the payload of the event to the webhook as JSON, retrying the failed attempts.
+// It returns the error of the last attempt and the number of attempts made.
+func (w *Webhook) Deliver(ctx context.Context, event string, payload interface{}) (int, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return 0, err
+	}
+	interval := w.RetryInterval
+	if interval == 0 {
+		interval = DefaultRetryInterval
+	}
+	attempts := 0
+	for {
+		attempts++
+		err = w.post(ctx, event, body)
+		if err == nil || attempts > w.Retries {
+			return attempts, err
+		}
+		select {
+		case <-ctx.Done():
+			return attempts, ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+func (w *Webhook) post(ctx context.Context, event string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, event)
+	if len(w.Key) > 0 {
+		req.Header.Set(SignatureHeader, Sign(w.Key, body))
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook %s responded with %s", w.URL, resp.Status)
+	}
+	return nil
+}
+
+// Sign returns the value of SignatureHeader for the body signed with the key.
+func Sign(key, body []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
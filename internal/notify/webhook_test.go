@@ -0,0 +1,42 @@
+package notify
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWebhook_Deliver(t *testing.T) {
+	key := []byte("secret")
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := ioutil.ReadAll(r.Body)
+		if got := r.Header.Get(SignatureHeader); got != Sign(key, body) {
+			t.Errorf("signature = %q, want %q", got, Sign(key, body))
+		}
+		if got := r.Header.Get(EventHeader); got != "backup.succeeded" {
+			t.Errorf("event = %q", got)
+		}
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	w := &Webhook{URL: srv.URL, Key: key, Retries: 2, RetryInterval: time.Millisecond}
+	attempts, err := w.Deliver(context.Background(), "backup.succeeded", map[string]string{"hotBackup": "hot-backup"})
+	if err != nil || attempts != 3 {
+		t.Errorf("Deliver() = %d, %v, want 3 attempts and no error", attempts, err)
+	}
+
+	calls = 0
+	w.Retries = 1
+	attempts, err = w.Deliver(context.Background(), "backup.succeeded", map[string]string{"hotBackup": "hot-backup"})
+	if err == nil || attempts != 2 {
+		t.Errorf("Deliver() = %d, %v, want 2 failed attempts", attempts, err)
+	}
+}
@@ -2,9 +2,11 @@ package rest
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -14,6 +16,11 @@ type UploadService struct {
 }
 
 func NewUploadService(address string) (*UploadService, error) {
+	return NewUploadServiceWithClient(address, &http.Client{})
+}
+
+// NewUploadServiceWithClient returns the service of the agent at the address reached with the given HTTP client.
+func NewUploadServiceWithClient(address string, httpClient *http.Client) (*UploadService, error) {
 	baseURL, err := url.Parse(address)
 	if err != nil {
 		return nil, err
@@ -21,21 +28,52 @@ func NewUploadService(address string) (*UploadService, error) {
 	return &UploadService{
 		client: &Client{
 			BaseURL: baseURL,
-			client:  &http.Client{},
+			client:  httpClient,
 		},
 	}, nil
 }
 
 type Upload struct {
-	ID uuid.UUID `json:"ID,omitempty"`
+	ID            uuid.UUID `json:"ID,omitempty"`
+	Status        string    `json:"status,omitempty"`
+	HotBackupName string    `json:"hot_backup_name,omitempty"`
+	AgentVersion  string    `json:"agent_version,omitempty"`
 }
 
 type UploadOptions struct {
-	BucketURL        string `json:"bucket_url"`
-	BackupFolderPath string `json:"backup_folder_path"`
-	HazelcastCRName  string `json:"hz_cr_name"`
-	SecretName       string `json:"secret_name"`
-	MemberUUID       string `json:"member_uuid"`
+	BucketURL        string            `json:"bucket_url"`
+	BackupFolderPath string            `json:"backup_folder_path"`
+	HazelcastCRName  string            `json:"hz_cr_name"`
+	HotBackupName    string            `json:"hot_backup_name"`
+	SecretName       string            `json:"secret_name"`
+	MemberUUID       string            `json:"member_uuid"`
+	HazelcastVersion string            `json:"hz_version"`
+	Compression      string            `json:"compression,omitempty"`
+	KeyEncoding      string            `json:"key_encoding,omitempty"`
+	CompressionLevel int32             `json:"compression_level,omitempty"`
+	VerifyArchive    bool              `json:"verify_archive,omitempty"`
+	ObjectLockMode   string            `json:"object_lock_mode,omitempty"`
+	RetainUntil      string            `json:"retain_until,omitempty"`
+	ObjectACL        *ObjectACL        `json:"object_acl,omitempty"`
+	ChunkedTransfer  bool              `json:"chunked_transfer,omitempty"`
+	DeleteLocal      bool              `json:"delete_local_backup,omitempty"`
+	Metadata         map[string]string `json:"metadata,omitempty"`
+	DynamicConfig    string            `json:"dynamic_config,omitempty"`
+	DeltaBase        string            `json:"delta_base,omitempty"`
+	Zone             string            `json:"zone,omitempty"`
+	RunID            string            `json:"run_id,omitempty"`
+	TriggerCause     string            `json:"trigger_cause,omitempty"`
+	TriggerSource    string            `json:"trigger_source,omitempty"`
+	PartSize         int64             `json:"part_size,omitempty"`
+	MaxObjectSize    int64             `json:"max_object_size,omitempty"`
+	RequestHeaders   map[string]string `json:"request_headers,omitempty"`
+	MaxSize          int64             `json:"max_size,omitempty"`
+	// CredentialsRefreshSeconds is the interval the agent reads the credentials of the bucket again at
+	CredentialsRefreshSeconds int64 `json:"credentials_refresh_seconds,omitempty"`
+	// RateLimit is the maximum number of requests per second the agent sends to the bucket for the upload,
+	// e.g. the parts of a multipart upload, with bursts of RateBurst requests. The requests are not limited if it is 0.
+	RateLimit float64 `json:"rate_limit,omitempty"`
+	RateBurst int     `json:"rate_burst,omitempty"`
 }
 
 func (s *UploadService) Upload(ctx context.Context, opts *UploadOptions) (*Upload, *http.Response, error) {
@@ -55,8 +93,55 @@ func (s *UploadService) Upload(ctx context.Context, opts *UploadOptions) (*Uploa
 	return upload, resp, nil
 }
 
+func (s *UploadService) List(ctx context.Context) ([]Upload, *http.Response, error) {
+	u := "upload"
+
+	req, err := s.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var uploads []Upload
+	resp, err := s.client.Do(ctx, req, &uploads)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return uploads, resp, nil
+}
+
+// ObjectACL is the access control list the agent applies to the uploaded objects.
+type ObjectACL struct {
+	Canned string     `json:"canned,omitempty"`
+	Grants []ACLGrant `json:"grants,omitempty"`
+}
+
+type ACLGrant struct {
+	Grantee    string `json:"grantee"`
+	Permission string `json:"permission"`
+}
+
 type UploadStatus struct {
-	Status string `json:"status,omitempty"`
+	Status           string `json:"status,omitempty"`
+	Reason           string `json:"reason,omitempty"`
+	Message          string `json:"message,omitempty"`
+	CompressionLevel int32  `json:"compression_level,omitempty"`
+	OriginalSize     int64  `json:"original_size,omitempty"`
+	CompressedSize   int64  `json:"compressed_size,omitempty"`
+	BackupKey        string `json:"backup_key,omitempty"`
+	Checksum         string `json:"checksum,omitempty"`
+	UploadedSize     int64  `json:"uploaded_size,omitempty"`
+	Digest           string `json:"digest,omitempty"`
+	AgentVersion     string `json:"agent_version,omitempty"`
+	// Progress is the percentage of the backups of the chain merged by a compaction
+	Progress int32 `json:"progress,omitempty"`
+	// BackupFolder is the folder of the full backup written by a compaction
+	BackupFolder string `json:"backup_folder,omitempty"`
+	// MergedFolders are the backup folders of the chain merged by a compaction
+	MergedFolders []string `json:"merged_folders,omitempty"`
+	// SampledObjects and TotalObjects are the number of objects verified and in the backup by a verification
+	SampledObjects int32 `json:"sampled_objects,omitempty"`
+	TotalObjects   int32 `json:"total_objects,omitempty"`
 }
 
 func (s *UploadService) Status(ctx context.Context, uploadID uuid.UUID) (*UploadStatus, *http.Response, error) {
@@ -91,3 +176,339 @@ func (s *UploadService) Delete(ctx context.Context, uploadID uuid.UUID) (*http.R
 
 	return resp, nil
 }
+
+// Purge makes the agent abort the upload and delete all the parts and objects already uploaded by it.
+func (s *UploadService) Purge(ctx context.Context, uploadID uuid.UUID) (*http.Response, error) {
+	u := fmt.Sprintf("upload/%v?purge=true", uploadID)
+
+	req, err := s.client.NewRequest("DELETE", u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.client.Do(ctx, req, nil)
+}
+
+type LatestOptions struct {
+	BucketURL       string `json:"bucket_url"`
+	SecretName      string `json:"secret_name"`
+	HazelcastCRName string `json:"hz_cr_name"`
+	BackupFolder    string `json:"backup_folder"`
+}
+
+// UpdateLatest makes the agent replace the latest pointer object of the cluster with one pointing to the backup folder.
+func (s *UploadService) UpdateLatest(ctx context.Context, opts *LatestOptions) (*http.Response, error) {
+	u := "latest"
+
+	req, err := s.client.NewRequest("PUT", u, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.client.Do(ctx, req, nil)
+}
+
+type ObjectsDigestOptions struct {
+	BucketURL  string `json:"bucket_url"`
+	SecretName string `json:"secret_name"`
+}
+
+// ObjectsDigest is the digest of the keys and the ETags of the objects in a bucket.
+type ObjectsDigest struct {
+	Digest string `json:"digest"`
+}
+
+// ObjectsDigest makes the agent compute the digest of the keys and the ETags of the objects in the bucket.
+func (s *UploadService) ObjectsDigest(ctx context.Context, opts *ObjectsDigestOptions) (*ObjectsDigest, *http.Response, error) {
+	u := "objects/digest"
+
+	req, err := s.client.NewRequest("POST", u, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	digest := new(ObjectsDigest)
+	resp, err := s.client.Do(ctx, req, digest)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return digest, resp, nil
+}
+
+type ManifestOptions struct {
+	BucketURL    string `json:"bucket_url"`
+	SecretName   string `json:"secret_name"`
+	BackupFolder string `json:"backup_folder"`
+	Digest       string `json:"digest"`
+}
+
+// UpdateManifest makes the agent store the digest of the whole backup set in the manifest of the backup folder.
+func (s *UploadService) UpdateManifest(ctx context.Context, opts *ManifestOptions) (*http.Response, error) {
+	u := "manifest"
+
+	req, err := s.client.NewRequest("PUT", u, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.client.Do(ctx, req, nil)
+}
+
+type DiagnosticsOptions struct {
+	BucketURL  string          `json:"bucket_url"`
+	SecretName string          `json:"secret_name"`
+	Path       string          `json:"path"`
+	MemberUUID string          `json:"member_uuid"`
+	Bundle     json.RawMessage `json:"bundle,omitempty"`
+}
+
+// UploadDiagnostics makes the agent upload its recent logs and, if it is set, the diagnostic bundle of the operator
+// to the path in the bucket.
+func (s *UploadService) UploadDiagnostics(ctx context.Context, opts *DiagnosticsOptions) (*http.Response, error) {
+	u := "diagnostics"
+
+	req, err := s.client.NewRequest("PUT", u, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.client.Do(ctx, req, nil)
+}
+
+type VerifyOptions struct {
+	BucketURL    string `json:"bucket_url"`
+	SecretName   string `json:"secret_name"`
+	BackupFolder string `json:"backup_folder"`
+	RestoreTest  bool   `json:"restore_test,omitempty"`
+	// SampleRate is the percentage of the objects the checksums are verified of, all of them if it is zero
+	SampleRate int32 `json:"sample_rate,omitempty"`
+	// SampleSeed makes the agent choose the same sample every time the backup is verified
+	SampleSeed string `json:"sample_seed,omitempty"`
+}
+
+// Verify makes the agent verify the checksums of the backup folder against its manifest.
+func (s *UploadService) Verify(ctx context.Context, opts *VerifyOptions) (*Upload, *http.Response, error) {
+	u := "verify"
+
+	req, err := s.client.NewRequest("POST", u, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	verification := new(Upload)
+	resp, err := s.client.Do(ctx, req, verification)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return verification, resp, nil
+}
+
+func (s *UploadService) VerifyStatus(ctx context.Context, verificationID uuid.UUID) (*UploadStatus, *http.Response, error) {
+	u := fmt.Sprintf("verify/%v", verificationID)
+
+	req, err := s.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	status := new(UploadStatus)
+	resp, err := s.client.Do(ctx, req, status)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return status, resp, nil
+}
+
+type CompactOptions struct {
+	BucketURL       string `json:"bucket_url"`
+	SecretName      string `json:"secret_name"`
+	HazelcastCRName string `json:"hz_cr_name"`
+	BackupFolder    string `json:"backup_folder"`
+}
+
+// Compact makes the agent merge the delta backup in the backup folder with the chain of backups it is applied on
+// into a new full backup.
+func (s *UploadService) Compact(ctx context.Context, opts *CompactOptions) (*Upload, *http.Response, error) {
+	u := "compact"
+
+	req, err := s.client.NewRequest("POST", u, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	compaction := new(Upload)
+	resp, err := s.client.Do(ctx, req, compaction)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return compaction, resp, nil
+}
+
+func (s *UploadService) CompactStatus(ctx context.Context, compactionID uuid.UUID) (*UploadStatus, *http.Response, error) {
+	u := fmt.Sprintf("compact/%v", compactionID)
+
+	req, err := s.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	status := new(UploadStatus)
+	resp, err := s.client.Do(ctx, req, status)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return status, resp, nil
+}
+
+type BackupsOptions struct {
+	BucketURL       string   `json:"bucket_url"`
+	SecretName      string   `json:"secret_name"`
+	HazelcastCRName string   `json:"hz_cr_name"`
+	BackupFolders   []string `json:"backup_folders,omitempty"`
+}
+
+// BackupFolder is a backup of the cluster in the bucket or a local backup of a member.
+type BackupFolder struct {
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	// DeltaBase is the backup folder the delta backup is applied on, empty for full backups
+	DeltaBase string `json:"delta_base,omitempty"`
+	// HotBackup is the name of the HotBackup that took the backup, empty if it is not known
+	HotBackup string `json:"hot_backup,omitempty"`
+}
+
+// ListBackups returns the backup folders under the prefix of the cluster in the bucket.
+func (s *UploadService) ListBackups(ctx context.Context, opts *BackupsOptions) ([]BackupFolder, *http.Response, error) {
+	u := "backups/list"
+
+	req, err := s.client.NewRequest("POST", u, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var folders []BackupFolder
+	resp, err := s.client.Do(ctx, req, &folders)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return folders, resp, nil
+}
+
+// DeleteBackups makes the agent delete all the objects of the given backup folders of the cluster from the bucket.
+func (s *UploadService) DeleteBackups(ctx context.Context, opts *BackupsOptions) (*http.Response, error) {
+	u := "backups/delete"
+
+	req, err := s.client.NewRequest("POST", u, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.client.Do(ctx, req, nil)
+}
+
+// LocalBackupsOptions are the local backups of the member in the directory.
+type LocalBackupsOptions struct {
+	Path       string   `json:"path"`
+	BackupDirs []string `json:"backup_dirs,omitempty"`
+	// HotBackupName is the HotBackup the newest local backup is marked as taken by
+	HotBackupName string `json:"hot_backup_name,omitempty"`
+}
+
+// ListLocalBackups returns the local backups of the member in the directory, their names are the names of their directories.
+func (s *UploadService) ListLocalBackups(ctx context.Context, opts *LocalBackupsOptions) ([]BackupFolder, *http.Response, error) {
+	u := "local-backups/list"
+
+	req, err := s.client.NewRequest("POST", u, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var backups []BackupFolder
+	resp, err := s.client.Do(ctx, req, &backups)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return backups, resp, nil
+}
+
+// DeleteLocalBackups makes the agent delete the given local backup directories of the member from the directory.
+func (s *UploadService) DeleteLocalBackups(ctx context.Context, opts *LocalBackupsOptions) (*http.Response, error) {
+	u := "local-backups/delete"
+
+	req, err := s.client.NewRequest("POST", u, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.client.Do(ctx, req, nil)
+}
+
+// MarkLocalBackup makes the agent record the newest local backup of the member in the directory as taken by the HotBackup.
+func (s *UploadService) MarkLocalBackup(ctx context.Context, opts *LocalBackupsOptions) (*http.Response, error) {
+	u := "local-backups/mark"
+
+	req, err := s.client.NewRequest("POST", u, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.client.Do(ctx, req, nil)
+}
+
+// Footprint is the disk usage of a directory of the member.
+type Footprint struct {
+	Bytes int64 `json:"bytes"`
+}
+
+// Footprint returns the disk usage of the directory on the member of the agent
+// without the excluded subdirectories relative to it.
+func (s *UploadService) Footprint(ctx context.Context, path string, exclude []string) (*Footprint, *http.Response, error) {
+	q := url.Values{"path": {path}}
+	for _, e := range exclude {
+		q.Add("exclude", e)
+	}
+	u := "footprint?" + q.Encode()
+
+	req, err := s.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	footprint := new(Footprint)
+	resp, err := s.client.Do(ctx, req, footprint)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return footprint, resp, nil
+}
+
+// PathStat describes a path on the member of the agent.
+type PathStat struct {
+	IsDir bool `json:"is_dir"`
+}
+
+// Stat returns the description of the path on the member of the agent if the agent can read it.
+func (s *UploadService) Stat(ctx context.Context, path string) (*PathStat, *http.Response, error) {
+	u := "stat?path=" + url.QueryEscape(path)
+
+	req, err := s.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	st := new(PathStat)
+	resp, err := s.client.Do(ctx, req, st)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return st, resp, nil
+}
@@ -0,0 +1,53 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSpans(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(tp)
+	defer otel.SetTracerProvider(prev)
+
+	ctx, root := Start(context.Background(), "HotBackup")
+	_, upload := Start(ctx, "upload")
+	End(upload, errors.New("connection refused"))
+	End(root, nil)
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("spans = %d, want 2", len(spans))
+	}
+	upSpan, rootSpan := spans[0], spans[1]
+	if upSpan.Parent.SpanID() != rootSpan.SpanContext.SpanID() {
+		t.Errorf("upload span is not the child of the backup span")
+	}
+	if upSpan.StatusCode != codes.Error || upSpan.StatusMessage != "connection refused" {
+		t.Errorf("upload span status = %v %q, want the error", upSpan.StatusCode, upSpan.StatusMessage)
+	}
+	if rootSpan.StatusCode == codes.Error {
+		t.Errorf("backup span status = %v, want no error", rootSpan.StatusCode)
+	}
+}
+
+func TestSetTracerProvider(t *testing.T) {
+	shutdown, err := SetTracerProvider(context.Background(), "", false, 1)
+	if err != nil {
+		t.Fatalf("SetTracerProvider() without endpoint: %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("shutdown() = %v", err)
+	}
+	if _, err := SetTracerProvider(context.Background(), "localhost:4317", true, 1.5); err == nil {
+		t.Errorf("SetTracerProvider() with sample ratio 1.5 succeeded, want error")
+	}
+}
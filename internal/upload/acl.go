@@ -0,0 +1,56 @@
+package upload
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// backendACLs are the object ACLs supported by a storage backend.
+type backendACLs struct {
+	canned      map[string]bool
+	permissions map[string]bool
+}
+
+func stringSet(values ...string) map[string]bool {
+	m := make(map[string]bool, len(values))
+	for _, v := range values {
+		m[v] = true
+	}
+	return m
+}
+
+// objectACLs are the ACLs the agent can apply to the uploaded objects, the agent translates the canned ACLs
+// and the permissions to the predefined ACLs and the roles of GCP. Azure has no object ACLs.
+var objectACLs = map[string]backendACLs{
+	"s3": {
+		canned: stringSet("private", "public-read", "public-read-write", "authenticated-read", "aws-exec-read",
+			"bucket-owner-read", "bucket-owner-full-control"),
+		permissions: stringSet("READ", "READ_ACP", "WRITE_ACP", "FULL_CONTROL"),
+	},
+	"gs": {
+		canned: stringSet("private", "public-read", "authenticated-read", "bucket-owner-read",
+			"bucket-owner-full-control", "project-private"),
+		permissions: stringSet("READ", "FULL_CONTROL"),
+	},
+}
+
+// ValidateObjectACL checks that the backend of the bucket supports the canned ACL and the permissions of the grants.
+func ValidateObjectACL(bucketURI, canned string, permissions []string) error {
+	u, err := url.Parse(bucketURI)
+	if err != nil {
+		return fmt.Errorf("invalid bucket URI: %w", err)
+	}
+	acls, ok := objectACLs[u.Scheme]
+	if !ok {
+		return fmt.Errorf("object ACLs are not supported by the bucket %s", bucketURI)
+	}
+	if canned != "" && !acls.canned[canned] {
+		return fmt.Errorf("canned ACL %q is not supported by the bucket %s", canned, bucketURI)
+	}
+	for _, p := range permissions {
+		if !acls.permissions[p] {
+			return fmt.Errorf("ACL permission %q is not supported by the bucket %s", p, bucketURI)
+		}
+	}
+	return nil
+}
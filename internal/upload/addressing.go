@@ -0,0 +1,205 @@
+package upload
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	hzrest "github.com/hazelcast/hazelcast-platform-operator/internal/rest"
+)
+
+// AgentAddressing is how the operator reaches the backup agents of the members.
+type AgentAddressing string
+
+const (
+	// AgentAddressingPodIP reaches the agents on the IPs of the member pods.
+	AgentAddressingPodIP AgentAddressing = "pod-ip"
+	// AgentAddressingAPIServerProxy reaches the agents through the pod proxy of the Kubernetes API server,
+	// for the networks the pod IPs are not routable from the operator in.
+	AgentAddressingAPIServerProxy AgentAddressing = "api-server-proxy"
+)
+
+// ErrAgentUnreachable is returned when the backup agents of the members cannot be reached from the operator.
+var ErrAgentUnreachable = errors.New("Backup agent is not reachable from the operator")
+
+const (
+	agentPort = "8080"
+	// podLookupTimeout is the timeout of looking up the pod of a member by its IP
+	podLookupTimeout = 10 * time.Second
+)
+
+var (
+	addressingMu sync.RWMutex
+	addressing   = AgentAddressingPodIP
+	// agentEndpoint returns the base URL of the agent on the pod with the given IP and the HTTP client reaching it
+	agentEndpoint = podIPEndpoint
+)
+
+type (
+	namespaceKey struct{}
+	podCacheKey  struct{}
+)
+
+// podCache keeps the names of the pods of the members looked up by their IPs.
+type podCache struct {
+	mu    sync.Mutex
+	names map[string]string
+}
+
+// WithNamespace returns a copy of ctx carrying the namespace of the Hazelcast resource the members belong to.
+// The pods of the members are looked up in it with the api-server-proxy addressing.
+func WithNamespace(ctx context.Context, namespace string) context.Context {
+	return context.WithValue(ctx, namespaceKey{}, namespace)
+}
+
+// WithPodCache returns a copy of ctx caching the names of the pods looked up by the IPs of the members with the
+// api-server-proxy addressing, so each pod is looked up once by all the calls made with ctx, e.g. during a backup run.
+func WithPodCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, podCacheKey{}, &podCache{names: make(map[string]string)})
+}
+
+// SetAgentAddressing configures how the backup agents of the members are reached. The pod proxy of the API server is reached
+// with the configuration cfg, the pods of the members are looked up by their IPs with the reader c
+// in the namespace set on the context with WithNamespace.
+func SetAgentAddressing(a AgentAddressing, cfg *rest.Config, c client.Reader) error {
+	var endpoint func(ctx context.Context, host string) (string, *http.Client, error)
+	switch a {
+	case AgentAddressingPodIP, "":
+		a, endpoint = AgentAddressingPodIP, podIPEndpoint
+	case AgentAddressingAPIServerProxy:
+		transport, err := rest.TransportFor(cfg)
+		if err != nil {
+			return fmt.Errorf("could not create the transport of the API server proxy: %w", err)
+		}
+		endpoint = apiServerProxyEndpoint(cfg.Host, &http.Client{Transport: transport}, c)
+	default:
+		return fmt.Errorf("unknown agent addressing %q, it must be %s or %s", a, AgentAddressingPodIP, AgentAddressingAPIServerProxy)
+	}
+	addressingMu.Lock()
+	defer addressingMu.Unlock()
+	addressing, agentEndpoint = a, endpoint
+	return nil
+}
+
+func podIPEndpoint(_ context.Context, host string) (string, *http.Client, error) {
+	return "http://" + net.JoinHostPort(host, agentPort), &http.Client{}, nil
+}
+
+func apiServerProxyEndpoint(apiServer string, httpClient *http.Client, c client.Reader) func(ctx context.Context, host string) (string, *http.Client, error) {
+	if !strings.Contains(apiServer, "://") {
+		apiServer = "https://" + apiServer
+	}
+	apiServer = strings.TrimSuffix(apiServer, "/")
+	return func(ctx context.Context, host string) (string, *http.Client, error) {
+		namespace, _ := ctx.Value(namespaceKey{}).(string)
+		if namespace == "" {
+			return "", nil, fmt.Errorf("could not look up the pod with IP %s: namespace of the member is not known", host)
+		}
+		proxy := func(pod string) string {
+			return fmt.Sprintf("%s/api/v1/namespaces/%s/pods/%s/proxy/", apiServer,
+				url.PathEscape(namespace), url.PathEscape(pod+":"+agentPort))
+		}
+		cache, _ := ctx.Value(podCacheKey{}).(*podCache)
+		if cache != nil {
+			cache.mu.Lock()
+			pod, ok := cache.names[namespace+"/"+host]
+			cache.mu.Unlock()
+			if ok {
+				return proxy(pod), httpClient, nil
+			}
+		}
+		ctx, cancel := context.WithTimeout(ctx, podLookupTimeout)
+		defer cancel()
+		pods := &corev1.PodList{}
+		if err := c.List(ctx, pods, client.InNamespace(namespace), client.MatchingFields{"status.podIP": host}); err != nil {
+			return "", nil, fmt.Errorf("could not look up the pod with IP %s: %w", host, err)
+		}
+		for _, p := range pods.Items {
+			if p.Status.Phase != corev1.PodRunning {
+				continue
+			}
+			if cache != nil {
+				cache.mu.Lock()
+				cache.names[namespace+"/"+host] = p.Name
+				cache.mu.Unlock()
+			}
+			return proxy(p.Name), httpClient, nil
+		}
+		return "", nil, fmt.Errorf("no running pod in namespace %s has IP %s", namespace, host)
+	}
+}
+
+func agentService(ctx context.Context, memberAddress string) (*hzrest.UploadService, error) {
+	host, _, err := net.SplitHostPort(memberAddress)
+	if err != nil {
+		return nil, err
+	}
+	addressingMu.RLock()
+	endpoint := agentEndpoint
+	addressingMu.RUnlock()
+	address, httpClient, err := endpoint(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	return hzrest.NewUploadServiceWithClient(address, httpClient)
+}
+
+// CheckAgents checks that the backup agents of all the members are reachable from the operator.
+// The error lists the unreachable members, it wraps ErrAgentUnreachable.
+func CheckAgents(ctx context.Context, memberAddresses []string) error {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	unreachable := make(map[string]error)
+	for _, addr := range memberAddresses {
+		addr := addr
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := checkAgent(ctx, addr); err != nil {
+				mu.Lock()
+				unreachable[addr] = err
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if len(unreachable) == 0 {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	addrs := make([]string, 0, len(unreachable))
+	for addr := range unreachable {
+		addrs = append(addrs, addr)
+	}
+	sort.Strings(addrs)
+	addressingMu.RLock()
+	a := addressing
+	addressingMu.RUnlock()
+	return fmt.Errorf("%w: agents of members %s cannot be reached with the %s addressing, first error: %v",
+		ErrAgentUnreachable, strings.Join(addrs, ", "), a, unreachable[addrs[0]])
+}
+
+func checkAgent(ctx context.Context, memberAddress string) error {
+	s, err := agentService(ctx, memberAddress)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, DefaultConnectTimeout)
+	defer cancel()
+	_, _, err = s.List(ctx)
+	return err
+}
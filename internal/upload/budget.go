@@ -0,0 +1,39 @@
+package upload
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrSizeBudgetExceeded is returned when the uploaded backup is larger than the size budget of the HotBackup.
+var ErrSizeBudgetExceeded = errors.New("Backup size budget exceeded")
+
+// SizeBudget limits the total number of bytes uploaded by the member uploads of a cluster backup.
+// It is shared by the uploads and safe for concurrent use. A nil SizeBudget is unlimited.
+type SizeBudget struct {
+	mu    sync.Mutex
+	limit int64
+	used  int64
+}
+
+// NewSizeBudget returns the budget for the given number of bytes, nil if the limit is not positive.
+func NewSizeBudget(limit int64) *SizeBudget {
+	if limit <= 0 {
+		return nil
+	}
+	return &SizeBudget{limit: limit}
+}
+
+func (b *SizeBudget) charge(n int64) error {
+	if b == nil || n <= 0 {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.used += n
+	if b.used > b.limit {
+		return fmt.Errorf("%w: %d bytes uploaded, the limit is %d", ErrSizeBudgetExceeded, b.used, b.limit)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package upload
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// ErrBucketCircuitOpen is returned for the buckets failing repeatedly until the next retry is allowed.
+var ErrBucketCircuitOpen = errors.New("Bucket circuit open")
+
+// maxCircuitOpenInterval caps the exponential backoff of the failing buckets.
+const maxCircuitOpenInterval = time.Hour
+
+// breaker is shared by all uploads to stop uploading to the buckets failing repeatedly.
+var breaker = newCircuitBreaker(0, 0)
+
+type circuitBreaker struct {
+	mu sync.Mutex
+	// threshold is the number of consecutive failures opening the circuit, 0 disables the circuit breaker
+	threshold int
+	interval  time.Duration
+	buckets   map[string]*bucketCircuit
+	now       func() time.Time
+}
+
+type bucketCircuit struct {
+	failures  int
+	openUntil time.Time
+}
+
+func newCircuitBreaker(threshold int, interval time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		threshold: threshold,
+		interval:  interval,
+		buckets:   make(map[string]*bucketCircuit),
+		now:       time.Now,
+	}
+}
+
+// SetCircuitBreaker makes the uploads to a bucket fail fast after threshold consecutive failures.
+// The next upload is allowed after the interval which doubles with every further failure.
+// A non-positive threshold disables the circuit breaker.
+func SetCircuitBreaker(threshold int, interval time.Duration) {
+	breaker.mu.Lock()
+	defer breaker.mu.Unlock()
+	breaker.threshold = threshold
+	breaker.interval = interval
+	breaker.buckets = make(map[string]*bucketCircuit)
+}
+
+// CheckBucket returns ErrBucketCircuitOpen if no upload should be started to the bucket at the moment.
+func CheckBucket(bucketURI string) error {
+	return breaker.check(bucketKey(bucketURI))
+}
+
+// BucketFailed records a failed upload to the bucket.
+func BucketFailed(bucketURI string) {
+	breaker.failed(bucketKey(bucketURI))
+}
+
+// BucketSucceeded records a successful upload to the bucket closing its circuit.
+func BucketSucceeded(bucketURI string) {
+	breaker.succeeded(bucketKey(bucketURI))
+}
+
+func (b *circuitBreaker) check(key string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c, ok := b.buckets[key]
+	if !ok || b.threshold <= 0 || c.failures < b.threshold {
+		return nil
+	}
+	if until := c.openUntil; b.now().Before(until) {
+		return fmt.Errorf("%w: %d consecutive failures, next retry after %s", ErrBucketCircuitOpen, c.failures, until.UTC().Format(time.RFC3339))
+	}
+	// half-open, let the upload probe the bucket
+	return nil
+}
+
+func (b *circuitBreaker) failed(key string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.threshold <= 0 {
+		return
+	}
+	c, ok := b.buckets[key]
+	if !ok {
+		c = &bucketCircuit{}
+		b.buckets[key] = c
+	}
+	c.failures++
+	if c.failures < b.threshold {
+		return
+	}
+	interval := b.interval
+	for i := b.threshold; i < c.failures && interval < maxCircuitOpenInterval; i++ {
+		interval *= 2
+	}
+	if interval > maxCircuitOpenInterval {
+		interval = maxCircuitOpenInterval
+	}
+	c.openUntil = b.now().Add(interval)
+}
+
+func (b *circuitBreaker) succeeded(key string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.buckets, key)
+}
+
+// bucketKey identifies the bucket of the URI ignoring the query parameters like the prefix.
+func bucketKey(bucketURI string) string {
+	u, err := url.Parse(bucketURI)
+	if err != nil {
+		return bucketURI
+	}
+	return u.Scheme + "://" + u.Host
+}
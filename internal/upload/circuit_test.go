@@ -0,0 +1,47 @@
+package upload
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	b := newCircuitBreaker(2, time.Minute)
+	b.now = func() time.Time { return now }
+	bucket := bucketKey("s3://backup?prefix=hazelcast/")
+
+	b.failed(bucket)
+	if err := b.check(bucket); err != nil {
+		t.Fatalf("check() after 1 failure = %v, want nil", err)
+	}
+	b.failed(bucket)
+	if err := b.check(bucket); !errors.Is(err, ErrBucketCircuitOpen) {
+		t.Fatalf("check() after 2 failures = %v, want %v", err, ErrBucketCircuitOpen)
+	}
+	if err := b.check(bucketKey("s3://other")); err != nil {
+		t.Errorf("check() of other bucket = %v, want nil", err)
+	}
+
+	// half-open after the interval, failing again doubles the interval
+	now = now.Add(time.Minute)
+	if err := b.check(bucket); err != nil {
+		t.Fatalf("check() after interval = %v, want nil", err)
+	}
+	b.failed(bucket)
+	now = now.Add(time.Minute)
+	if err := b.check(bucket); !errors.Is(err, ErrBucketCircuitOpen) {
+		t.Fatalf("check() before doubled interval = %v, want %v", err, ErrBucketCircuitOpen)
+	}
+	now = now.Add(time.Minute)
+	if err := b.check(bucket); err != nil {
+		t.Fatalf("check() after doubled interval = %v, want nil", err)
+	}
+
+	b.succeeded(bucket)
+	b.failed(bucket)
+	if err := b.check(bucket); err != nil {
+		t.Errorf("check() after success and 1 failure = %v, want nil", err)
+	}
+}
@@ -0,0 +1,77 @@
+package upload
+
+import (
+	"errors"
+	"strings"
+)
+
+// Bucket errors reported by the agents, normalized over the storage providers
+var (
+	// ErrBucketNotFound is returned when the bucket or the container does not exist.
+	ErrBucketNotFound = errors.New("Bucket not found")
+
+	// ErrAccessDenied is returned when the credentials of the bucket are invalid or lack permissions.
+	ErrAccessDenied = errors.New("Access to the bucket denied")
+
+	// ErrBucketThrottled is returned when the storage provider rejects the requests because of their rate.
+	ErrBucketThrottled = errors.New("Requests to the bucket throttled")
+
+	// ErrBucketUnreachable is returned when the agent cannot reach the storage provider.
+	ErrBucketUnreachable = errors.New("Bucket unreachable")
+)
+
+// Bucket failure reasons reported by the agent
+const (
+	reasonBucketNotFound = "BUCKET_NOT_FOUND"
+	reasonAccessDenied   = "ACCESS_DENIED"
+	reasonThrottled      = "THROTTLED"
+	reasonNetwork        = "NETWORK"
+)
+
+// bucketErrorCodes are the error codes and messages of the storage providers passed on by the agents
+// not reporting the failure reason.
+var bucketErrorCodes = []struct {
+	err   error
+	codes []string
+}{
+	{ErrBucketNotFound, []string{
+		"NoSuchBucket", "ContainerNotFound", "storage: bucket doesn't exist",
+	}},
+	{ErrAccessDenied, []string{
+		"AccessDenied", "InvalidAccessKeyId", "SignatureDoesNotMatch", "ExpiredToken",
+		"AuthorizationFailure", "AuthorizationPermissionMismatch", "AuthenticationFailed", "googleapi: Error 403",
+	}},
+	{ErrBucketThrottled, []string{
+		"SlowDown", "Throttling", "RequestLimitExceeded", "ServerBusy", "rateLimitExceeded", "googleapi: Error 429",
+	}},
+	{ErrBucketUnreachable, []string{
+		"RequestTimeout", "connection refused", "connection reset", "no such host", "i/o timeout",
+	}},
+}
+
+// bucketReasonError returns the bucket error of the failure reason reported by the agent or nil if it is not a bucket error.
+func bucketReasonError(reason string) error {
+	switch reason {
+	case reasonBucketNotFound:
+		return ErrBucketNotFound
+	case reasonAccessDenied:
+		return ErrAccessDenied
+	case reasonThrottled:
+		return ErrBucketThrottled
+	case reasonNetwork:
+		return ErrBucketUnreachable
+	}
+	return nil
+}
+
+// classifyMessage returns the bucket error matching the error of the storage provider in the message or nil.
+func classifyMessage(message string) error {
+	for _, c := range bucketErrorCodes {
+		for _, code := range c.codes {
+			if strings.Contains(message, code) {
+				return c.err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package upload
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/hazelcast/hazelcast-platform-operator/internal/rest"
+)
+
+// Compaction is the full backup written by a compaction.
+type Compaction struct {
+	// BackupFolder is the folder of the new full backup
+	BackupFolder string
+	// MergedFolders are the backup folders of the merged chain
+	MergedFolders []string
+}
+
+// Compact makes the agent of the member merge the delta backup in the backup folder with the chain of backups it is applied on
+// into a new full backup in the bucket. The progress of the compaction in percent is reported to the progress function.
+func Compact(ctx context.Context, memberAddress string, config *Config, backupFolder string, progress func(int32)) (*Compaction, error) {
+	s, err := agentService(ctx, memberAddress)
+	if err != nil {
+		return nil, err
+	}
+	return compact(ctx, s, &rest.CompactOptions{
+		BucketURL:       config.BucketURI,
+		SecretName:      config.SecretName,
+		HazelcastCRName: config.HazelcastName,
+		BackupFolder:    backupFolder,
+	}, progress)
+}
+
+func compact(ctx context.Context, s *rest.UploadService, opts *rest.CompactOptions, progress func(int32)) (*Compaction, error) {
+	if err := limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	c, _, err := s.Compact(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var reported int32
+	for {
+		status, _, err := s.CompactStatus(ctx, c.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		switch status.Status {
+		case "FAILURE":
+			return nil, statusError(status)
+		case "SUCCESS":
+			return &Compaction{BackupFolder: status.BackupFolder, MergedFolders: status.MergedFolders}, nil
+		case "IN_PROGRESS":
+			// expected, check status again (no return)
+			if status.Progress != reported {
+				reported = status.Progress
+				progress(reported)
+			}
+		default:
+			return nil, errors.New("Compaction unknown status: " + status.Status)
+		}
+
+		select {
+		case <-time.After(1 * time.Second):
+			continue
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package upload
+
+import (
+	"context"
+	"sync"
+)
+
+var (
+	slotsMu sync.RWMutex
+	// slots is the semaphore of the member uploads of all backups, the uploads are not limited if it is nil
+	slots chan struct{}
+)
+
+// SetMaxConcurrentUploads limits the number of member uploads running at the same time across all backups and clusters.
+// The uploads over the limit wait for a running one to finish. A non-positive max removes the limit.
+func SetMaxConcurrentUploads(max int) {
+	slotsMu.Lock()
+	defer slotsMu.Unlock()
+	if max <= 0 {
+		slots = nil
+		return
+	}
+	slots = make(chan struct{}, max)
+}
+
+func uploadSlots() chan struct{} {
+	slotsMu.RLock()
+	defer slotsMu.RUnlock()
+	return slots
+}
+
+// limitedSink holds a slot of the operator-wide upload limit from the start of the transfer until it is finished.
+type limitedSink struct {
+	BackupSink
+	slots chan struct{}
+
+	mu   sync.Mutex
+	held bool
+}
+
+func (s *limitedSink) Start(ctx context.Context) error {
+	select {
+	case s.slots <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	s.mu.Lock()
+	s.held = true
+	s.mu.Unlock()
+
+	err := s.BackupSink.Start(ctx)
+	if err != nil {
+		s.release()
+	}
+	return err
+}
+
+func (s *limitedSink) Wait(ctx context.Context) error {
+	defer s.release()
+	return s.BackupSink.Wait(ctx)
+}
+
+func (s *limitedSink) Cancel(ctx context.Context) error {
+	s.release()
+	return s.BackupSink.Cancel(ctx)
+}
+
+func (s *limitedSink) Purge(ctx context.Context) error {
+	s.release()
+	return s.BackupSink.Purge(ctx)
+}
+
+func (s *limitedSink) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.held {
+		s.held = false
+		<-s.slots
+	}
+}
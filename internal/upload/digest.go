@@ -0,0 +1,41 @@
+package upload
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+)
+
+// Digest returns the Merkle root over the checksums of the member backup objects keyed by the object keys.
+// The leaves are sorted by key so the digest does not depend on the order the members finished in.
+// It returns an empty string if there are no checksums.
+func Digest(checksums map[string]string) string {
+	if len(checksums) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(checksums))
+	for k := range checksums {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	level := make([][]byte, 0, len(keys))
+	for _, k := range keys {
+		h := sha256.Sum256([]byte(k + "\x00" + checksums[k]))
+		level = append(level, h[:])
+	}
+	for len(level) > 1 {
+		next := make([][]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				// odd node is promoted to the next level unchanged
+				next = append(next, level[i])
+				continue
+			}
+			h := sha256.Sum256(append(append([]byte{}, level[i]...), level[i+1]...))
+			next = append(next, h[:])
+		}
+		level = next
+	}
+	return hex.EncodeToString(level[0])
+}
@@ -0,0 +1,66 @@
+package upload
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Encodings of the segments of the object keys, e.g. the names of the cluster and of the structures.
+// The agents encode the segments of the keys they build with the encoding of the backup and record it
+// in the manifest, the restore, the verification and the compaction read it from there.
+const (
+	// KeyEncodingLowercase lowercases the segments for the case-insensitive stores.
+	// The original segments are recorded in the manifest.
+	KeyEncodingLowercase = "Lowercase"
+	// KeyEncodingPercent percent-encodes every byte of the segments except lowercase letters, digits, '-', '.' and '_'
+	// with lowercase hex digits, so the keys contain no upper case letters and no characters the stores restrict.
+	// It is reversible without the manifest, see DecodeKeySegment.
+	KeyEncodingPercent = "Percent"
+	// KeyEncodingHash replaces the segments longer than MaxKeySegmentLength with their first bytes followed by
+	// the hex of their SHA-256 hash. The original segments are recorded in the manifest.
+	KeyEncodingHash = "Hash"
+)
+
+// MaxKeySegmentLength is the length of the longest segment kept by KeyEncodingHash.
+const MaxKeySegmentLength = 64
+
+// hashedPrefixLength is the length of the prefix of the hashed segments kept to recognize them
+const hashedPrefixLength = 31
+
+// EncodeKeySegment returns the segment of an object key encoded with the encoding, the segment is not changed if it is empty.
+func EncodeKeySegment(segment, encoding string) string {
+	switch encoding {
+	case KeyEncodingLowercase:
+		return strings.ToLower(segment)
+	case KeyEncodingPercent:
+		var b strings.Builder
+		for i := 0; i < len(segment); i++ {
+			c := segment[i]
+			if c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-' || c == '.' || c == '_' {
+				b.WriteByte(c)
+				continue
+			}
+			fmt.Fprintf(&b, "%%%02x", c)
+		}
+		return b.String()
+	case KeyEncodingHash:
+		if len(segment) <= MaxKeySegmentLength {
+			return segment
+		}
+		sum := sha256.Sum256([]byte(segment))
+		return segment[:hashedPrefixLength] + "-" + hex.EncodeToString(sum[:])[:MaxKeySegmentLength-hashedPrefixLength-1]
+	}
+	return segment
+}
+
+// DecodeKeySegment returns the original segment encoded with KeyEncodingPercent. The segments encoded with the other encodings
+// are returned as they are, their original is recorded in the manifest of the backup.
+func DecodeKeySegment(segment, encoding string) (string, error) {
+	if encoding != KeyEncodingPercent {
+		return segment, nil
+	}
+	return url.PathUnescape(segment)
+}
@@ -0,0 +1,67 @@
+package upload
+
+import (
+	"fmt"
+	"net/url"
+)
+
+const (
+	kib int64 = 1 << 10
+	mib       = kib << 10
+	gib       = mib << 10
+	tib       = gib << 10
+)
+
+// backendLimits are the multipart upload constraints of a storage backend.
+type backendLimits struct {
+	minPartSize     int64
+	maxPartSize     int64
+	defaultPartSize int64
+	maxParts        int64
+	maxObjectSize   int64
+}
+
+var multipartLimits = map[string]backendLimits{
+	"s3":     {minPartSize: 5 * mib, maxPartSize: 5 * gib, defaultPartSize: 16 * mib, maxParts: 10000, maxObjectSize: 5 * tib},
+	"gs":     {minPartSize: 5 * mib, maxPartSize: 5 * gib, defaultPartSize: 16 * mib, maxParts: 10000, maxObjectSize: 5 * tib},
+	"azblob": {minPartSize: 1, maxPartSize: 4000 * mib, defaultPartSize: 8 * mib, maxParts: 50000, maxObjectSize: 50000 * 4000 * mib},
+}
+
+// DefaultPartSize returns the part size used for the multipart uploads to the bucket if it is not configured.
+// It is 0 for the backends without known constraints, leaving the choice to the agent.
+func DefaultPartSize(bucketURI string) int64 {
+	return limitsOf(bucketURI).defaultPartSize
+}
+
+// ValidateMultipart checks the part size and the maximum object size against the constraints of the backend
+// of the bucket. Zero values mean the defaults and are always valid.
+func ValidateMultipart(bucketURI string, partSize, maxObjectSize int64) error {
+	l := limitsOf(bucketURI)
+	if l == (backendLimits{}) {
+		return nil
+	}
+	if partSize != 0 && (partSize < l.minPartSize || partSize > l.maxPartSize) {
+		return fmt.Errorf("part size %d is out of the range %d-%d of the bucket %s", partSize, l.minPartSize, l.maxPartSize, bucketURI)
+	}
+	if maxObjectSize == 0 {
+		return nil
+	}
+	if maxObjectSize > l.maxObjectSize {
+		return fmt.Errorf("maximum object size %d is larger than the limit %d of the bucket %s", maxObjectSize, l.maxObjectSize, bucketURI)
+	}
+	if partSize == 0 {
+		partSize = l.defaultPartSize
+	}
+	if maxObjectSize > partSize*l.maxParts {
+		return fmt.Errorf("objects of %d bytes need more than %d parts of %d bytes, increase the part size", maxObjectSize, l.maxParts, partSize)
+	}
+	return nil
+}
+
+func limitsOf(bucketURI string) backendLimits {
+	u, err := url.Parse(bucketURI)
+	if err != nil {
+		return backendLimits{}
+	}
+	return multipartLimits[u.Scheme]
+}
@@ -0,0 +1,82 @@
+package upload
+
+import (
+	"context"
+
+	"github.com/hazelcast/hazelcast-platform-operator/internal/rest"
+)
+
+// ListBackups makes the agent of the member list the backup folders of the cluster in the bucket.
+func ListBackups(ctx context.Context, memberAddress string, config *Config) ([]rest.BackupFolder, error) {
+	s, err := agentService(ctx, memberAddress)
+	if err != nil {
+		return nil, err
+	}
+	if err := limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	folders, _, err := s.ListBackups(ctx, &rest.BackupsOptions{
+		BucketURL:       config.BucketURI,
+		SecretName:      config.SecretName,
+		HazelcastCRName: config.HazelcastName,
+	})
+	return folders, err
+}
+
+// DeleteBackups makes the agent of the member delete the given backup folders of the cluster from the bucket.
+func DeleteBackups(ctx context.Context, memberAddress string, config *Config, backupFolders []string) error {
+	s, err := agentService(ctx, memberAddress)
+	if err != nil {
+		return err
+	}
+	if err := limiter.Wait(ctx); err != nil {
+		return err
+	}
+	_, err = s.DeleteBackups(ctx, &rest.BackupsOptions{
+		BucketURL:       config.BucketURI,
+		SecretName:      config.SecretName,
+		HazelcastCRName: config.HazelcastName,
+		BackupFolders:   backupFolders,
+	})
+	return err
+}
+
+// ListLocalBackups makes the agent of the member list the local backups of the member in the directory.
+func ListLocalBackups(ctx context.Context, memberAddress, dir string) ([]rest.BackupFolder, error) {
+	s, err := agentService(ctx, memberAddress)
+	if err != nil {
+		return nil, err
+	}
+	if err := limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	backups, _, err := s.ListLocalBackups(ctx, &rest.LocalBackupsOptions{Path: dir})
+	return backups, err
+}
+
+// DeleteLocalBackups makes the agent of the member delete the given local backups of the member from the directory.
+func DeleteLocalBackups(ctx context.Context, memberAddress, dir string, backupDirs []string) error {
+	s, err := agentService(ctx, memberAddress)
+	if err != nil {
+		return err
+	}
+	if err := limiter.Wait(ctx); err != nil {
+		return err
+	}
+	_, err = s.DeleteLocalBackups(ctx, &rest.LocalBackupsOptions{Path: dir, BackupDirs: backupDirs})
+	return err
+}
+
+// MarkLocalBackup makes the agent of the member record its newest local backup in the directory as taken by the HotBackup,
+// the local backups are listed with the HotBackup they were marked with.
+func MarkLocalBackup(ctx context.Context, memberAddress, dir, hotBackupName string) error {
+	s, err := agentService(ctx, memberAddress)
+	if err != nil {
+		return err
+	}
+	if err := limiter.Wait(ctx); err != nil {
+		return err
+	}
+	_, err = s.MarkLocalBackup(ctx, &rest.LocalBackupsOptions{Path: dir, HotBackupName: hotBackupName})
+	return err
+}
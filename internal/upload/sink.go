@@ -0,0 +1,74 @@
+package upload
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+
+	"github.com/hazelcast/hazelcast-platform-operator/internal/rest"
+)
+
+// BackupSink is the destination the backup of a single member is transferred to.
+type BackupSink interface {
+	// Start starts the transfer of the member backup.
+	Start(ctx context.Context) error
+	// Wait blocks until the transfer is finished or ctx is canceled.
+	Wait(ctx context.Context) error
+	// Cancel stops the transfer and removes the partially transferred backup.
+	Cancel(ctx context.Context) error
+	// Purge stops the transfer if it is still running and removes everything transferred, including
+	// the finished parts and objects.
+	Purge(ctx context.Context) error
+	// Status returns the last status of the transfer.
+	Status() rest.UploadStatus
+	// RetryCount returns the number of times the transfer had to be retried.
+	RetryCount() int32
+	// LastRetryError returns the error causing the last retry or nil if there was no retry.
+	LastRetryError() error
+}
+
+// SinkFactory creates the sink for the given upload configuration.
+type SinkFactory func(ctx context.Context, config *Config) (BackupSink, error)
+
+var (
+	sinksMu sync.RWMutex
+	sinks   = make(map[string]SinkFactory)
+)
+
+func init() {
+	// buckets and HTTP PUT endpoints supported by the backup agent running next to the members
+	for _, scheme := range []string{"s3", "gs", "azblob", "http", "https"} {
+		RegisterSink(scheme, newAgentUpload)
+	}
+}
+
+// RegisterSink makes the sink created by f available for the bucket URIs with the given scheme.
+// Registering a scheme again replaces the previous factory.
+func RegisterSink(scheme string, f SinkFactory) {
+	sinksMu.Lock()
+	defer sinksMu.Unlock()
+	sinks[scheme] = f
+}
+
+// NewUpload returns the sink registered for the scheme of the bucket URI of the config.
+func NewUpload(ctx context.Context, config *Config) (BackupSink, error) {
+	u, err := url.Parse(config.BucketURI)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bucket URI: %w", err)
+	}
+	sinksMu.RLock()
+	f, ok := sinks[u.Scheme]
+	sinksMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("no backup sink registered for bucket URI scheme %q", u.Scheme)
+	}
+	s, err := f(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	if slots := uploadSlots(); slots != nil {
+		return &limitedSink{BackupSink: s, slots: slots}, nil
+	}
+	return s, nil
+}
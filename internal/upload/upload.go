@@ -3,10 +3,16 @@ package upload
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
+	"golang.org/x/time/rate"
+
 	"github.com/hazelcast/hazelcast-platform-operator/internal/rest"
 )
 
@@ -15,55 +21,473 @@ var (
 	errUploadAlreadyStarted = errors.New("Upload already started")
 	errUploadCanceled       = errors.New("Upload canceled")
 	errUploadFailed         = errors.New("Upload failed")
+
+	// ErrObjectLocked is returned when the bucket's object lock (WORM) policy does not allow
+	// to overwrite or delete the objects. Retrying the operation does not help.
+	ErrObjectLocked = errors.New("Object is protected by the bucket's object lock")
+
+	// ErrArchiveCorrupted is returned when the verification of the uploaded archive fails.
+	ErrArchiveCorrupted = errors.New("Uploaded backup archive is corrupted")
+
+	// ErrDigestMismatch is returned when the digest of the verified backup differs from the one in its manifest.
+	ErrDigestMismatch = errors.New("Backup digest does not match the manifest")
+
+	// ErrDeltaUnsupported is returned when the member does not expose the changelog required by delta backups.
+	ErrDeltaUnsupported = errors.New("Delta backups are not supported by the member")
 )
 
+// Failure reasons reported by the agent
+const (
+	reasonObjectLocked     = "OBJECT_LOCKED"
+	reasonArchiveCorrupted = "ARCHIVE_CORRUPTED"
+	reasonSizeExceeded     = "SIZE_EXCEEDED"
+	reasonDigestMismatch   = "DIGEST_MISMATCH"
+	reasonDeltaUnsupported = "DELTA_UNSUPPORTED"
+)
+
+const (
+	// DefaultConnectTimeout is the default timeout of a single attempt to reach the backup agent.
+	DefaultConnectTimeout = 10 * time.Second
+	// DefaultConnectRetries is the default number of times reaching the backup agent is retried.
+	DefaultConnectRetries = 3
+)
+
+// connectRetryInterval is the time waited before reaching the backup agent again.
+var connectRetryInterval = 2 * time.Second
+
+// limiter is shared by all uploads to throttle the calls made to the backup agents.
+var limiter = rate.NewLimiter(rate.Inf, 0)
+
+// SetRateLimit limits the storage operations of all uploads to rps requests per second
+// with the given burst. A non-positive rps removes the limit.
+// The calls of the operator making the agents read or write the bucket, e.g. starting an upload, listing and
+// deleting the backups, are limited by the operator. The requests the agents send to the bucket while uploading,
+// e.g. the parts of a multipart upload, are limited by the agents, each upload gets its share of the limit,
+// see uploadRateLimit. Polling the status of the uploads, canceling them and checking the agents are not limited.
+func SetRateLimit(rps float64, burst int) {
+	if rps <= 0 {
+		limiter.SetLimit(rate.Inf)
+		return
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	limiter.SetBurst(burst)
+	limiter.SetLimit(rate.Limit(rps))
+}
+
+// uploadRateLimit returns the rate limit and burst of the requests the agent sends to the bucket for an upload,
+// the limit is divided between the uploads which can run at once, see SetMaxConcurrentUploads. It returns 0 if the
+// requests are not limited. Without a limit of the concurrent uploads every upload can use the whole limit.
+func uploadRateLimit() (float64, int) {
+	rps := limiter.Limit()
+	if rps == rate.Inf {
+		return 0, 0
+	}
+	burst := limiter.Burst()
+	if s := uploadSlots(); s != nil {
+		rps /= rate.Limit(cap(s))
+		burst /= cap(s)
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return float64(rps), burst
+}
+
+// Upload is the BackupSink uploading the member backup to a bucket by the backup agent of the member.
 type Upload struct {
 	service  *rest.UploadService
 	uploadID *uuid.UUID
 	config   *Config
+	status   rest.UploadStatus
+	// agentVersion is the version of the agent reported when the upload was started
+	agentVersion string
+
+	retries      int32
+	lastRetryErr error
+	// charged is the number of bytes charged to the size budget
+	charged int64
+	// reported is the number of bytes last reported to the progress function
+	reported int64
 }
 
 type Config struct {
-	MemberAddress string
-	BucketURI     string
-	BackupPath    string
-	HazelcastName string
-	SecretName    string
+	MemberAddress    string
+	BucketURI        string
+	BackupPath       string
+	HazelcastName    string
+	HotBackupName    string
+	SecretName       string
+	HazelcastVersion string
+	Compression      string
+	KeyEncoding      string
+	CompressionLevel int32
+	VerifyArchive    bool
+	ObjectLockMode   string
+	RetainUntil      time.Time
+	// ObjectACL is applied by the agent to the uploaded objects, the default ACL of the bucket is used if it is nil.
+	ObjectACL *rest.ObjectACL
+	// ChunkedTransfer makes the agent stream the objects to HTTP PUT endpoints with chunked transfer encoding.
+	ChunkedTransfer bool
+	// DeleteLocal makes the agent delete the local backup of the member after it is uploaded.
+	DeleteLocal bool
+	Metadata    map[string]string
+	// DeltaBase is the backup folder in the bucket the agent uploads the changelog since, it uploads a full backup if it is empty.
+	DeltaBase string
+	// DynamicConfig is the dynamic configuration of the cluster in YAML the agent writes into the manifest of the backup.
+	DynamicConfig string
+	// Zone of the member the agent writes into the manifest of the backup.
+	Zone string
+	// RunID is the ID of the backup run the agent writes into the manifest and its log lines.
+	RunID string
+	// TriggerCause and TriggerSource tell why the backup was started, the agent writes them into the manifest.
+	TriggerCause  string
+	TriggerSource string
+	// PartSize of the multipart upload in bytes, DefaultPartSize of the bucket is used if it is zero.
+	PartSize int64
+	// MaxObjectSize splits the backup archive into objects of at most this many bytes if it is not zero.
+	MaxObjectSize int64
+	// RequestHeaders are added by the agent to the requests uploading the objects.
+	RequestHeaders map[string]string
+	// MaxSize is the maximum size of the uploaded member backup in bytes enforced by the agent, unlimited if it is zero.
+	MaxSize int64
+	// SizeBudget is the budget of the whole cluster backup the uploaded bytes are charged to.
+	SizeBudget *SizeBudget
+	// Progress is called with the bytes sent by the upload in progress whenever they change, it may be nil.
+	Progress func(uploaded int64)
+	// CredentialsRefreshInterval is the interval the agent reads the credentials of the bucket again at
+	// during the upload, the credentials are read once if it is zero.
+	CredentialsRefreshInterval time.Duration
+
+	// ConnectTimeout is the timeout of a single attempt to start the upload on the agent.
+	// DefaultConnectTimeout is used if it is zero.
+	ConnectTimeout time.Duration
+	// ConnectRetries is the number of times starting the upload is retried while the agent is
+	// not reachable, e.g. during a rolling restart of the member. DefaultConnectRetries is used if it is zero,
+	// starting the upload is not retried if it is negative.
+	ConnectRetries int
+}
+
+func newAgentUpload(ctx context.Context, config *Config) (BackupSink, error) {
+	s, err := agentService(ctx, config.MemberAddress)
+	if err != nil {
+		return nil, err
+	}
+	return &Upload{
+		service: s,
+		config:  config,
+	}, nil
 }
 
-func NewUpload(config *Config) (*Upload, error) {
-	host, _, err := net.SplitHostPort(config.MemberAddress)
+// ListInProgress returns the uploads in progress on the agent of the given member.
+func ListInProgress(ctx context.Context, memberAddress string) ([]*Upload, error) {
+	s, err := agentService(ctx, memberAddress)
 	if err != nil {
 		return nil, err
 	}
-	s, err := rest.NewUploadService("http://" + host + ":8080")
+	if err := limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	list, _, err := s.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var uploads []*Upload
+	for _, l := range list {
+		if l.Status != "IN_PROGRESS" {
+			continue
+		}
+		id := l.ID
+		uploads = append(uploads, &Upload{
+			service:  s,
+			uploadID: &id,
+			config: &Config{
+				MemberAddress: memberAddress,
+				HotBackupName: l.HotBackupName,
+			},
+		})
+	}
+	return uploads, nil
+}
+
+// Find returns the upload of the HotBackup on the agent of the given member, or nil if the agent has none.
+// An upload in progress is preferred to the finished ones of the previous runs.
+func Find(ctx context.Context, memberAddress, hotBackupName string) (*Upload, error) {
+	s, err := agentService(ctx, memberAddress)
 	if err != nil {
 		return nil, err
 	}
+	return find(ctx, s, memberAddress, hotBackupName)
+}
+
+func find(ctx context.Context, s *rest.UploadService, memberAddress, hotBackupName string) (*Upload, error) {
+	if err := limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	list, _, err := s.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var found *rest.Upload
+	for i := range list {
+		l := &list[i]
+		if l.HotBackupName != hotBackupName {
+			continue
+		}
+		if found == nil || found.Status != "IN_PROGRESS" {
+			found = l
+		}
+	}
+	if found == nil {
+		return nil, nil
+	}
+	id := found.ID
 	return &Upload{
-		service: s,
-		config:  config,
+		service:  s,
+		uploadID: &id,
+		config: &Config{
+			MemberAddress: memberAddress,
+			HotBackupName: hotBackupName,
+		},
 	}, nil
 }
 
+// UpdateLatest makes the agent of the member write the latest pointer object under the cluster's
+// prefix in the bucket, pointing to the given backup folder. The agent replaces the object with a single
+// write so readers see either the previous or the new pointer.
+func UpdateLatest(ctx context.Context, memberAddress string, config *Config, backupFolder string) error {
+	s, err := agentService(ctx, memberAddress)
+	if err != nil {
+		return err
+	}
+	if err := limiter.Wait(ctx); err != nil {
+		return err
+	}
+	_, err = s.UpdateLatest(ctx, &rest.LatestOptions{
+		BucketURL:       config.BucketURI,
+		SecretName:      config.SecretName,
+		HazelcastCRName: config.HazelcastName,
+		BackupFolder:    backupFolder,
+	})
+	return err
+}
+
+// ObjectsDigest makes the agent of the member compute the digest of the objects in the bucket,
+// it changes whenever an object is added, removed or overwritten.
+func ObjectsDigest(ctx context.Context, memberAddress string, config *Config) (string, error) {
+	s, err := agentService(ctx, memberAddress)
+	if err != nil {
+		return "", err
+	}
+	if err := limiter.Wait(ctx); err != nil {
+		return "", err
+	}
+	d, _, err := s.ObjectsDigest(ctx, &rest.ObjectsDigestOptions{
+		BucketURL:  config.BucketURI,
+		SecretName: config.SecretName,
+	})
+	if err != nil {
+		return "", err
+	}
+	return d.Digest, nil
+}
+
+// Footprint returns the disk usage of the persistence directory of the member in bytes without the excluded
+// subdirectories, e.g. the local backups, which is about the size of the member backup before compression.
+func Footprint(ctx context.Context, memberAddress, path string, exclude ...string) (int64, error) {
+	s, err := agentService(ctx, memberAddress)
+	if err != nil {
+		return 0, err
+	}
+	if err := limiter.Wait(ctx); err != nil {
+		return 0, err
+	}
+	f, _, err := s.Footprint(ctx, path, exclude)
+	if err != nil {
+		return 0, err
+	}
+	return f.Bytes, nil
+}
+
+// CheckPath checks that the directory can be read on all the members by their backup agents.
+// Only the directory itself is checked, its contents are not walked.
+func CheckPath(ctx context.Context, memberAddresses []string, path string) error {
+	for _, addr := range memberAddresses {
+		if err := checkPath(ctx, addr, path); err != nil {
+			return fmt.Errorf("backup path %s cannot be read on member %s: %w", path, addr, err)
+		}
+	}
+	return nil
+}
+
+func checkPath(ctx context.Context, memberAddress, path string) error {
+	s, err := agentService(ctx, memberAddress)
+	if err != nil {
+		return err
+	}
+	if err := limiter.Wait(ctx); err != nil {
+		return err
+	}
+	st, _, err := s.Stat(ctx, path)
+	if err != nil {
+		return err
+	}
+	if !st.IsDir {
+		return errors.New("not a directory")
+	}
+	return nil
+}
+
+// UpdateManifest makes the agent of the member store the digest of the backup set in the manifest of
+// the backup folder, so the backup can be verified as a whole before it is restored.
+func UpdateManifest(ctx context.Context, memberAddress string, config *Config, backupFolder, digest string) error {
+	s, err := agentService(ctx, memberAddress)
+	if err != nil {
+		return err
+	}
+	if err := limiter.Wait(ctx); err != nil {
+		return err
+	}
+	_, err = s.UpdateManifest(ctx, &rest.ManifestOptions{
+		BucketURL:    config.BucketURI,
+		SecretName:   config.SecretName,
+		BackupFolder: backupFolder,
+		Digest:       digest,
+	})
+	return err
+}
+
+// UploadDiagnostics makes the agent of the member upload its logs and the given diagnostic bundle to the path in the bucket.
+func UploadDiagnostics(ctx context.Context, memberAddress string, config *Config, path, memberUUID string, bundle []byte) error {
+	s, err := agentService(ctx, memberAddress)
+	if err != nil {
+		return err
+	}
+	if err := limiter.Wait(ctx); err != nil {
+		return err
+	}
+	_, err = s.UploadDiagnostics(ctx, &rest.DiagnosticsOptions{
+		BucketURL:  config.BucketURI,
+		SecretName: config.SecretName,
+		Path:       path,
+		MemberUUID: memberUUID,
+		Bundle:     bundle,
+	})
+	return err
+}
+
+// HotBackupName returns the name of the HotBackup the upload belongs to.
+func (u *Upload) HotBackupName() string {
+	return u.config.HotBackupName
+}
+
 func (u *Upload) Start(ctx context.Context) error {
 	if u.uploadID != nil {
 		return errUploadAlreadyStarted
 	}
-	upload, _, err := u.service.Upload(ctx, &rest.UploadOptions{
+	if err := limiter.Wait(ctx); err != nil {
+		return err
+	}
+	opts := &rest.UploadOptions{
 		BucketURL:        u.config.BucketURI,
 		BackupFolderPath: u.config.BackupPath,
 		HazelcastCRName:  u.config.HazelcastName,
+		HotBackupName:    u.config.HotBackupName,
 		SecretName:       u.config.SecretName,
+		HazelcastVersion: u.config.HazelcastVersion,
+		Compression:      u.config.Compression,
+		KeyEncoding:      u.config.KeyEncoding,
+		CompressionLevel: u.config.CompressionLevel,
+		VerifyArchive:    u.config.VerifyArchive,
+		ObjectLockMode:   u.config.ObjectLockMode,
+		ObjectACL:        u.config.ObjectACL,
+		ChunkedTransfer:  u.config.ChunkedTransfer,
+		DeleteLocal:      u.config.DeleteLocal,
+		Metadata:         u.config.Metadata,
+		DynamicConfig:    u.config.DynamicConfig,
+		DeltaBase:        u.config.DeltaBase,
+		Zone:             u.config.Zone,
+		RunID:            u.config.RunID,
+		TriggerCause:     u.config.TriggerCause,
+		TriggerSource:    u.config.TriggerSource,
+		PartSize:         u.config.PartSize,
+		MaxObjectSize:    u.config.MaxObjectSize,
+		RequestHeaders:   u.config.RequestHeaders,
+		MaxSize:          u.config.MaxSize,
+
+		CredentialsRefreshSeconds: int64(u.config.CredentialsRefreshInterval / time.Second),
+	}
+	opts.RateLimit, opts.RateBurst = uploadRateLimit()
+	if opts.PartSize == 0 {
+		opts.PartSize = DefaultPartSize(u.config.BucketURI)
+	}
+	if !u.config.RetainUntil.IsZero() {
+		opts.RetainUntil = u.config.RetainUntil.UTC().Format(time.RFC3339)
+	}
+	var upload *rest.Upload
+	err := u.retryConnect(ctx, func(ctx context.Context) error {
+		var err error
+		upload, _, err = u.service.Upload(ctx, opts)
+		return err
 	})
 	if err != nil {
 		return err
 	}
 
 	u.uploadID = &upload.ID
+	u.agentVersion = upload.AgentVersion
 	return nil
 }
 
+// retryConnect calls f until it succeeds or fails with an error other than a connection error,
+// waiting for the agent to become reachable. The attempts are retried only if they failed before
+// the request was sent, as the agent might have started the upload of a request that timed out.
+func (u *Upload) retryConnect(ctx context.Context, f func(ctx context.Context) error) error {
+	timeout := u.config.ConnectTimeout
+	if timeout == 0 {
+		timeout = DefaultConnectTimeout
+	}
+	retries := u.config.ConnectRetries
+	if retries == 0 {
+		retries = DefaultConnectRetries
+	}
+	for i := 0; ; i++ {
+		var sent int32
+		trace := &httptrace.ClientTrace{
+			WroteRequest: func(httptrace.WroteRequestInfo) { atomic.StoreInt32(&sent, 1) },
+		}
+		attemptCtx, cancel := context.WithTimeout(httptrace.WithClientTrace(ctx, trace), timeout)
+		err := f(attemptCtx)
+		cancel()
+		if err == nil || i >= retries || !isConnectError(err) || atomic.LoadInt32(&sent) == 1 || ctx.Err() != nil {
+			return err
+		}
+		u.retries++
+		u.lastRetryErr = err
+		select {
+		case <-time.After(connectRetryInterval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// RetryCount returns the number of times starting the upload was retried.
+func (u *Upload) RetryCount() int32 {
+	return u.retries
+}
+
+// LastRetryError returns the error causing the last retry or nil if there was no retry.
+func (u *Upload) LastRetryError() error {
+	return u.lastRetryErr
+}
+
+func isConnectError(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr)
+}
+
 func (u *Upload) Wait(ctx context.Context) error {
 	if u.uploadID == nil {
 		return errUploadNotStarted
@@ -73,16 +497,29 @@ func (u *Upload) Wait(ctx context.Context) error {
 		if err != nil {
 			return err
 		}
+		u.status = *status
 
 		switch status.Status {
 		case "CANCELED":
 			return errUploadCanceled
 		case "FAILURE":
-			return errUploadFailed
+			return statusError(status)
 		case "SUCCESS":
-			return nil
+			// agents not reporting the progress are charged by the final size
+			uploaded := status.UploadedSize
+			if uploaded < status.CompressedSize {
+				uploaded = status.CompressedSize
+			}
+			return u.chargeBudget(uploaded)
 		case "IN_PROGRESS":
 			// expected, check status again (no return)
+			if err := u.chargeBudget(status.UploadedSize); err != nil {
+				return err
+			}
+			if u.config.Progress != nil && status.UploadedSize != u.reported {
+				u.reported = status.UploadedSize
+				u.config.Progress(u.reported)
+			}
 		default:
 			return errors.New("Upload unknown status: " + status.Status)
 		}
@@ -97,10 +534,70 @@ func (u *Upload) Wait(ctx context.Context) error {
 	}
 }
 
+// chargeBudget charges the bytes uploaded since the last call to the size budget.
+func (u *Upload) chargeBudget(uploaded int64) error {
+	if uploaded <= u.charged {
+		return nil
+	}
+	delta := uploaded - u.charged
+	u.charged = uploaded
+	return u.config.SizeBudget.charge(delta)
+}
+
+// Status returns the last status of the upload. The version of the agent is the one reported
+// by the start of the upload if the agent does not report it in the status.
+func (u *Upload) Status() rest.UploadStatus {
+	s := u.status
+	if s.AgentVersion == "" {
+		s.AgentVersion = u.agentVersion
+	}
+	return s
+}
+
 func (u *Upload) Cancel(ctx context.Context) error {
 	if u.uploadID == nil {
 		return errUploadNotStarted
 	}
-	_, err := u.service.Delete(ctx, *u.uploadID)
+	resp, err := u.service.Delete(ctx, *u.uploadID)
+	if resp != nil && resp.StatusCode == http.StatusConflict {
+		return fmt.Errorf("%w: partially uploaded backup could not be deleted", ErrObjectLocked)
+	}
+	return err
+}
+
+func (u *Upload) Purge(ctx context.Context) error {
+	if u.uploadID == nil {
+		return errUploadNotStarted
+	}
+	resp, err := u.service.Purge(ctx, *u.uploadID)
+	if resp != nil && resp.StatusCode == http.StatusConflict {
+		return fmt.Errorf("%w: uploaded backup could not be deleted", ErrObjectLocked)
+	}
 	return err
 }
+
+func statusError(s *rest.UploadStatus) error {
+	err := errUploadFailed
+	switch s.Reason {
+	case reasonObjectLocked:
+		err = ErrObjectLocked
+	case reasonArchiveCorrupted:
+		err = ErrArchiveCorrupted
+	case reasonSizeExceeded:
+		err = ErrSizeBudgetExceeded
+	case reasonDigestMismatch:
+		err = ErrDigestMismatch
+	case reasonDeltaUnsupported:
+		err = ErrDeltaUnsupported
+	default:
+		if bucketErr := bucketReasonError(s.Reason); bucketErr != nil {
+			err = bucketErr
+		} else if bucketErr := classifyMessage(s.Message); bucketErr != nil {
+			err = bucketErr
+		}
+	}
+	if s.Message == "" {
+		return err
+	}
+	return fmt.Errorf("%w: %s", err, s.Message)
+}
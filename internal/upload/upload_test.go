@@ -0,0 +1,876 @@
+package upload
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/time/rate"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/hazelcast/hazelcast-platform-operator/internal/rest"
+)
+
+func Test_statusError(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  *rest.UploadStatus
+		want    error
+		message string
+	}{
+		{
+			name:    "Failure without details",
+			status:  &rest.UploadStatus{Status: "FAILURE"},
+			want:    errUploadFailed,
+			message: "Upload failed",
+		},
+		{
+			name:    "Failure with message",
+			status:  &rest.UploadStatus{Status: "FAILURE", Message: "access denied"},
+			want:    errUploadFailed,
+			message: "Upload failed: access denied",
+		},
+		{
+			name:    "Object lock failure",
+			status:  &rest.UploadStatus{Status: "FAILURE", Reason: reasonObjectLocked, Message: "object is locked until 2030-01-01"},
+			want:    ErrObjectLocked,
+			message: "Object is protected by the bucket's object lock: object is locked until 2030-01-01",
+		},
+		{
+			name:    "Archive verification failure",
+			status:  &rest.UploadStatus{Status: "FAILURE", Reason: reasonArchiveCorrupted, Message: "gzip: invalid checksum"},
+			want:    ErrArchiveCorrupted,
+			message: "Uploaded backup archive is corrupted: gzip: invalid checksum",
+		},
+		{
+			name:    "Delta backup not supported",
+			status:  &rest.UploadStatus{Status: "FAILURE", Reason: reasonDeltaUnsupported, Message: "persistence changelog is not enabled"},
+			want:    ErrDeltaUnsupported,
+			message: "Delta backups are not supported by the member: persistence changelog is not enabled",
+		},
+		{
+			name:    "Bucket failure reason",
+			status:  &rest.UploadStatus{Status: "FAILURE", Reason: reasonAccessDenied, Message: "missing s3:PutObject permission"},
+			want:    ErrAccessDenied,
+			message: "Access to the bucket denied: missing s3:PutObject permission",
+		},
+		{
+			name:    "S3 error code without reason",
+			status:  &rest.UploadStatus{Status: "FAILURE", Message: "NoSuchBucket: The specified bucket does not exist"},
+			want:    ErrBucketNotFound,
+			message: "Bucket not found: NoSuchBucket: The specified bucket does not exist",
+		},
+		{
+			name:    "Azure error code without reason",
+			status:  &rest.UploadStatus{Status: "FAILURE", Message: "ServerBusy: the server is busy"},
+			want:    ErrBucketThrottled,
+			message: "Requests to the bucket throttled: ServerBusy: the server is busy",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := statusError(tt.status)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("statusError() = %v, want %v", err, tt.want)
+			}
+			if err.Error() != tt.message {
+				t.Errorf("statusError() message = %v, want %v", err.Error(), tt.message)
+			}
+		})
+	}
+}
+
+func TestUpload_StartRetriesUnreachableAgent(t *testing.T) {
+	connectRetryInterval = 10 * time.Millisecond
+	defer func() { connectRetryInterval = 2 * time.Second }()
+
+	// nothing listens on the address of the agent
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = l.Close()
+
+	s, err := rest.NewUploadService("http://" + l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	u := &Upload{
+		service: s,
+		config:  &Config{ConnectTimeout: time.Second, ConnectRetries: 2},
+	}
+	if err := u.Start(context.Background()); err == nil {
+		t.Fatal("Start() error = nil, want a connection error")
+	}
+	if u.RetryCount() != 2 || u.LastRetryError() == nil {
+		t.Errorf("RetryCount() = %d, LastRetryError() = %v, want 2 retries after connection errors", u.RetryCount(), u.LastRetryError())
+	}
+
+	u = &Upload{
+		service: s,
+		config:  &Config{ConnectTimeout: time.Second, ConnectRetries: -1},
+	}
+	if err := u.Start(context.Background()); err == nil || u.RetryCount() != 0 {
+		t.Errorf("Start() error = %v, RetryCount() = %d, want no retries if they are disabled", err, u.RetryCount())
+	}
+}
+
+func TestUpload_StartDoesNotRetrySentRequest(t *testing.T) {
+	connectRetryInterval = 10 * time.Millisecond
+	defer func() { connectRetryInterval = 2 * time.Second }()
+
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		// the agent started the upload but does not respond in time
+		time.Sleep(200 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"ID":"` + uuid.New().String() + `"}`))
+	}))
+	defer ts.Close()
+
+	s, err := rest.NewUploadService(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u := &Upload{
+		service: s,
+		config:  &Config{ConnectTimeout: 50 * time.Millisecond, ConnectRetries: 1},
+	}
+	if err := u.Start(context.Background()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Start() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if c := atomic.LoadInt32(&calls); c != 1 || u.RetryCount() != 0 {
+		t.Errorf("Start() called the agent %d times with %d retries, want the sent request not to be retried", c, u.RetryCount())
+	}
+}
+
+func TestUpload_StartDeletesLocalBackup(t *testing.T) {
+	var opts rest.UploadOptions
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&opts)
+		_, _ = w.Write([]byte(`{"ID":"` + uuid.New().String() + `"}`))
+	}))
+	defer ts.Close()
+
+	s, err := rest.NewUploadService(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, deleteLocal := range []bool{false, true} {
+		u := &Upload{
+			service: s,
+			config:  &Config{DeleteLocal: deleteLocal},
+		}
+		if err := u.Start(context.Background()); err != nil {
+			t.Fatalf("Start() error = %v", err)
+		}
+		if opts.DeleteLocal != deleteLocal {
+			t.Errorf("Start() sent delete_local_backup = %v, want %v", opts.DeleteLocal, deleteLocal)
+		}
+	}
+}
+
+func TestNewUpload_selectsSinkByScheme(t *testing.T) {
+	type fakeSink struct{ BackupSink }
+	RegisterSink("fake", func(ctx context.Context, config *Config) (BackupSink, error) { return fakeSink{}, nil })
+	defer func() {
+		sinksMu.Lock()
+		delete(sinks, "fake")
+		sinksMu.Unlock()
+	}()
+
+	tests := []struct {
+		uri     string
+		want    interface{}
+		wantErr bool
+	}{
+		{uri: "s3://backup", want: &Upload{}},
+		{uri: "azblob://backup?prefix=hazelcast/", want: &Upload{}},
+		{uri: "https://archive.example.com/hazelcast", want: &Upload{}},
+		{uri: "fake://backup", want: fakeSink{}},
+		{uri: "ftp://backup", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.uri, func(t *testing.T) {
+			s, err := NewUpload(context.Background(), &Config{BucketURI: tt.uri, MemberAddress: "10.0.0.1:5701"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewUpload() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && reflect.TypeOf(s) != reflect.TypeOf(tt.want) {
+				t.Errorf("NewUpload() = %T, want %T", s, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateMultipart(t *testing.T) {
+	tests := []struct {
+		name          string
+		bucketURI     string
+		partSize      int64
+		maxObjectSize int64
+		wantErr       bool
+	}{
+		{name: "Defaults", bucketURI: "s3://backup"},
+		{name: "Valid S3 part size", bucketURI: "s3://backup", partSize: 64 * mib, maxObjectSize: 100 * gib},
+		{name: "Too small S3 part", bucketURI: "s3://backup", partSize: mib, wantErr: true},
+		{name: "Too large Azure part", bucketURI: "azblob://backup", partSize: 5 * gib, wantErr: true},
+		{name: "Too large object", bucketURI: "gs://backup", maxObjectSize: 6 * tib, wantErr: true},
+		{name: "Too many parts", bucketURI: "s3://backup", partSize: 5 * mib, maxObjectSize: tib, wantErr: true},
+		{name: "Unknown backend", bucketURI: "fake://backup", partSize: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateMultipart(tt.bucketURI, tt.partSize, tt.maxObjectSize); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateMultipart() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateObjectACL(t *testing.T) {
+	tests := []struct {
+		name        string
+		bucketURI   string
+		canned      string
+		permissions []string
+		wantErr     bool
+	}{
+		{name: "S3 canned ACL", bucketURI: "s3://backup", canned: "bucket-owner-full-control"},
+		{name: "S3 grants", bucketURI: "s3://backup", permissions: []string{"READ", "READ_ACP"}},
+		{name: "GCP canned ACL", bucketURI: "gs://backup", canned: "project-private"},
+		{name: "Unknown canned ACL", bucketURI: "s3://backup", canned: "project-private", wantErr: true},
+		{name: "Unsupported GCP permission", bucketURI: "gs://backup", permissions: []string{"WRITE_ACP"}, wantErr: true},
+		{name: "Azure", bucketURI: "azblob://backup", canned: "private", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateObjectACL(tt.bucketURI, tt.canned, tt.permissions); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateObjectACL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDigest(t *testing.T) {
+	checksums := map[string]string{
+		"hz/2022-06-02/a.tar.gz": "aa",
+		"hz/2022-06-02/b.tar.gz": "bb",
+		"hz/2022-06-02/c.tar.gz": "cc",
+	}
+	d := Digest(checksums)
+	if d == "" {
+		t.Fatal("Digest() is empty")
+	}
+	if got := Digest(map[string]string{
+		"hz/2022-06-02/c.tar.gz": "cc",
+		"hz/2022-06-02/b.tar.gz": "bb",
+		"hz/2022-06-02/a.tar.gz": "aa",
+	}); got != d {
+		t.Errorf("Digest() depends on the order of the checksums: %v != %v", got, d)
+	}
+	checksums["hz/2022-06-02/b.tar.gz"] = "bc"
+	if got := Digest(checksums); got == d {
+		t.Errorf("Digest() = %v did not change after a checksum changed", got)
+	}
+	if got := Digest(nil); got != "" {
+		t.Errorf("Digest(nil) = %v, want empty", got)
+	}
+}
+
+func TestUpload_WaitChargesSizeBudget(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"SUCCESS","compressed_size":100}`))
+	}))
+	defer ts.Close()
+
+	s, err := rest.NewUploadService(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	budget := NewSizeBudget(150)
+	wait := func() error {
+		id := uuid.New()
+		u := &Upload{service: s, uploadID: &id, config: &Config{SizeBudget: budget}}
+		return u.Wait(context.Background())
+	}
+	if err := wait(); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if err := wait(); !errors.Is(err, ErrSizeBudgetExceeded) {
+		t.Errorf("Wait() error = %v, want %v", err, ErrSizeBudgetExceeded)
+	}
+}
+
+func TestUpload_WaitReportsProgress(t *testing.T) {
+	var polls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&polls, 1) == 1 {
+			_, _ = w.Write([]byte(`{"status":"IN_PROGRESS","uploaded_size":40}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"SUCCESS","uploaded_size":100}`))
+	}))
+	defer ts.Close()
+
+	s, err := rest.NewUploadService(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var reported []int64
+	id := uuid.New()
+	u := &Upload{service: s, uploadID: &id, config: &Config{Progress: func(b int64) { reported = append(reported, b) }}}
+	if err := u.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if !reflect.DeepEqual(reported, []int64{40}) {
+		t.Errorf("reported progress = %v, want [40]", reported)
+	}
+}
+
+func TestUpload_StatusReportsAgentVersion(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			_, _ = w.Write([]byte(`{"ID":"` + uuid.New().String() + `","agent_version":"1.2.0"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"SUCCESS"}`))
+	}))
+	defer ts.Close()
+
+	s, err := rest.NewUploadService(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u := &Upload{service: s, config: &Config{}}
+	if err := u.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if err := u.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if v := u.Status().AgentVersion; v != "1.2.0" {
+		t.Errorf("Status().AgentVersion = %v, want 1.2.0", v)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/verify":
+			_, _ = w.Write([]byte(`{"ID":"` + uuid.New().String() + `"}`))
+		case atomic.AddInt32(&calls, 1) == 1:
+			_, _ = w.Write([]byte(`{"status":"SUCCESS","digest":"abc","sampled_objects":3,"total_objects":30}`))
+		default:
+			_, _ = w.Write([]byte(`{"status":"FAILURE","reason":"DIGEST_MISMATCH"}`))
+		}
+	}))
+	defer ts.Close()
+
+	s, err := rest.NewUploadService(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := &rest.VerifyOptions{BackupFolder: "hz/2022-06-02", SampleRate: 10, SampleSeed: "hz/2022-06-02"}
+	if v, err := verify(context.Background(), s, opts); err != nil || *v != (Verification{Digest: "abc", SampledObjects: 3, TotalObjects: 30}) {
+		t.Errorf("verify() = %+v, %v, want digest abc with 3 of 30 objects sampled", v, err)
+	}
+	if v, err := verify(context.Background(), s, opts); !errors.Is(err, ErrDigestMismatch) || v != nil {
+		t.Errorf("verify() = %+v, %v, want %v", v, err, ErrDigestMismatch)
+	}
+}
+
+func TestUpload_Purge(t *testing.T) {
+	var purged int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete && r.URL.Query().Get("purge") == "true" {
+			atomic.AddInt32(&purged, 1)
+		}
+	}))
+	defer ts.Close()
+
+	s, err := rest.NewUploadService(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u := &Upload{service: s, config: &Config{}}
+	if err := u.Purge(context.Background()); !errors.Is(err, errUploadNotStarted) {
+		t.Errorf("Purge() error = %v, want %v", err, errUploadNotStarted)
+	}
+	id := uuid.New()
+	u.uploadID = &id
+	if err := u.Purge(context.Background()); err != nil {
+		t.Fatalf("Purge() error = %v", err)
+	}
+	if atomic.LoadInt32(&purged) != 1 {
+		t.Error("Purge() did not ask the agent to purge the upload")
+	}
+}
+
+func TestFind(t *testing.T) {
+	inProgress := uuid.New()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]rest.Upload{
+			{ID: uuid.New(), Status: "SUCCESS", HotBackupName: "hb"},
+			{ID: inProgress, Status: "IN_PROGRESS", HotBackupName: "hb"},
+			{ID: uuid.New(), Status: "SUCCESS", HotBackupName: "hb"},
+			{ID: uuid.New(), Status: "IN_PROGRESS", HotBackupName: "other"},
+		})
+	}))
+	defer ts.Close()
+
+	s, err := rest.NewUploadService(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := find(context.Background(), s, "10.0.0.1:5701", "hb")
+	if err != nil {
+		t.Fatalf("find() error = %v", err)
+	}
+	if u == nil || *u.uploadID != inProgress {
+		t.Errorf("find() = %v, want the upload in progress %v", u, inProgress)
+	}
+	u, err = find(context.Background(), s, "10.0.0.1:5701", "missing")
+	if err != nil || u != nil {
+		t.Errorf("find() = %v, %v, want nil, nil", u, err)
+	}
+}
+
+func TestCheckAgents(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer ts.Close()
+
+	prev := agentEndpoint
+	defer func() { agentEndpoint = prev }()
+	agentEndpoint = func(_ context.Context, host string) (string, *http.Client, error) {
+		if host != "10.0.0.1" {
+			return "", nil, errors.New("no running pod has IP " + host)
+		}
+		return ts.URL, ts.Client(), nil
+	}
+
+	if err := CheckAgents(context.Background(), []string{"10.0.0.1:5701"}); err != nil {
+		t.Errorf("CheckAgents() error = %v", err)
+	}
+	err := CheckAgents(context.Background(), []string{"10.0.0.1:5701", "10.0.0.3:5701", "10.0.0.2:5701"})
+	if !errors.Is(err, ErrAgentUnreachable) {
+		t.Fatalf("CheckAgents() error = %v, want %v", err, ErrAgentUnreachable)
+	}
+	if want := "agents of members 10.0.0.2:5701, 10.0.0.3:5701 cannot be reached"; !strings.Contains(err.Error(), want) {
+		t.Errorf("CheckAgents() error = %v, want it to contain %q", err, want)
+	}
+}
+
+// podReader returns the pods in the namespace of the list options.
+type podReader struct {
+	client.Reader
+	pods []corev1.Pod
+	// lookups counts the calls of List if it is set
+	lookups *int32
+}
+
+func (r podReader) List(_ context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	if r.lookups != nil {
+		atomic.AddInt32(r.lookups, 1)
+	}
+	o := &client.ListOptions{}
+	o.ApplyOptions(opts)
+	for _, p := range r.pods {
+		if p.Namespace == o.Namespace {
+			list.(*corev1.PodList).Items = append(list.(*corev1.PodList).Items, p)
+		}
+	}
+	return nil
+}
+
+func TestAPIServerProxyEndpoint(t *testing.T) {
+	pod := func(namespace string) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "hazelcast-0", Namespace: namespace},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning, PodIP: "10.0.0.1"},
+		}
+	}
+	endpoint := apiServerProxyEndpoint("api.cluster:6443", &http.Client{}, podReader{pods: []corev1.Pod{pod("other"), pod("prod")}})
+
+	address, _, err := endpoint(WithNamespace(context.Background(), "prod"), "10.0.0.1")
+	if err != nil {
+		t.Fatalf("endpoint() error = %v", err)
+	}
+	if want := "https://api.cluster:6443/api/v1/namespaces/prod/pods/hazelcast-0:8080/proxy/"; address != want {
+		t.Errorf("endpoint() = %s, want %s", address, want)
+	}
+
+	if _, _, err := endpoint(WithNamespace(context.Background(), "dev"), "10.0.0.1"); err == nil {
+		t.Error("endpoint() found a pod of another namespace")
+	}
+	if _, _, err := endpoint(context.Background(), "10.0.0.1"); err == nil {
+		t.Error("endpoint() without the namespace of the member succeeded")
+	}
+}
+
+func TestAPIServerProxyEndpoint_cachesPods(t *testing.T) {
+	var lookups int32
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "hazelcast-0", Namespace: "prod"},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning, PodIP: "10.0.0.1"},
+	}
+	endpoint := apiServerProxyEndpoint("api.cluster:6443", &http.Client{}, podReader{pods: []corev1.Pod{pod}, lookups: &lookups})
+
+	ctx := WithPodCache(WithNamespace(context.Background(), "prod"))
+	for i := 0; i < 3; i++ {
+		address, _, err := endpoint(ctx, "10.0.0.1")
+		if err != nil {
+			t.Fatalf("endpoint() error = %v", err)
+		}
+		if want := "https://api.cluster:6443/api/v1/namespaces/prod/pods/hazelcast-0:8080/proxy/"; address != want {
+			t.Errorf("endpoint() = %s, want %s", address, want)
+		}
+	}
+	if lookups != 1 {
+		t.Errorf("pod was looked up %d times, want once for the calls with the cache", lookups)
+	}
+
+	// every run has its own cache
+	if _, _, err := endpoint(WithPodCache(WithNamespace(context.Background(), "prod")), "10.0.0.1"); err != nil {
+		t.Fatalf("endpoint() error = %v", err)
+	}
+	if lookups != 2 {
+		t.Errorf("pod was looked up %d times, want again with a new cache", lookups)
+	}
+}
+
+func TestCheckPath(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/stat" {
+			http.Error(w, `{"message":"the directory is walked"}`, http.StatusBadRequest)
+			return
+		}
+		switch r.URL.Query().Get("path") {
+		case "/mnt/backups":
+			_, _ = w.Write([]byte(`{"is_dir":true}`))
+		case "/mnt/backups/backup.tar":
+			_, _ = w.Write([]byte(`{"is_dir":false}`))
+		default:
+			http.Error(w, `{"message":"no such directory"}`, http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	prev := agentEndpoint
+	defer func() { agentEndpoint = prev }()
+	agentEndpoint = func(_ context.Context, host string) (string, *http.Client, error) {
+		return ts.URL, ts.Client(), nil
+	}
+
+	members := []string{"10.0.0.1:5701", "10.0.0.2:5701"}
+	if err := CheckPath(context.Background(), members, "/mnt/backups"); err != nil {
+		t.Errorf("CheckPath() error = %v", err)
+	}
+	if err := CheckPath(context.Background(), members, "/data/missing"); err == nil || !strings.Contains(err.Error(), "10.0.0.1:5701") {
+		t.Errorf("CheckPath() error = %v, want the path to be unreadable on 10.0.0.1:5701", err)
+	}
+	if err := CheckPath(context.Background(), members, "/mnt/backups/backup.tar"); err == nil || !strings.Contains(err.Error(), "not a directory") {
+		t.Errorf("CheckPath() error = %v, want the path not to be a directory", err)
+	}
+}
+
+func TestCompact(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/compact":
+			_, _ = w.Write([]byte(`{"ID":"` + uuid.New().String() + `"}`))
+		case atomic.AddInt32(&calls, 1) == 1:
+			_, _ = w.Write([]byte(`{"status":"IN_PROGRESS","progress":50}`))
+		default:
+			_, _ = w.Write([]byte(`{"status":"SUCCESS","backup_folder":"hz/2022-06-03","merged_folders":["hz/2022-06-01","hz/2022-06-02"]}`))
+		}
+	}))
+	defer ts.Close()
+
+	s, err := rest.NewUploadService(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var progress []int32
+	c, err := compact(context.Background(), s, &rest.CompactOptions{BackupFolder: "hz/2022-06-02"}, func(p int32) {
+		progress = append(progress, p)
+	})
+	if err != nil {
+		t.Fatalf("compact() error = %v", err)
+	}
+	want := &Compaction{BackupFolder: "hz/2022-06-03", MergedFolders: []string{"hz/2022-06-01", "hz/2022-06-02"}}
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("compact() = %+v, want %+v", c, want)
+	}
+	if !reflect.DeepEqual(progress, []int32{50}) {
+		t.Errorf("progress = %v, want [50]", progress)
+	}
+}
+
+func TestSetMaxConcurrentUploads(t *testing.T) {
+	RegisterSink("fake", func(ctx context.Context, config *Config) (BackupSink, error) { return &fakeSink{}, nil })
+	SetMaxConcurrentUploads(1)
+	defer func() {
+		SetMaxConcurrentUploads(0)
+		sinksMu.Lock()
+		delete(sinks, "fake")
+		sinksMu.Unlock()
+	}()
+
+	newSink := func() BackupSink {
+		s, err := NewUpload(context.Background(), &Config{BucketURI: "fake://backup"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	first, second := newSink(), newSink()
+	if err := first.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := second.Start(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Start() over the limit error = %v, want it to block until the deadline", err)
+	}
+
+	if err := first.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if err := second.Start(context.Background()); err != nil {
+		t.Errorf("Start() after the first upload finished error = %v", err)
+	}
+	if err := second.Cancel(context.Background()); err != nil {
+		t.Errorf("Cancel() error = %v", err)
+	}
+	if len(slots) != 0 {
+		t.Errorf("slots held = %d after all uploads finished, want 0", len(slots))
+	}
+}
+
+type fakeSink struct{ BackupSink }
+
+func (fakeSink) Start(context.Context) error  { return nil }
+func (fakeSink) Wait(context.Context) error   { return nil }
+func (fakeSink) Cancel(context.Context) error { return nil }
+
+func TestEncodeKeySegment(t *testing.T) {
+	long := strings.Repeat("orders-", 12)
+	tests := []struct {
+		segment  string
+		encoding string
+		want     string
+	}{
+		{"Orders", "", "Orders"},
+		{"Orders", KeyEncodingLowercase, "orders"},
+		{"Orders EU/2", KeyEncodingPercent, "%4frders%20%45%55%2f2"},
+		{"orders_2.eu-west", KeyEncodingPercent, "orders_2.eu-west"},
+		{"orders", KeyEncodingHash, "orders"},
+	}
+	for _, tt := range tests {
+		got := EncodeKeySegment(tt.segment, tt.encoding)
+		if got != tt.want {
+			t.Errorf("EncodeKeySegment(%q, %q) = %q, want %q", tt.segment, tt.encoding, got, tt.want)
+		}
+		if tt.encoding == KeyEncodingPercent {
+			if decoded, err := DecodeKeySegment(got, tt.encoding); err != nil || decoded != tt.segment {
+				t.Errorf("DecodeKeySegment(%q) = %q, %v, want %q", got, decoded, err, tt.segment)
+			}
+		}
+	}
+
+	hashed := EncodeKeySegment(long, KeyEncodingHash)
+	if len(hashed) != MaxKeySegmentLength || !strings.HasPrefix(hashed, long[:hashedPrefixLength]+"-") {
+		t.Errorf("EncodeKeySegment() of a long segment = %q, want its prefix and hash in %d bytes", hashed, MaxKeySegmentLength)
+	}
+	if other := EncodeKeySegment(long+"x", KeyEncodingHash); other == hashed {
+		t.Errorf("EncodeKeySegment() of different long segments = %q for both", other)
+	}
+}
+
+func TestFootprint(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/footprint" || q.Get("path") != "/data/hot-restart" || !reflect.DeepEqual(q["exclude"], []string{"hot-backup"}) {
+			http.Error(w, `{"message":"unexpected request"}`, http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{"bytes":1024}`))
+	}))
+	defer ts.Close()
+
+	prev := agentEndpoint
+	defer func() { agentEndpoint = prev }()
+	agentEndpoint = func(_ context.Context, host string) (string, *http.Client, error) {
+		return ts.URL, ts.Client(), nil
+	}
+
+	bytes, err := Footprint(context.Background(), "10.0.0.1:5701", "/data/hot-restart", "hot-backup")
+	if err != nil {
+		t.Fatalf("Footprint() error = %v", err)
+	}
+	if bytes != 1024 {
+		t.Errorf("Footprint() = %d, want 1024", bytes)
+	}
+}
+
+func TestLocalBackups(t *testing.T) {
+	var marked, deleted rest.LocalBackupsOptions
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var opts rest.LocalBackupsOptions
+		if err := json.NewDecoder(r.Body).Decode(&opts); err != nil || opts.Path != "/mnt/backups/hot-backup" {
+			http.Error(w, `{"message":"no such directory"}`, http.StatusNotFound)
+			return
+		}
+		switch r.URL.Path {
+		case "/local-backups/mark":
+			marked = opts
+		case "/local-backups/list":
+			_, _ = w.Write([]byte(`[{"name":"backup-2","created_at":"2022-06-15T12:00:00Z","hot_backup":"hourly"},{"name":"backup-1","created_at":"2022-06-15T11:00:00Z"}]`))
+		case "/local-backups/delete":
+			deleted = opts
+		}
+	}))
+	defer ts.Close()
+
+	prev := agentEndpoint
+	defer func() { agentEndpoint = prev }()
+	agentEndpoint = func(_ context.Context, host string) (string, *http.Client, error) {
+		return ts.URL, ts.Client(), nil
+	}
+
+	ctx := context.Background()
+	if err := MarkLocalBackup(ctx, "10.0.0.1:5701", "/mnt/backups/hot-backup", "hourly"); err != nil {
+		t.Fatalf("MarkLocalBackup() error = %v", err)
+	}
+	if marked.HotBackupName != "hourly" {
+		t.Errorf("marked HotBackup = %q, want hourly", marked.HotBackupName)
+	}
+	backups, err := ListLocalBackups(ctx, "10.0.0.1:5701", "/mnt/backups/hot-backup")
+	if err != nil {
+		t.Fatalf("ListLocalBackups() error = %v", err)
+	}
+	if len(backups) != 2 || backups[0].HotBackup != "hourly" || backups[1].HotBackup != "" {
+		t.Errorf("ListLocalBackups() = %+v, want backup-2 of hourly and unmarked backup-1", backups)
+	}
+	if err := DeleteLocalBackups(ctx, "10.0.0.1:5701", "/mnt/backups/hot-backup", []string{"backup-2"}); err != nil {
+		t.Fatalf("DeleteLocalBackups() error = %v", err)
+	}
+	if !reflect.DeepEqual(deleted.BackupDirs, []string{"backup-2"}) {
+		t.Errorf("deleted = %v, want [backup-2]", deleted.BackupDirs)
+	}
+	if _, err := ListLocalBackups(ctx, "10.0.0.1:5701", "/data/hot-backup"); err == nil {
+		t.Error("ListLocalBackups() of a missing directory succeeded")
+	}
+}
+
+func TestSetRateLimit(t *testing.T) {
+	defer SetRateLimit(0, 0)
+
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		_, _ = w.Write([]byte(`{"bytes":1024}`))
+	}))
+	defer ts.Close()
+
+	prev := agentEndpoint
+	defer func() { agentEndpoint = prev }()
+	agentEndpoint = func(_ context.Context, host string) (string, *http.Client, error) {
+		return ts.URL, ts.Client(), nil
+	}
+
+	SetRateLimit(1, 0)
+	if limiter.Limit() != 1 || limiter.Burst() != 1 {
+		t.Fatalf("limit = %v, burst = %d, want 1 call per second with a burst of 1", limiter.Limit(), limiter.Burst())
+	}
+	if _, err := Footprint(context.Background(), "10.0.0.1:5701", "/data/hot-restart"); err != nil {
+		t.Fatalf("Footprint() error = %v", err)
+	}
+	// the next call has to wait for about a second, longer than the deadline
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := Footprint(ctx, "10.0.0.1:5701", "/data/hot-restart"); err == nil {
+		t.Error("Footprint() error = nil, want the rate limit to exceed the deadline")
+	}
+	if c := atomic.LoadInt32(&calls); c != 1 {
+		t.Errorf("agent was called %d times, want the throttled call not to reach it", c)
+	}
+
+	SetRateLimit(0, 0)
+	if limiter.Limit() != rate.Inf {
+		t.Errorf("limit = %v, want no limit", limiter.Limit())
+	}
+}
+
+func TestUpload_StartSendsRateLimit(t *testing.T) {
+	defer SetRateLimit(0, 0)
+	defer SetMaxConcurrentUploads(0)
+
+	var opts rest.UploadOptions
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			_ = json.NewDecoder(r.Body).Decode(&opts)
+			_, _ = w.Write([]byte(`{"ID":"` + uuid.New().String() + `"}`))
+		}
+	}))
+	defer ts.Close()
+
+	s, err := rest.NewUploadService(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name      string
+		rps       float64
+		burst     int
+		slots     int
+		wantLimit float64
+		wantBurst int
+	}{
+		{name: "no limit", wantLimit: 0, wantBurst: 0},
+		{name: "whole limit", rps: 10, burst: 4, wantLimit: 10, wantBurst: 4},
+		{name: "share of concurrent uploads", rps: 10, burst: 4, slots: 4, wantLimit: 2.5, wantBurst: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetRateLimit(tt.rps, tt.burst)
+			SetMaxConcurrentUploads(tt.slots)
+			u := &Upload{service: s, config: &Config{}}
+			if err := u.Start(context.Background()); err != nil {
+				t.Fatalf("Start() error = %v", err)
+			}
+			if opts.RateLimit != tt.wantLimit || opts.RateBurst != tt.wantBurst {
+				t.Errorf("Start() sent rate_limit = %v, rate_burst = %d, want %v and %d", opts.RateLimit, opts.RateBurst, tt.wantLimit, tt.wantBurst)
+			}
+		})
+	}
+
+	// the upload started, only the storage operations are throttled
+	SetRateLimit(0.001, 1)
+	SetMaxConcurrentUploads(0)
+	limiter.Allow()
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	id := uuid.New()
+	u := &Upload{service: s, config: &Config{}, uploadID: &id}
+	if err := u.Cancel(ctx); err != nil {
+		t.Errorf("Cancel() error = %v, want the cancel not to wait for the rate limit", err)
+	}
+}
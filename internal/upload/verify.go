@@ -0,0 +1,84 @@
+package upload
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/hazelcast/hazelcast-platform-operator/internal/rest"
+)
+
+// Verification is the result of a verification reported by the agent.
+type Verification struct {
+	// Digest of the verified backup
+	Digest string
+	// SampledObjects is the number of objects whose checksums were verified
+	SampledObjects int32
+	// TotalObjects is the number of objects of the backup
+	TotalObjects int32
+}
+
+// Verify makes the agent of the member download the backup in the backup folder and compare the checksums
+// and the digest of the backup set with its manifest. The member backups are also extracted to a temporary
+// directory if restoreTest is true. If sampleRate is not zero, only the checksums of that percentage of the objects
+// are verified, the sample is chosen deterministically from the backup folder. The manifest is always verified.
+// The verification is returned together with the error of the failed verification if the agent reported the sample.
+func Verify(ctx context.Context, memberAddress string, config *Config, backupFolder string, restoreTest bool, sampleRate int32) (*Verification, error) {
+	s, err := agentService(ctx, memberAddress)
+	if err != nil {
+		return nil, err
+	}
+	opts := &rest.VerifyOptions{
+		BucketURL:    config.BucketURI,
+		SecretName:   config.SecretName,
+		BackupFolder: backupFolder,
+		RestoreTest:  restoreTest,
+	}
+	if sampleRate > 0 && sampleRate < 100 {
+		opts.SampleRate = sampleRate
+		opts.SampleSeed = backupFolder
+	}
+	return verify(ctx, s, opts)
+}
+
+func verify(ctx context.Context, s *rest.UploadService, opts *rest.VerifyOptions) (*Verification, error) {
+	if err := limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	v, _, err := s.Verify(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		status, _, err := s.VerifyStatus(ctx, v.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		switch status.Status {
+		case "FAILURE":
+			if status.TotalObjects > 0 {
+				return verification(status), statusError(status)
+			}
+			return nil, statusError(status)
+		case "SUCCESS":
+			return verification(status), nil
+		case "IN_PROGRESS":
+			// expected, check status again (no return)
+		default:
+			return nil, errors.New("Verification unknown status: " + status.Status)
+		}
+
+		select {
+		case <-time.After(1 * time.Second):
+			continue
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func verification(s *rest.UploadStatus) *Verification {
+	return &Verification{Digest: s.Digest, SampledObjects: s.SampledObjects, TotalObjects: s.TotalObjects}
+}
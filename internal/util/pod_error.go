@@ -28,6 +28,10 @@ func NewPodErrorWithContainerStatus(pod *corev1.Pod, status corev1.ContainerStat
 	err := NewPodError(pod)
 	err.RestartCount = status.RestartCount
 	err.Reason = status.State.Waiting.Reason
+	// Prefer the message of the failed container, e.g. written by a restore hook to its termination log.
+	if t := status.LastTerminationState.Terminated; err.Message == "" && t != nil {
+		err.Message = t.Message
+	}
 	return err
 }
 
@@ -99,7 +99,7 @@ func AsPodErrors(err error) (PodErrors, bool) {
 }
 
 func hasPodFailedWhileWaiting(pod *corev1.Pod) bool {
-	for _, status := range pod.Status.ContainerStatuses {
+	for _, status := range containerStatuses(pod) {
 		if status.State.Waiting != nil {
 			switch status.State.Waiting.Reason {
 			case "ContainerCreating", "PodInitializing", "":
@@ -113,7 +113,7 @@ func hasPodFailedWhileWaiting(pod *corev1.Pod) bool {
 
 func errorsFromPendingPod(pod *corev1.Pod) PodErrors {
 	podErrors := make(PodErrors, 0, len(pod.Spec.Containers))
-	for _, status := range pod.Status.ContainerStatuses {
+	for _, status := range containerStatuses(pod) {
 		if status.State.Waiting != nil {
 			switch status.State.Waiting.Reason {
 			case "ContainerCreating", "PodInitializing", "":
@@ -125,6 +125,13 @@ func errorsFromPendingPod(pod *corev1.Pod) PodErrors {
 	return podErrors
 }
 
+// containerStatuses returns the statuses of both init and regular containers of the pod.
+func containerStatuses(pod *corev1.Pod) []corev1.ContainerStatus {
+	statuses := make([]corev1.ContainerStatus, 0, len(pod.Status.InitContainerStatuses)+len(pod.Status.ContainerStatuses))
+	statuses = append(statuses, pod.Status.InitContainerStatuses...)
+	return append(statuses, pod.Status.ContainerStatuses...)
+}
+
 func listPods(ctx context.Context, cl client.Client, sts *appsv1.StatefulSet) (*corev1.PodList, error) {
 	pods := &corev1.PodList{}
 	podLabels := sts.Spec.Template.Labels
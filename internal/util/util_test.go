@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -78,3 +79,60 @@ func Test_deploymentName(t *testing.T) {
 		})
 	}
 }
+
+func Test_errorsFromPendingPod(t *testing.T) {
+	tests := []struct {
+		name    string
+		pod     *corev1.Pod
+		failed  bool
+		message string
+	}{
+		{
+			name:   "Pod is initializing",
+			pod:    pendingPod(waitingStatus("PodInitializing", ""), waitingStatus("PodInitializing", "")),
+			failed: false,
+		},
+		{
+			name:    "Init container is crashing",
+			pod:     pendingPod(waitingStatus("CrashLoopBackOff", "incompatible serialization version"), waitingStatus("PodInitializing", "")),
+			failed:  true,
+			message: "incompatible serialization version",
+		},
+		{
+			name:   "Container image cannot be pulled",
+			pod:    pendingPod(corev1.ContainerStatus{State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{}}}, waitingStatus("ErrImagePull", "")),
+			failed: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hasPodFailedWhileWaiting(tt.pod); got != tt.failed {
+				t.Errorf("hasPodFailedWhileWaiting() = %v, want %v", got, tt.failed)
+			}
+			errs := errorsFromPendingPod(tt.pod)
+			if tt.message != "" && (len(errs) != 1 || errs[0].Message != tt.message) {
+				t.Errorf("errorsFromPendingPod() = %v, want message %v", errs, tt.message)
+			}
+		})
+	}
+}
+
+func pendingPod(initStatus corev1.ContainerStatus, status corev1.ContainerStatus) *corev1.Pod {
+	return &corev1.Pod{
+		Status: corev1.PodStatus{
+			Phase:                 corev1.PodPending,
+			InitContainerStatuses: []corev1.ContainerStatus{initStatus},
+			ContainerStatuses:     []corev1.ContainerStatus{status},
+		},
+	}
+}
+
+func waitingStatus(reason string, terminationMessage string) corev1.ContainerStatus {
+	s := corev1.ContainerStatus{
+		State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: reason}},
+	}
+	if terminationMessage != "" {
+		s.LastTerminationState.Terminated = &corev1.ContainerStateTerminated{ExitCode: 1, Message: terminationMessage}
+	}
+	return s
+}
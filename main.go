@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"os"
 	"time"
@@ -12,7 +13,10 @@ import (
 
 	"github.com/hazelcast/hazelcast-platform-operator/controllers/hazelcast"
 	"github.com/hazelcast/hazelcast-platform-operator/controllers/managementcenter"
+	"github.com/hazelcast/hazelcast-platform-operator/internal/audit"
 	"github.com/hazelcast/hazelcast-platform-operator/internal/platform"
+	"github.com/hazelcast/hazelcast-platform-operator/internal/tracing"
+	"github.com/hazelcast/hazelcast-platform-operator/internal/upload"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -49,11 +53,65 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var uploadRateLimit float64
+	var uploadRateBurst int
+	var scheduleWithSeconds bool
+	var bucketFailureThreshold int
+	var bucketRetryInterval time.Duration
+	var hotBackupConcurrentReconciles int
+	var auditSink string
+	var disableExternalBackups bool
+	var backupMaintenanceMode bool
+	var hazelcastFetchTimeout time.Duration
+	var otelEndpoint string
+	var otelInsecure bool
+	var otelSampleRatio float64
+	var agentAddressing string
+	var maxConcurrentUploads int
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.Float64Var(&uploadRateLimit, "upload-rate-limit", 0,
+		"Maximum number of bucket requests per second shared by all backups. "+
+			"The agents limit the requests they send to the bucket while uploading to the share of their upload, "+
+			"the limit divided by max-concurrent-uploads if it is set. Agents older than 0.2.0 do not limit their requests. "+
+			"Zero means no limit.")
+	flag.IntVar(&uploadRateBurst, "upload-rate-burst", 10, "Maximum burst of bucket requests allowed by the rate limit.")
+	flag.BoolVar(&scheduleWithSeconds, "backup-schedule-seconds", false,
+		"Accept an optional leading seconds field in HotBackup schedules.")
+	flag.IntVar(&bucketFailureThreshold, "bucket-failure-threshold", 5,
+		"Number of consecutive failed uploads after which backups to the bucket fail fast until the retry interval passes. "+
+			"Zero disables it.")
+	flag.DurationVar(&bucketRetryInterval, "bucket-retry-interval", 5*time.Minute,
+		"Time after which a backup to a failing bucket is tried again. It doubles with every further failure up to an hour.")
+	flag.IntVar(&hotBackupConcurrentReconciles, "hotbackup-concurrent-reconciles", 1,
+		"Maximum number of HotBackup resources reconciled at the same time.")
+	flag.StringVar(&auditSink, "audit-sink", "",
+		"URI of the sink the audit records of the finished backups are appended to, "+
+			"e.g. syslog://host:514, syslog+tcp://host:514 or hazelcast-topic://<topic>. Auditing is disabled if empty.")
+	flag.BoolVar(&disableExternalBackups, "disable-external-backups", false,
+		"Keep all backups on the Hazelcast members only, nothing is uploaded to the buckets regardless of the HotBackup resources. "+
+			"It is meant as a safety guard for non-production deployments.")
+	flag.BoolVar(&backupMaintenanceMode, "backup-maintenance-mode", false,
+		"Skip the runs of all scheduled HotBackups, e.g. during a maintenance window. "+
+			"The skipped runs are recorded in the status of the HotBackups, the schedules resume once it is turned off.")
+	flag.DurationVar(&hazelcastFetchTimeout, "hazelcast-fetch-timeout", 30*time.Second,
+		"Time the transient API server errors are retried within when a starting backup fetches its Hazelcast resource. "+
+			"Zero disables the retries.")
+	flag.StringVar(&otelEndpoint, "otel-endpoint", "",
+		"Address of the OpenTelemetry collector the traces of the backups are exported to over OTLP gRPC, e.g. otel-collector:4317. "+
+			"Tracing is disabled if empty.")
+	flag.BoolVar(&otelInsecure, "otel-insecure", false, "Export the traces to the OpenTelemetry collector without TLS.")
+	flag.Float64Var(&otelSampleRatio, "otel-sample-ratio", 1, "Ratio of the backup runs traced, between 0 and 1.")
+	flag.StringVar(&agentAddressing, "agent-addressing", string(upload.AgentAddressingPodIP),
+		"How the backup agents of the members are reached: pod-ip connects to the pod IPs, "+
+			"api-server-proxy goes through the pod proxy of the Kubernetes API server for the networks the pod IPs are not routable from the operator in. "+
+			"Only api-server-proxy uses the pods/proxy permissions of the operator, they can be removed from its role with pod-ip.")
+	flag.IntVar(&maxConcurrentUploads, "max-concurrent-uploads", 0,
+		"Maximum number of member uploads running at the same time across all backups and clusters, e.g. to protect the shared network egress. "+
+			"The uploads over the limit wait for a running one to finish. Zero means no limit.")
 	opts := zap.Options{
 		Development: util.IsDeveloperModeEnabled(),
 	}
@@ -62,6 +120,19 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	upload.SetRateLimit(uploadRateLimit, uploadRateBurst)
+	upload.SetCircuitBreaker(bucketFailureThreshold, bucketRetryInterval)
+	upload.SetMaxConcurrentUploads(maxConcurrentUploads)
+	if err := audit.SetSink(auditSink); err != nil {
+		setupLog.Error(err, "unable to set up audit sink")
+		os.Exit(1)
+	}
+	shutdownTracing, err := tracing.SetTracerProvider(context.Background(), otelEndpoint, otelInsecure, otelSampleRatio)
+	if err != nil {
+		setupLog.Error(err, "unable to set up tracing")
+		os.Exit(1)
+	}
+
 	// Get watch namespace from environment variable.
 	namespace, found := os.LookupEnv(WatchNamespaceEnv)
 	if !found || namespace == "" {
@@ -87,6 +158,10 @@ func main() {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
+	if err := upload.SetAgentAddressing(upload.AgentAddressing(agentAddressing), cfg, mgr.GetAPIReader()); err != nil {
+		setupLog.Error(err, "unable to set up agent addressing")
+		os.Exit(1)
+	}
 
 	err = platform.FindAndSetPlatform(cfg)
 	if err != nil {
@@ -128,10 +203,42 @@ func main() {
 		os.Exit(1)
 	}
 
-	if err = hazelcast.NewHotBackupReconciler(mgr.GetClient(), ctrl.Log.WithName("controllers").WithName("HotBackup")).SetupWithManager(mgr); err != nil {
+	if err = hazelcast.NewHotBackupReconciler(
+		mgr.GetClient(),
+		ctrl.Log.WithName("controllers").WithName("HotBackup"),
+		hazelcast.NewScheduleParser(scheduleWithSeconds),
+		hotBackupConcurrentReconciles,
+		disableExternalBackups,
+		backupMaintenanceMode,
+		hazelcastFetchTimeout,
+	).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "HotBackup")
 		os.Exit(1)
 	}
+	if err = hazelcast.NewHotBackupTriggerReconciler(
+		mgr.GetClient(),
+		ctrl.Log.WithName("controllers").WithName("HotBackupTrigger"),
+		mgr.GetScheme(),
+	).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "HotBackupTrigger")
+		os.Exit(1)
+	}
+	if err = hazelcast.NewHotBackupPolicyReconciler(
+		mgr.GetClient(),
+		ctrl.Log.WithName("controllers").WithName("HotBackupPolicy"),
+		mgr.GetScheme(),
+	).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "HotBackupPolicy")
+		os.Exit(1)
+	}
+	if err = hazelcast.NewUserCodeReconciler(
+		mgr.GetClient(),
+		ctrl.Log.WithName("controllers").WithName("UserCode"),
+		mgr.GetScheme(),
+	).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "UserCode")
+		os.Exit(1)
+	}
 	if err = (&hazelcast.MapReconciler{
 		Client: mgr.GetClient(),
 		Log:    ctrl.Log.WithName("controllers").WithName("Map"),
@@ -169,4 +276,8 @@ func main() {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
+	// the spans of the last backups are exported before the operator exits
+	if err := shutdownTracing(context.Background()); err != nil {
+		setupLog.Error(err, "unable to export the remaining traces")
+	}
 }